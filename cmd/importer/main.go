@@ -0,0 +1,95 @@
+// Command importer sends curl commands, raw HTTP requests or HAR files to a
+// running proxy API, so requests which were never proxied can be repeated
+// and scanned.
+//
+//	importer -format curl request.sh
+//	importer -format raw -scheme https request.txt
+//	cat dump.har | importer -format har -name dump
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+func main() {
+	api := flag.String("api", "http://127.0.0.1:8000", "proxy API address")
+	format := flag.String("format", "curl", "input format: curl, raw or har")
+	scheme := flag.String("scheme", "http", "scheme for raw requests with origin-form URL")
+	name := flag.String("name", "", "session name for har import")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [file ...]\n\nReads stdin if no files given.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	query := url.Values{}
+	switch *format {
+	case "curl":
+	case "raw":
+		query.Set("scheme", *scheme)
+	case "har":
+		if *name != "" {
+			query.Set("name", *name)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		os.Exit(2)
+	}
+	endpoint := strings.TrimRight(*api, "/") + "/import/" + *format
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	files := flag.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	failed := false
+	for _, file := range files {
+		if err := importFile(endpoint, file); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func importFile(endpoint, file string) error {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(endpoint, "application/octet-stream", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	fmt.Printf("%s: %s\n", file, strings.TrimSpace(string(body)))
+	return nil
+}
@@ -1,17 +1,23 @@
 package main
 
 import (
-	"context"
+	fuzzDelivery "github.com/MatiXxD/go-mitm-proxy/internal/delivery/fuzz"
 	proxyDelivery "github.com/MatiXxD/go-mitm-proxy/internal/delivery/proxy"
 	requestDelivery "github.com/MatiXxD/go-mitm-proxy/internal/delivery/request"
+	scanDelivery "github.com/MatiXxD/go-mitm-proxy/internal/delivery/scan"
+	sessionDelivery "github.com/MatiXxD/go-mitm-proxy/internal/delivery/session"
 	proxyServer "github.com/MatiXxD/go-mitm-proxy/internal/proxy"
 	proxyRepository "github.com/MatiXxD/go-mitm-proxy/internal/repository/proxy"
-	requestRepository "github.com/MatiXxD/go-mitm-proxy/internal/repository/request"
+	fuzzUsecase "github.com/MatiXxD/go-mitm-proxy/internal/usecase/fuzz"
 	requestUsecase "github.com/MatiXxD/go-mitm-proxy/internal/usecase/request"
+	scanUsecase "github.com/MatiXxD/go-mitm-proxy/internal/usecase/scan"
+	sessionUsecase "github.com/MatiXxD/go-mitm-proxy/internal/usecase/session"
 	"github.com/MatiXxD/go-mitm-proxy/internal/webapi"
-	"github.com/MatiXxD/go-mitm-proxy/pkg/db/mongodb"
 	"github.com/MatiXxD/go-mitm-proxy/pkg/env"
 	"github.com/MatiXxD/go-mitm-proxy/pkg/logger"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/oob"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner/checks"
 	"log"
 	"os"
 )
@@ -26,7 +32,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	db, err := mongodb.NewMongoDB(context.Background(), cfg)
+	st, err := newStorage(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -36,12 +42,82 @@ func main() {
 		log.Fatal(err)
 	}
 
+	blobs, err := st.blobStore(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Webapi
-	rr := requestRepository.NewRequestRepository(db, logger)
-	ru := requestUsecase.NewRequestUsecase(rr, logger)
+	sr, err := st.sessionRepository(logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	su, err := sessionUsecase.NewSessionUsecase(sr, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sd := sessionDelivery.NewSessionDelivery(su, logger)
+
+	rr, err := st.requestRepository(logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ru := requestUsecase.NewRequestUsecase(rr, su, blobs, cfg, logger)
+	if err := ru.MigrateSessions(); err != nil {
+		log.Fatal(err)
+	}
 	rd := requestDelivery.NewRequestDelivery(ru, logger)
+
+	fr, err := st.fuzzRepository(logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fu, err := fuzzUsecase.NewFuzzUsecase(fr, ru, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fd := fuzzDelivery.NewFuzzDelivery(fu, logger)
+
+	scr, err := st.scanRepository(logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	scanEngine := scanner.NewEngine(scanner.Config{
+		Concurrency: cfg.Scan.Concurrency,
+		RateLimit:   cfg.Scan.RateLimit,
+		Timeout:     cfg.Scan.Timeout,
+	})
+	var oobServer *oob.Server
+	if cfg.OOB.HTTPAddr != "" || cfg.OOB.DNSAddr != "" {
+		oobServer = oob.NewServer(oob.Config{
+			HTTPAddr:   cfg.OOB.HTTPAddr,
+			DNSAddr:    cfg.OOB.DNSAddr,
+			Domain:     cfg.OOB.Domain,
+			PublicHost: cfg.OOB.PublicHost,
+		})
+	}
+	scanRegistry, err := scanner.NewRegistry(checks.All(checks.Config{
+		Delay: cfg.Scan.Delay,
+		OOB:   oobServer,
+	})...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	scu, err := scanUsecase.NewScanUsecase(scr, ru, scanner.NewScanner(scanEngine, scanRegistry), oobServer, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if oobServer != nil {
+		if err := oobServer.Start(); err != nil {
+			log.Fatal(err)
+		}
+		defer oobServer.Close()
+	}
+	scd := scanDelivery.NewScanDelivery(scu, logger)
+	ru.OnCapture(scu.Observe)
+
 	webapi := webapi.NewServer(logger, cfg)
-	webapi.BindRoutes(rd)
+	webapi.BindRoutes(rd, sd, fd, scd)
 
 	// Proxy
 	pr := proxyRepository.NewMemProxyRepository()
@@ -51,6 +127,8 @@ func main() {
 	}
 	proxy := proxyServer.NewProxy(pd, cfg, logger)
 
+	go ru.RunRetention()
+
 	// Run servers
 	errChan := make(chan error, 2)
 
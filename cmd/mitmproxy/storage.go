@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	fuzzRepository "github.com/MatiXxD/go-mitm-proxy/internal/repository/fuzz"
+	requestRepository "github.com/MatiXxD/go-mitm-proxy/internal/repository/request"
+	scanRepository "github.com/MatiXxD/go-mitm-proxy/internal/repository/scan"
+	sessionRepository "github.com/MatiXxD/go-mitm-proxy/internal/repository/session"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/blobstore"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/db/boltdb"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/db/mongodb"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/env"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+const (
+	backendMongo  = "mongo"
+	backendMemory = "memory"
+	backendBolt   = "bolt"
+)
+
+// storage holds connection of the configured backend, only one of them is set.
+type storage struct {
+	backend string
+	mongo   *mongo.Database
+	bolt    *bbolt.DB
+}
+
+func newStorage(cfg *env.Config) (*storage, error) {
+	st := &storage{backend: cfg.StorageConfig.Backend}
+
+	var err error
+	switch st.backend {
+	case backendMongo:
+		st.mongo, err = mongodb.NewMongoDB(context.Background(), cfg)
+	case backendBolt:
+		st.bolt, err = boltdb.NewBoltDB(cfg)
+	case backendMemory:
+	default:
+		err = fmt.Errorf("unknown storage backend %q", st.backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return st, nil
+}
+
+func (st *storage) requestRepository(logger *zap.Logger) (requestRepository.RequestRepository, error) {
+	switch st.backend {
+	case backendMongo:
+		return requestRepository.NewMongoRequestRepository(st.mongo, logger), nil
+	case backendBolt:
+		return requestRepository.NewBoltRequestRepository(st.bolt, logger)
+	default:
+		return requestRepository.NewMemRequestRepository(logger), nil
+	}
+}
+
+func (st *storage) sessionRepository(logger *zap.Logger) (sessionRepository.SessionRepository, error) {
+	switch st.backend {
+	case backendMongo:
+		return sessionRepository.NewMongoSessionRepository(st.mongo, logger), nil
+	case backendBolt:
+		return sessionRepository.NewBoltSessionRepository(st.bolt, logger)
+	default:
+		return sessionRepository.NewMemSessionRepository(logger), nil
+	}
+}
+
+func (st *storage) fuzzRepository(logger *zap.Logger) (fuzzRepository.FuzzRepository, error) {
+	switch st.backend {
+	case backendMongo:
+		return fuzzRepository.NewMongoFuzzRepository(st.mongo, logger), nil
+	case backendBolt:
+		return fuzzRepository.NewBoltFuzzRepository(st.bolt, logger)
+	default:
+		return fuzzRepository.NewMemFuzzRepository(logger), nil
+	}
+}
+
+func (st *storage) scanRepository(logger *zap.Logger) (scanRepository.ScanRepository, error) {
+	switch st.backend {
+	case backendMongo:
+		return scanRepository.NewMongoScanRepository(st.mongo, logger), nil
+	case backendBolt:
+		return scanRepository.NewBoltScanRepository(st.bolt, logger)
+	default:
+		return scanRepository.NewMemScanRepository(logger), nil
+	}
+}
+
+func (st *storage) blobStore(cfg *env.Config) (blobstore.Store, error) {
+	store := cfg.BlobConfig.Store
+	if store == "" {
+		store = "dir"
+		if st.backend == backendMongo {
+			store = "gridfs"
+		}
+	}
+
+	switch store {
+	case "gridfs":
+		if st.mongo == nil {
+			return nil, fmt.Errorf("gridfs blob store needs mongo storage backend")
+		}
+		return blobstore.NewGridFSStore(st.mongo)
+	case "dir":
+		return blobstore.NewDirStore(cfg.BlobConfig.Dir)
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", store)
+	}
+}
@@ -0,0 +1,210 @@
+package fuzz
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	sessionDelivery "github.com/MatiXxD/go-mitm-proxy/internal/delivery/session"
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/internal/usecase/fuzz"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultResultLimit = 100
+	maxResultLimit     = 1000
+)
+
+type FuzzDelivery struct {
+	usecase *fuzz.FuzzUsecase
+	logger  *zap.Logger
+}
+
+func NewFuzzDelivery(usecase *fuzz.FuzzUsecase, logger *zap.Logger) *FuzzDelivery {
+	return &FuzzDelivery{
+		usecase: usecase,
+		logger:  logger,
+	}
+}
+
+// StartAttack starts attack described by models.FuzzConfig in the body and
+// returns it right away, progress is polled with GetAttack.
+func (fd *FuzzDelivery) StartAttack() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		cfg := &models.FuzzConfig{}
+		if err := c.Bind(cfg); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong attack config",
+			})
+		}
+		if _, err := primitive.ObjectIDFromHex(cfg.RequestID); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong request id",
+			})
+		}
+
+		attack, err := fd.usecase.StartAttack(cfg)
+		if err != nil {
+			return fd.fuzzError(c, "StartAttack: ", err)
+		}
+		return c.JSON(http.StatusAccepted, attack)
+	}
+}
+
+// GetAttacks lists attacks of the session from "session" param, of the
+// active session by default.
+func (fd *FuzzDelivery) GetAttacks() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		sessionID, err := sessionDelivery.ParseSession(c, fd.usecase.ActiveSessionID())
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		attacks, err := fd.usecase.GetAttacks(sessionID)
+		if err != nil {
+			return fd.fuzzError(c, "GetAttacks: ", err)
+		}
+		return c.JSON(http.StatusOK, attacks)
+	}
+}
+
+func (fd *FuzzDelivery) GetAttack() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong id",
+			})
+		}
+
+		attack, err := fd.usecase.GetAttack(id)
+		if err != nil {
+			return fd.fuzzError(c, "GetAttack: ", err)
+		}
+		return c.JSON(http.StatusOK, attack)
+	}
+}
+
+// GetResults lists attack results. Query params: sort (index, status,
+// length, time), order (asc, desc), status, matched=true, offset, limit.
+func (fd *FuzzDelivery) GetResults() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong id",
+			})
+		}
+
+		opts, err := parseResultOptions(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		results, err := fd.usecase.GetResults(id, opts)
+		if err != nil {
+			return fd.fuzzError(c, "GetResults: ", err)
+		}
+		return c.JSON(http.StatusOK, results)
+	}
+}
+
+func (fd *FuzzDelivery) CancelAttack() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong id",
+			})
+		}
+
+		if err := fd.usecase.CancelAttack(id); err != nil {
+			return fd.fuzzError(c, "CancelAttack: ", err)
+		}
+		return c.NoContent(http.StatusAccepted)
+	}
+}
+
+func (fd *FuzzDelivery) fuzzError(c echo.Context, msg string, err error) error {
+	switch {
+	case errors.Is(err, fuzz.ErrAttackNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "attack not found",
+		})
+	case errors.Is(err, fuzz.ErrRequestNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "request not found",
+		})
+	case errors.Is(err, fuzz.ErrInvalidAttack):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	case errors.Is(err, fuzz.ErrAttackFinished):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+	default:
+		fd.logger.Error(msg, zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "could not process attack",
+		})
+	}
+}
+
+func parseResultOptions(c echo.Context) (*models.FuzzResultOptions, error) {
+	opts := &models.FuzzResultOptions{
+		SortBy:  models.FuzzSortIndex,
+		Matched: c.QueryParam("matched") == "true",
+		Limit:   defaultResultLimit,
+	}
+
+	switch sortBy := c.QueryParam("sort"); sortBy {
+	case "":
+	case models.FuzzSortIndex, models.FuzzSortStatus, models.FuzzSortLength, models.FuzzSortTime:
+		opts.SortBy = sortBy
+	default:
+		return nil, fmt.Errorf("sort must be index, status, length or time")
+	}
+
+	switch order := c.QueryParam("order"); order {
+	case "", "asc":
+	case "desc":
+		opts.Desc = true
+	default:
+		return nil, fmt.Errorf("order must be asc or desc")
+	}
+
+	if v := c.QueryParam("status"); v != "" {
+		code, err := strconv.Atoi(v)
+		if err != nil || code < 0 {
+			return nil, fmt.Errorf("wrong status")
+		}
+		opts.StatusCode = code
+	}
+
+	if v := c.QueryParam("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("wrong offset")
+		}
+		opts.Offset = offset
+	}
+
+	if v := c.QueryParam("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxResultLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", maxResultLimit)
+		}
+		opts.Limit = limit
+	}
+
+	return opts, nil
+}
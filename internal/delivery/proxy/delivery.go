@@ -2,14 +2,17 @@ package proxy
 
 import (
 	"bufio"
+	"bytes"
 	"crypto/tls"
 	"fmt"
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
 	"github.com/MatiXxD/go-mitm-proxy/internal/usecase/request"
 	"go.uber.org/zap"
 	"io"
 	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/MatiXxD/go-mitm-proxy/internal/repository/proxy"
 	"github.com/MatiXxD/go-mitm-proxy/pkg/env"
@@ -91,6 +94,8 @@ func (pd *ProxyDelivery) handleHTTP(conn net.Conn, req *http.Request, tlsCfg *tl
 	pd.logger.Info(fmt.Sprintln("request info: ", req.Method, req.Host, req.RequestURI))
 	pd.deleteHeaders(req)
 
+	timings := &models.Timings{StartedAt: time.Now()}
+
 	var dial net.Conn
 	var err error
 	if tlsCfg == nil {
@@ -106,15 +111,17 @@ func (pd *ProxyDelivery) handleHTTP(conn net.Conn, req *http.Request, tlsCfg *tl
 		pd.logger.Error("can't dial", zap.Error(err))
 		return fmt.Errorf("can't connect to host: %v", err)
 	}
+	defer dial.Close()
+	timings.Connect = time.Since(timings.StartedAt)
 
-	resp, err := pd.sendRequest(dial, req)
+	resp, err := pd.sendRequest(dial, req, timings)
 	if err != nil {
 		pd.logger.Error("can't send request", zap.Error(err))
 		return fmt.Errorf("can't send request: %v", err)
 	}
 
 	// need to save before resp.Write
-	if err := pd.requestUsecase.AddRequest(req, resp); err != nil {
+	if err := pd.requestUsecase.AddRequest(req, resp, timings); err != nil {
 		pd.logger.Error("can't add request", zap.Error(err))
 	}
 
@@ -126,27 +133,48 @@ func (pd *ProxyDelivery) handleHTTP(conn net.Conn, req *http.Request, tlsCfg *tl
 	return nil
 }
 
-func (pd *ProxyDelivery) sendRequest(dial net.Conn, req *http.Request) (*http.Response, error) {
+func (pd *ProxyDelivery) sendRequest(dial net.Conn, req *http.Request, timings *models.Timings) (*http.Response, error) {
 	// make body readable more than one time
+	var body []byte
 	if req.Body != nil {
 		bytes, err := io.ReadAll(req.Body)
 		if err != nil {
 			pd.logger.Error("error reading body", zap.Error(err))
 			return nil, err
 		}
-		req.Body = io.NopCloser(strings.NewReader(string(bytes)))
+		body = bytes
+		req.Body = io.NopCloser(strings.NewReader(string(body)))
 	}
 
+	start := time.Now()
 	if err := req.Write(dial); err != nil {
 		pd.logger.Error("can't send request", zap.Error(err))
 		return nil, fmt.Errorf("can't send request: %v", err)
 	}
+	timings.Send = time.Since(start)
+	// req.Write consumes body, restore it so it can be stored
+	if body != nil {
+		req.Body = io.NopCloser(strings.NewReader(string(body)))
+	}
 
+	start = time.Now()
 	resp, err := http.ReadResponse(bufio.NewReader(dial), req)
 	if err != nil {
 		pd.logger.Error("can't read response", zap.Error(err))
 		return nil, fmt.Errorf("can't read response: %v", err)
 	}
+	timings.Wait = time.Since(start)
+
+	// read body here, so receive time is measured and upstream conn can be closed
+	start = time.Now()
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		pd.logger.Error("can't read response body", zap.Error(err))
+		return nil, fmt.Errorf("can't read response body: %v", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	timings.Receive = time.Since(start)
 
 	return resp, nil
 }
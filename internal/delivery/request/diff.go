@@ -0,0 +1,50 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/usecase/request"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/diff"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// DiffRequests compares two stored exchanges. "ignore" query param holds
+// comma-separated header names, cookie names and JSON paths like "$.ts"
+// which are reported as volatile.
+func (rd *RequestDelivery) DiffRequests() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		a, b := c.Param("a"), c.Param("b")
+		for _, id := range []string{a, b} {
+			if _, err := primitive.ObjectIDFromHex(id); err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "wrong id",
+				})
+			}
+		}
+
+		opts := &diff.Options{}
+		for _, name := range strings.Split(c.QueryParam("ignore"), ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				opts.Ignore = append(opts.Ignore, name)
+			}
+		}
+
+		res, err := rd.usecase.DiffRequests(a, b, opts)
+		if errors.Is(err, request.ErrRequestNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "request not found",
+			})
+		} else if err != nil {
+			rd.logger.Error("DiffRequests: ", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "could not diff requests",
+			})
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
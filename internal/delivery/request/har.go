@@ -0,0 +1,69 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/usecase/request"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/har"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ExportHAR accepts the same filters as the requests listing.
+func (rd *RequestDelivery) ExportHAR() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		filter, err := parseRequestFilter(c, rd.usecase.ActiveSessionID())
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		h, err := rd.usecase.ExportHAR(filter)
+		if err != nil {
+			rd.logger.Error("ExportHAR: ", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "could not export requests",
+			})
+		}
+
+		filename := fmt.Sprintf("requests-%s.har", time.Now().Format("20060102-150405"))
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+		return c.JSON(http.StatusOK, h)
+	}
+}
+
+// ImportHAR takes HAR log as request body, entries are stored in a new
+// session named by "name" query param.
+func (rd *RequestDelivery) ImportHAR() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		h := &har.HAR{}
+		if err := json.NewDecoder(c.Request().Body).Decode(h); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong har",
+			})
+		}
+
+		sess, imported, err := rd.usecase.ImportHAR(h, c.QueryParam("name"))
+		if errors.Is(err, request.ErrInvalidHAR) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		} else if err != nil {
+			rd.logger.Error("ImportHAR: ", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"error":    "could not import requests",
+				"imported": imported,
+			})
+		}
+
+		return c.JSON(http.StatusCreated, map[string]interface{}{
+			"session":  sess,
+			"imported": imported,
+		})
+	}
+}
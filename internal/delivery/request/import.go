@@ -0,0 +1,71 @@
+package request
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/MatiXxD/go-mitm-proxy/pkg/reqparse"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ImportCurl takes curl command line as request body.
+func (rd *RequestDelivery) ImportCurl() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		data, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "can't read body",
+			})
+		}
+
+		req, err := reqparse.ParseCurl(string(data))
+		return rd.importRequest(c, req, err)
+	}
+}
+
+// ImportRaw takes raw HTTP request as request body, "scheme" query param is
+// used for origin-form request line and defaults to http.
+func (rd *RequestDelivery) ImportRaw() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		scheme := c.QueryParam("scheme")
+		if scheme == "" {
+			scheme = "http"
+		}
+		if scheme != "http" && scheme != "https" {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "scheme must be http or https",
+			})
+		}
+
+		data, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "can't read body",
+			})
+		}
+
+		req, err := reqparse.ParseRaw(data, scheme)
+		return rd.importRequest(c, req, err)
+	}
+}
+
+func (rd *RequestDelivery) importRequest(c echo.Context, req *http.Request, parseErr error) error {
+	if parseErr != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": parseErr.Error(),
+		})
+	}
+
+	id, err := rd.usecase.ImportRequest(req)
+	if err != nil {
+		rd.logger.Error("ImportRequest: ", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "could not import request",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]string{
+		"id": id,
+	})
+}
@@ -0,0 +1,121 @@
+package request
+
+import (
+	"fmt"
+	sessionDelivery "github.com/MatiXxD/go-mitm-proxy/internal/delivery/session"
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/flowfilter"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+func parseListOptions(c echo.Context, activeSession string) (*models.ListOptions, error) {
+	filter, err := parseRequestFilter(c, activeSession)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &models.ListOptions{
+		Filter: *filter,
+		SortBy: models.SortByTime,
+		Desc:   true,
+		Limit:  defaultListLimit,
+	}
+
+	switch sortBy := c.QueryParam("sort"); sortBy {
+	case "", models.SortByTime:
+	case models.SortBySize:
+		opts.SortBy = sortBy
+	default:
+		return nil, fmt.Errorf("sort must be time or size")
+	}
+
+	switch order := c.QueryParam("order"); order {
+	case "", "desc":
+	case "asc":
+		opts.Desc = false
+	default:
+		return nil, fmt.Errorf("order must be asc or desc")
+	}
+
+	if v := c.QueryParam("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxListLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", maxListLimit)
+		}
+		opts.Limit = limit
+	}
+
+	if v := c.QueryParam("cursor"); v != "" {
+		cursor, err := models.DecodeCursor(v)
+		if err != nil {
+			return nil, err
+		}
+		opts.Cursor = cursor
+	}
+
+	return opts, nil
+}
+
+// parseRequestFilter reads filter from query, it is scoped to the session
+// from "session" param, to the active session by default.
+func parseRequestFilter(c echo.Context, activeSession string) (*models.RequestFilter, error) {
+	sessionID, err := sessionDelivery.ParseSession(c, activeSession)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &models.RequestFilter{
+		SessionID:   sessionID,
+		Host:        c.QueryParam("host"),
+		Method:      c.QueryParam("method"),
+		ContentType: c.QueryParam("content_type"),
+		Search:      c.QueryParam("q"),
+	}
+
+	if v := c.QueryParam("parent"); v != "" {
+		if _, err := primitive.ObjectIDFromHex(v); err != nil {
+			return nil, fmt.Errorf("wrong parent: %v", err)
+		}
+		filter.ParentID = v
+	}
+
+	if v := c.QueryParam("filter"); v != "" {
+		expr, err := flowfilter.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("wrong filter: %v", err)
+		}
+		filter.Expr = expr
+	}
+
+	if v := c.QueryParam("status"); v != "" {
+		status, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("wrong status: %v", err)
+		}
+		filter.StatusCode = status
+	}
+
+	if filter.From, err = parseTime(c.QueryParam("from")); err != nil {
+		return nil, fmt.Errorf("wrong from: %v", err)
+	}
+	if filter.To, err = parseTime(c.QueryParam("to")); err != nil {
+		return nil, fmt.Errorf("wrong to: %v", err)
+	}
+
+	return filter, nil
+}
+
+func parseTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
@@ -0,0 +1,88 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/internal/usecase/request"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// RepeatEditedRequest applies models.RequestEdit from the body to the stored
+// request, sends it and returns the new entry with its response. Empty body
+// repeats the request as is.
+func (rd *RequestDelivery) RepeatEditedRequest() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		_, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong id",
+			})
+		}
+
+		edit := &models.RequestEdit{}
+		if c.Request().ContentLength != 0 {
+			if err := c.Bind(edit); err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "wrong edit",
+				})
+			}
+		}
+
+		reqInfo, err := rd.usecase.GetFullRequestById(id)
+		if err != nil {
+			rd.logger.Error("RepeatEditedRequest: ", zap.Error(err))
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "request not found",
+			})
+		}
+
+		edited, err := edit.Apply(reqInfo.Request)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		newID, newReqInfo, err := rd.sendRequest(c.Request().Context(), id, edited)
+		if err != nil {
+			rd.logger.Error("RepeatEditedRequest: ", zap.Error(err))
+			return c.JSON(http.StatusBadGateway, map[string]string{
+				"error": "can't send request",
+			})
+		}
+
+		return c.JSON(http.StatusOK, newRequestInfoView(newID, newReqInfo))
+	}
+}
+
+// GetRequestHistory returns tree of repeats the request belongs to.
+func (rd *RequestDelivery) GetRequestHistory() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		_, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong id",
+			})
+		}
+
+		history, err := rd.usecase.GetHistory(id)
+		if errors.Is(err, request.ErrRequestNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "request not found",
+			})
+		} else if err != nil {
+			rd.logger.Error("GetRequestHistory: ", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "could not retrieve history",
+			})
+		}
+
+		return c.JSON(http.StatusOK, history)
+	}
+}
@@ -1,36 +1,62 @@
 package request
 
 import (
+	"crypto/tls"
+	"errors"
+	sessionDelivery "github.com/MatiXxD/go-mitm-proxy/internal/delivery/session"
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
 	"github.com/MatiXxD/go-mitm-proxy/internal/usecase/request"
-	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner"
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/zap"
 	"net/http"
+	"strconv"
+	"time"
 )
 
+// repeatTimeout limits a repeated request with its response.
+const repeatTimeout = 30 * time.Second
+
 type RequestDelivery struct {
 	usecase *request.RequestUsecase
+	client  *http.Client
 	logger  *zap.Logger
 }
 
 func NewRequestDelivery(usecase *request.RequestUsecase, logger *zap.Logger) *RequestDelivery {
 	return &RequestDelivery{
 		usecase: usecase,
-		logger:  logger,
+		// repeats get the response as it is, redirects are not followed
+		client: &http.Client{
+			Timeout: repeatTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		},
+		logger: logger,
 	}
 }
 
 func (rd *RequestDelivery) GetRequestsInfo() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		reqsInfo, err := rd.usecase.GetRequestsInfo()
+		opts, err := parseListOptions(c, rd.usecase.ActiveSessionID())
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		page, err := rd.usecase.ListRequests(opts)
 		if err != nil {
 			rd.logger.Error("GetRequestsInfo: ", zap.Error(err))
 			return c.JSON(http.StatusInternalServerError, map[string]string{
 				"error": "could not retrieve requests",
 			})
 		}
-		return c.JSON(http.StatusOK, reqsInfo)
+		return c.JSON(http.StatusOK, page)
 	}
 }
 
@@ -52,11 +78,11 @@ func (rd *RequestDelivery) GetRequestById() echo.HandlerFunc {
 			})
 		}
 
-		return c.JSON(http.StatusOK, reqInfo)
+		return c.JSON(http.StatusOK, newRequestInfoView(id, reqInfo))
 	}
 }
 
-func (rd *RequestDelivery) RepeatRequest() echo.HandlerFunc {
+func (rd *RequestDelivery) GetRequestBody() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		id := c.Param("id")
 		_, err := primitive.ObjectIDFromHex(id)
@@ -68,24 +94,55 @@ func (rd *RequestDelivery) RepeatRequest() echo.HandlerFunc {
 
 		reqInfo, err := rd.usecase.GetRequestById(id)
 		if err != nil {
-			rd.logger.Error("RepeatRequest: ", zap.Error(err))
+			rd.logger.Error("GetRequestBody: ", zap.Error(err))
 			return c.JSON(http.StatusNotFound, map[string]string{
 				"error": "request not found",
 			})
 		}
 
-		if err := rd.sendRequest(reqInfo); err != nil {
-			rd.logger.Error("RepeatRequest: ", zap.Error(err))
+		var body []byte
+		var meta models.BodyMeta
+		var header http.Header
+		switch c.Param("part") {
+		case "request":
+			body, meta, header = reqInfo.Request.Body, reqInfo.Request.BodyMeta, reqInfo.Request.Header
+		case "response":
+			if reqInfo.Response == nil {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "response not found",
+				})
+			}
+			body, meta, header = reqInfo.Response.Body, reqInfo.Response.BodyMeta, reqInfo.Response.Header
+		default:
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "part must be request or response",
+			})
+		}
+
+		ct := header.Get("Content-Type")
+		if ct == "" {
+			ct = echo.MIMEOctetStream
+		}
+
+		if meta.Ref == "" {
+			return c.Blob(http.StatusOK, ct, body)
+		}
+
+		r, size, err := rd.usecase.OpenBody(meta.Ref)
+		if err != nil {
+			rd.logger.Error("GetRequestBody: ", zap.Error(err))
 			return c.JSON(http.StatusNotFound, map[string]string{
-				"error": "can't send request",
+				"error": "body not found",
 			})
 		}
+		defer r.Close()
 
-		return c.NoContent(http.StatusOK)
+		c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(size, 10))
+		return c.Stream(http.StatusOK, ct, r)
 	}
 }
 
-func (rd *RequestDelivery) ScanRequest() echo.HandlerFunc {
+func (rd *RequestDelivery) DeleteRequest() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		id := c.Param("id")
 		_, err := primitive.ObjectIDFromHex(id)
@@ -95,7 +152,94 @@ func (rd *RequestDelivery) ScanRequest() echo.HandlerFunc {
 			})
 		}
 
-		reqInfo, err := rd.usecase.GetRequestById(id)
+		if err := rd.usecase.DeleteRequestById(id); err != nil {
+			if errors.Is(err, request.ErrRequestNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "request not found",
+				})
+			}
+			rd.logger.Error("DeleteRequest: ", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "could not delete request",
+			})
+		}
+
+		return c.JSON(http.StatusOK, map[string]int64{
+			"deleted": 1,
+		})
+	}
+}
+
+func (rd *RequestDelivery) DeleteRequests() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		filter, err := parseRequestFilter(c, rd.usecase.ActiveSessionID())
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		// empty filter would delete everything, clear endpoint is used for it
+		if filter.IsEmpty() {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "filter is required",
+			})
+		}
+
+		deleted, err := rd.usecase.DeleteRequests(filter)
+		if err != nil {
+			rd.logger.Error("DeleteRequests: ", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"error":   "could not delete requests",
+				"deleted": deleted,
+			})
+		}
+
+		return c.JSON(http.StatusOK, map[string]int64{
+			"deleted": deleted,
+		})
+	}
+}
+
+func (rd *RequestDelivery) ClearRequests() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		sessionID, err := sessionDelivery.ParseSession(c, rd.usecase.ActiveSessionID())
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		deleted, err := rd.usecase.ClearRequests(sessionID)
+		if errors.Is(err, request.ErrNoSession) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "can't clear all sessions at once",
+			})
+		}
+		if err != nil {
+			rd.logger.Error("ClearRequests: ", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"error":   "could not clear requests",
+				"deleted": deleted,
+			})
+		}
+
+		return c.JSON(http.StatusOK, map[string]int64{
+			"deleted": deleted,
+		})
+	}
+}
+
+func (rd *RequestDelivery) RepeatRequest() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		_, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong id",
+			})
+		}
+
+		reqInfo, err := rd.usecase.GetFullRequestById(id)
 		if err != nil {
 			rd.logger.Error("RepeatRequest: ", zap.Error(err))
 			return c.JSON(http.StatusNotFound, map[string]string{
@@ -103,9 +247,13 @@ func (rd *RequestDelivery) ScanRequest() echo.HandlerFunc {
 			})
 		}
 
-		scanner := scanner.NewInjectionScanner(nil, nil)
-		report := scanner.Scan(reqInfo.Request)
+		if _, _, err := rd.sendRequest(c.Request().Context(), id, reqInfo.Request); err != nil {
+			rd.logger.Error("RepeatRequest: ", zap.Error(err))
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "can't send request",
+			})
+		}
 
-		return c.JSON(http.StatusOK, report)
+		return c.NoContent(http.StatusOK)
 	}
 }
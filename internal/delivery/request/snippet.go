@@ -0,0 +1,48 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/pkg/snippet"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// ExportRequest renders stored request as curl command, raw HTTP message or
+// code snippet, format is one of snippet.Formats.
+func (rd *RequestDelivery) ExportRequest() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		_, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong id",
+			})
+		}
+
+		reqInfo, err := rd.usecase.GetFullRequestById(id)
+		if err != nil {
+			rd.logger.Error("ExportRequest: ", zap.Error(err))
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "request not found",
+			})
+		}
+
+		out, err := snippet.Render(reqInfo.Request, c.Param("format"))
+		if errors.Is(err, snippet.ErrUnknownFormat) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "format must be one of: " + strings.Join(snippet.Formats(), ", "),
+			})
+		} else if err != nil {
+			rd.logger.Error("ExportRequest: ", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "could not export request",
+			})
+		}
+
+		return c.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, []byte(out))
+	}
+}
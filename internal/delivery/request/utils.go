@@ -2,59 +2,40 @@ package request
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/fuzzer"
 	"go.uber.org/zap"
-	"net/http"
+	"io"
+	"time"
 )
 
-func (rd *RequestDelivery) sendRequest(reqInfo *models.RequestInfo) error {
-	req, err := newRequest(reqInfo.Request)
+// sendRequest sends request repeated from parentID and stores it with the
+// response. Sending stops when ctx is done.
+func (rd *RequestDelivery) sendRequest(ctx context.Context, parentID string, parsedReq *models.ParsedRequest) (string, *models.RequestInfo, error) {
+	req, err := fuzzer.NewRequest(ctx, parsedReq)
 	if err != nil {
 		rd.logger.Error("can't create request", zap.Error(err))
-		return err
+		return "", nil, err
 	}
 
-	client := &http.Client{
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
-	}
-
-	resp, err := client.Do(req)
+	timings := &models.Timings{StartedAt: time.Now()}
+	resp, err := rd.client.Do(req)
 	if err != nil {
 		rd.logger.Error("can't send request", zap.Error(err))
-		return err
+		return "", nil, err
 	}
 	defer resp.Body.Close()
+	timings.Wait = time.Since(timings.StartedAt)
 
-	if err := rd.usecase.AddRequest(req, resp); err != nil {
-		rd.logger.Error("can't add request", zap.Error(err))
-		return err
-	}
-
-	return nil
-}
+	// client consumed the body, it is needed again to store the request
+	req.Body = io.NopCloser(bytes.NewReader(parsedReq.Body))
 
-func newRequest(parsedReq *models.ParsedRequest) (*http.Request, error) {
-	req, err := http.NewRequest(parsedReq.Method, parsedReq.URL, bytes.NewBuffer([]byte(parsedReq.Body)))
+	id, reqInfo, err := rd.usecase.AddRepeatedRequest(parentID, req, resp, timings)
 	if err != nil {
-		return nil, err
-	}
-
-	req.Host = parsedReq.URL
-	req.ContentLength = parsedReq.ContentLength
-
-	req.Header = parsedReq.Header
-	for _, cookie := range parsedReq.Cookies {
-		req.AddCookie(cookie)
+		rd.logger.Error("can't add request", zap.Error(err))
+		return "", nil, err
 	}
 
-	req.PostForm = parsedReq.PostForm
-	req.Form = parsedReq.Form
-
-	return req, nil
+	return id, reqInfo, nil
 }
@@ -0,0 +1,89 @@
+package request
+
+import (
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/bodyfmt"
+	"time"
+)
+
+type bodyView struct {
+	Format   string `json:"format"`
+	Content  string `json:"content"`
+	MimeType string `json:"mimeType,omitempty"`
+	Charset  string `json:"charset,omitempty"`
+	Binary   bool   `json:"binary"`
+	Decoded  bool   `json:"decoded"`
+	Size     int64  `json:"size"`
+	Ref      string `json:"ref,omitempty"`
+}
+
+type parsedRequestView struct {
+	*models.ParsedRequest
+	Body bodyView
+}
+
+type parsedResponseView struct {
+	*models.ParsedResponse
+	Body bodyView
+}
+
+type requestInfoView struct {
+	ID        string `json:",omitempty"`
+	Request   *parsedRequestView
+	Response  *parsedResponseView
+	SessionID string
+	ParentID  string `json:",omitempty"`
+	CreatedAt time.Time
+}
+
+func newBodyView(body []byte, meta models.BodyMeta, contentType string) bodyView {
+	content, format := bodyfmt.Format(body, contentType, meta.Binary)
+	if meta.Ref != "" && len(body) == 0 {
+		// offloaded bodies are only available from the body endpoint
+		format = "ref"
+	}
+	return bodyView{
+		Format:   format,
+		Content:  content,
+		MimeType: meta.MimeType,
+		Charset:  meta.Charset,
+		Binary:   meta.Binary,
+		Decoded:  meta.Decoded,
+		Size:     meta.Size,
+		Ref:      meta.Ref,
+	}
+}
+
+func newRequestInfoView(id string, reqInfo *models.RequestInfo) *requestInfoView {
+	view := &requestInfoView{
+		ID:        id,
+		SessionID: reqInfo.SessionID,
+		ParentID:  reqInfo.ParentID,
+		CreatedAt: reqInfo.CreatedAt,
+	}
+
+	if req := reqInfo.Request; req != nil {
+		view.Request = &parsedRequestView{
+			ParsedRequest: req,
+			Body:          newBodyView(req.Body, req.BodyMeta, contentType(req.Header.Get("Content-Type"), req.BodyMeta)),
+		}
+	}
+
+	if resp := reqInfo.Response; resp != nil {
+		view.Response = &parsedResponseView{
+			ParsedResponse: resp,
+			Body:           newBodyView(resp.Body, resp.BodyMeta, contentType(resp.Header.Get("Content-Type"), resp.BodyMeta)),
+		}
+	}
+
+	return view
+}
+
+// contentType prefers the original header, because it keeps parameters
+// like multipart boundary.
+func contentType(header string, meta models.BodyMeta) string {
+	if header != "" {
+		return header
+	}
+	return meta.MimeType
+}
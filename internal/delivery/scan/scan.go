@@ -0,0 +1,227 @@
+package scan
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	sessionDelivery "github.com/MatiXxD/go-mitm-proxy/internal/delivery/session"
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/internal/usecase/scan"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultReportLimit = 50
+	maxReportLimit     = 500
+)
+
+type ScanDelivery struct {
+	usecase *scan.ScanUsecase
+	logger  *zap.Logger
+}
+
+func NewScanDelivery(usecase *scan.ScanUsecase, logger *zap.Logger) *ScanDelivery {
+	return &ScanDelivery{
+		usecase: usecase,
+		logger:  logger,
+	}
+}
+
+type startScanRequest struct {
+	RequestID string             `json:"requestId"`
+	Policy    *models.ScanPolicy `json:"policy"`
+}
+
+// StartScan starts scan of the stored request and returns the job right
+// away, progress is polled with GetJob.
+func (sd *ScanDelivery) StartScan() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := startScanRequest{}
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "requestId is required",
+			})
+		}
+		if _, err := primitive.ObjectIDFromHex(req.RequestID); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong request id",
+			})
+		}
+
+		job, err := sd.usecase.StartScan(req.RequestID, req.Policy)
+		if err != nil {
+			return sd.scanError(c, "StartScan: ", err)
+		}
+		return c.JSON(http.StatusAccepted, job)
+	}
+}
+
+// GetChecks lists checks which can be enabled in scan policy.
+func (sd *ScanDelivery) GetChecks() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, sd.usecase.Checks())
+	}
+}
+
+// GetJobs lists jobs of the session from "session" param, of the active
+// session by default.
+func (sd *ScanDelivery) GetJobs() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		sessionID, err := sessionDelivery.ParseSession(c, sd.usecase.ActiveSessionID())
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		jobs, err := sd.usecase.GetJobs(sessionID)
+		if err != nil {
+			return sd.scanError(c, "GetJobs: ", err)
+		}
+		return c.JSON(http.StatusOK, jobs)
+	}
+}
+
+func (sd *ScanDelivery) GetJob() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong id",
+			})
+		}
+
+		job, err := sd.usecase.GetJob(id)
+		if err != nil {
+			return sd.scanError(c, "GetJob: ", err)
+		}
+		return c.JSON(http.StatusOK, job)
+	}
+}
+
+func (sd *ScanDelivery) CancelScan() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong id",
+			})
+		}
+
+		if err := sd.usecase.CancelScan(id); err != nil {
+			return sd.scanError(c, "CancelScan: ", err)
+		}
+		return c.NoContent(http.StatusAccepted)
+	}
+}
+
+func (sd *ScanDelivery) GetJobReport() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong id",
+			})
+		}
+
+		report, err := sd.usecase.GetJobReport(id)
+		if err != nil {
+			return sd.scanError(c, "GetJobReport: ", err)
+		}
+		return c.JSON(http.StatusOK, report)
+	}
+}
+
+// ListReports returns reports from the newest one. Query params: session
+// (the active one by default, "all"), host (part of the host), request,
+// limit.
+func (sd *ScanDelivery) ListReports() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		sessionID, err := sessionDelivery.ParseSession(c, sd.usecase.ActiveSessionID())
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		filter := &models.ReportFilter{
+			SessionID: sessionID,
+			Host:      c.QueryParam("host"),
+			RequestID: c.QueryParam("request"),
+			Limit:     defaultReportLimit,
+		}
+		if v := c.QueryParam("limit"); v != "" {
+			limit, err := strconv.Atoi(v)
+			if err != nil || limit <= 0 || limit > maxReportLimit {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "limit must be between 1 and " + strconv.Itoa(maxReportLimit),
+				})
+			}
+			filter.Limit = limit
+		}
+
+		reports, err := sd.usecase.ListReports(filter)
+		if err != nil {
+			return sd.scanError(c, "ListReports: ", err)
+		}
+		return c.JSON(http.StatusOK, reports)
+	}
+}
+
+func (sd *ScanDelivery) GetReport() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong id",
+			})
+		}
+
+		report, err := sd.usecase.GetReport(id)
+		if err != nil {
+			return sd.scanError(c, "GetReport: ", err)
+		}
+		return c.JSON(http.StatusOK, report)
+	}
+}
+
+// GetInteractions returns recent out-of-band interactions. Query params:
+// scan (job id).
+func (sd *ScanDelivery) GetInteractions() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		scanID := c.QueryParam("scan")
+		if scanID != "" {
+			if _, err := primitive.ObjectIDFromHex(scanID); err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "wrong scan id",
+				})
+			}
+		}
+		return c.JSON(http.StatusOK, sd.usecase.GetInteractions(scanID))
+	}
+}
+
+func (sd *ScanDelivery) scanError(c echo.Context, msg string, err error) error {
+	switch {
+	case errors.Is(err, scan.ErrJobNotFound), errors.Is(err, scan.ErrReportNotFound), errors.Is(err, scan.ErrRequestNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	case errors.Is(err, scan.ErrInvalidPolicy):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	case errors.Is(err, scan.ErrJobFinished):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+	default:
+		sd.logger.Error(msg, zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "could not process scan",
+		})
+	}
+}
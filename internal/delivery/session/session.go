@@ -0,0 +1,138 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatiXxD/go-mitm-proxy/internal/usecase/session"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// allSessions lifts session scoping of listings
+const allSessions = "all"
+
+type SessionDelivery struct {
+	usecase *session.SessionUsecase
+	logger  *zap.Logger
+}
+
+func NewSessionDelivery(usecase *session.SessionUsecase, logger *zap.Logger) *SessionDelivery {
+	return &SessionDelivery{
+		usecase: usecase,
+		logger:  logger,
+	}
+}
+
+type createSessionRequest struct {
+	Name string `json:"name"`
+}
+
+func (sd *SessionDelivery) GetSessions() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		sessions, err := sd.usecase.GetSessions(c.QueryParam("archived") == "true")
+		if err != nil {
+			sd.logger.Error("GetSessions: ", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "could not retrieve sessions",
+			})
+		}
+		return c.JSON(http.StatusOK, sessions)
+	}
+}
+
+func (sd *SessionDelivery) GetActiveSession() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, sd.usecase.GetActiveSession())
+	}
+}
+
+func (sd *SessionDelivery) CreateSession() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := createSessionRequest{}
+		if err := c.Bind(&req); err != nil || req.Name == "" {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "name is required",
+			})
+		}
+
+		s, err := sd.usecase.CreateSession(req.Name)
+		if err != nil {
+			sd.logger.Error("CreateSession: ", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "could not create session",
+			})
+		}
+		return c.JSON(http.StatusCreated, s)
+	}
+}
+
+func (sd *SessionDelivery) ActivateSession() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong id",
+			})
+		}
+
+		s, err := sd.usecase.ActivateSession(id)
+		if err != nil {
+			return sd.sessionError(c, "ActivateSession: ", err)
+		}
+		return c.JSON(http.StatusOK, s)
+	}
+}
+
+func (sd *SessionDelivery) ArchiveSession() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "wrong id",
+			})
+		}
+
+		s, err := sd.usecase.ArchiveSession(id)
+		if err != nil {
+			return sd.sessionError(c, "ArchiveSession: ", err)
+		}
+		return c.JSON(http.StatusOK, s)
+	}
+}
+
+func (sd *SessionDelivery) sessionError(c echo.Context, msg string, err error) error {
+	switch {
+	case errors.Is(err, session.ErrSessionNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "session not found",
+		})
+	case errors.Is(err, session.ErrSessionArchived), errors.Is(err, session.ErrSessionActive):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+	default:
+		sd.logger.Error(msg, zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "could not update session",
+		})
+	}
+}
+
+// ParseSession reads session listings are scoped to from "session" query
+// param, the active session by default. "all" gives empty ID, which selects
+// every session.
+func ParseSession(c echo.Context, activeSession string) (string, error) {
+	switch v := c.QueryParam("session"); v {
+	case "":
+		return activeSession, nil
+	case allSessions:
+		return "", nil
+	default:
+		if _, err := primitive.ObjectIDFromHex(v); err != nil {
+			return "", fmt.Errorf("wrong session")
+		}
+		return v, nil
+	}
+}
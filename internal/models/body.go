@@ -0,0 +1,106 @@
+package models
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Body is stored as BSON string when it is valid UTF-8, so text bodies stay
+// readable and can be searched by regex in Mongo. Other bodies are stored as
+// BSON binary.
+type Body []byte
+
+func (b Body) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if b == nil {
+		return bson.TypeNull, nil, nil
+	}
+	if utf8.Valid(b) {
+		return bson.MarshalValue(string(b))
+	}
+	return bson.MarshalValue([]byte(b))
+}
+
+func (b *Body) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bson.TypeNull, bson.TypeUndefined:
+		*b = nil
+	case bson.TypeString:
+		*b = Body(raw.StringValue())
+	case bson.TypeBinary:
+		_, bin := raw.Binary()
+		*b = append(Body(nil), bin...)
+	default:
+		return fmt.Errorf("can't decode body from %s", t)
+	}
+	return nil
+}
+
+// BodyMeta describes stored body bytes, so they can be shown without guessing
+// again on every read. Ref is set when the body is kept in the blob store
+// instead of the request document.
+type BodyMeta struct {
+	MimeType string `bson:"mimeType"`
+	Charset  string `bson:"charset"`
+	Binary   bool   `bson:"binary"`
+	Decoded  bool   `bson:"decoded"`
+	Size     int64  `bson:"size"`
+	Ref      string `bson:"ref,omitempty"`
+}
+
+var binaryMimePrefixes = []string{
+	"image/",
+	"audio/",
+	"video/",
+	"font/",
+	"application/octet-stream",
+	"application/pdf",
+	"application/zip",
+	"application/gzip",
+	"application/protobuf",
+	"application/x-protobuf",
+	"application/grpc",
+	"application/wasm",
+}
+
+func NewBodyMeta(header http.Header, body []byte, decoded bool) BodyMeta {
+	meta := BodyMeta{Decoded: decoded, Size: int64(len(body))}
+	if len(body) == 0 {
+		return meta
+	}
+
+	if ct := header.Get("Content-Type"); ct != "" {
+		if mediaType, params, err := mime.ParseMediaType(ct); err == nil {
+			meta.MimeType = mediaType
+			meta.Charset = strings.ToLower(params["charset"])
+		}
+	}
+	if meta.MimeType == "" {
+		mediaType, params, _ := mime.ParseMediaType(http.DetectContentType(body))
+		meta.MimeType = mediaType
+		if meta.Charset == "" {
+			meta.Charset = params["charset"]
+		}
+	}
+
+	for _, prefix := range binaryMimePrefixes {
+		if strings.HasPrefix(meta.MimeType, prefix) {
+			meta.Binary = true
+			break
+		}
+	}
+	if !meta.Binary && meta.Charset == "" && !utf8.Valid(body) {
+		meta.Binary = true
+	}
+	if !meta.Binary && meta.Charset == "" {
+		meta.Charset = "utf-8"
+	}
+
+	return meta
+}
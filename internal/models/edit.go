@@ -0,0 +1,128 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// RequestEdit describes changes applied to a stored request before it is
+// repeated, nil fields are left as they are. Empty header list removes the
+// header, null cookie removes the cookie. Body is base64-encoded when
+// BodyEncoding is "base64".
+type RequestEdit struct {
+	Method       *string             `json:"method"`
+	URL          *string             `json:"url"`
+	Headers      map[string][]string `json:"headers"`
+	Cookies      map[string]*string  `json:"cookies"`
+	Body         *string             `json:"body"`
+	BodyEncoding string              `json:"bodyEncoding"`
+}
+
+// Apply returns edited copy of request, the original is not changed.
+func (e *RequestEdit) Apply(original *ParsedRequest) (*ParsedRequest, error) {
+	req := CloneParsedRequest(original)
+
+	if e.Method != nil {
+		method := strings.TrimSpace(*e.Method)
+		if method == "" || strings.ContainsAny(method, " \t\r\n") {
+			return nil, fmt.Errorf("wrong method %q", *e.Method)
+		}
+		req.Method = method
+	}
+
+	if e.URL != nil {
+		u, err := url.Parse(*e.URL)
+		if err != nil {
+			return nil, fmt.Errorf("wrong url: %v", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("url must be absolute http or https url")
+		}
+		req.URL = u.String()
+		req.Host = u.Host
+	}
+
+	cookieEdited := false
+	for name, values := range e.Headers {
+		name = http.CanonicalHeaderKey(name)
+		cookieEdited = cookieEdited || name == "Cookie"
+		if name == "Host" {
+			if len(values) > 0 {
+				req.Host = values[0]
+			}
+			continue
+		}
+		if len(values) == 0 {
+			req.Header.Del(name)
+			continue
+		}
+		req.Header[name] = append([]string(nil), values...)
+	}
+	if cookieEdited {
+		req.Cookies = (&http.Request{Header: req.Header}).Cookies()
+	}
+
+	if len(e.Cookies) > 0 {
+		req.Cookies = editCookies(req.Cookies, e.Cookies)
+		req.Header.Del("Cookie")
+		if len(req.Cookies) > 0 {
+			pairs := make([]string, len(req.Cookies))
+			for i, c := range req.Cookies {
+				pairs[i] = c.Name + "=" + c.Value
+			}
+			req.Header.Set("Cookie", strings.Join(pairs, "; "))
+		}
+	}
+
+	if e.Body != nil {
+		body := []byte(*e.Body)
+		switch e.BodyEncoding {
+		case "":
+		case "base64":
+			data, err := base64.StdEncoding.DecodeString(*e.Body)
+			if err != nil {
+				return nil, fmt.Errorf("wrong base64 body: %v", err)
+			}
+			body = data
+		default:
+			return nil, fmt.Errorf("unknown body encoding %q", e.BodyEncoding)
+		}
+		req.Body = body
+		req.ContentLength = int64(len(body))
+		req.BodyMeta = NewBodyMeta(req.Header, body, false)
+	}
+
+	return req, nil
+}
+
+// editCookies keeps order of existing cookies, new ones go after them sorted
+// by name.
+func editCookies(cookies []*http.Cookie, edits map[string]*string) []*http.Cookie {
+	res := make([]*http.Cookie, 0, len(cookies)+len(edits))
+	seen := make(map[string]bool)
+	for _, c := range cookies {
+		v, ok := edits[c.Name]
+		seen[c.Name] = true
+		if !ok {
+			res = append(res, c)
+		} else if v != nil {
+			res = append(res, &http.Cookie{Name: c.Name, Value: *v})
+		}
+	}
+
+	names := make([]string, 0, len(edits))
+	for name, v := range edits {
+		if !seen[name] && v != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		res = append(res, &http.Cookie{Name: name, Value: *edits[name]})
+	}
+	return res
+}
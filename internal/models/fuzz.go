@@ -0,0 +1,167 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Attack modes, named the same way as in Burp Intruder.
+const (
+	// FuzzSniper puts every payload of the first set into each point in turn,
+	// other points keep original values.
+	FuzzSniper = "sniper"
+	// FuzzBatteringRam puts the same payload into all points at once.
+	FuzzBatteringRam = "battering-ram"
+	// FuzzPitchfork uses a set per point and iterates them in parallel.
+	FuzzPitchfork = "pitchfork"
+	// FuzzClusterBomb uses a set per point and tries every combination.
+	FuzzClusterBomb = "cluster-bomb"
+)
+
+// Insertion point types.
+const (
+	PointQuery  = "query"
+	PointForm   = "form"
+	PointJSON   = "json"
+	PointHeader = "header"
+	PointCookie = "cookie"
+	PointPath   = "path"
+	PointXML    = "xml"
+)
+
+// Payload set types.
+const (
+	PayloadList       = "list"
+	PayloadNumbers    = "numbers"
+	PayloadBruteforce = "bruteforce"
+)
+
+// Attack statuses.
+const (
+	FuzzRunning   = "running"
+	FuzzDone      = "done"
+	FuzzCancelled = "cancelled"
+	FuzzFailed    = "failed"
+)
+
+// InsertionPoint is a place in the request payloads are put into. Name is
+// parameter, header or cookie name, JSON path like "$.user.ids[0]", XPath
+// of element text or attribute like "/order/item[2]/@id" or index of the
+// path segment starting from 0.
+type InsertionPoint struct {
+	Type string `bson:"type" json:"type"`
+	Name string `bson:"name" json:"name"`
+}
+
+// PayloadSet describes payloads for one position. List uses Values, numbers
+// go From To with Step and optional fmt Format, bruteforce tries every
+// string of Charset with length from MinLength to MaxLength. Encode is ""
+// "url" or "base64" and is applied on top of the value.
+type PayloadSet struct {
+	Type      string   `bson:"type" json:"type"`
+	Values    []string `bson:"values,omitempty" json:"values,omitempty"`
+	From      int64    `bson:"from,omitempty" json:"from,omitempty"`
+	To        int64    `bson:"to,omitempty" json:"to,omitempty"`
+	Step      int64    `bson:"step,omitempty" json:"step,omitempty"`
+	Format    string   `bson:"format,omitempty" json:"format,omitempty"`
+	Charset   string   `bson:"charset,omitempty" json:"charset,omitempty"`
+	MinLength int      `bson:"minLength,omitempty" json:"minLength,omitempty"`
+	MaxLength int      `bson:"maxLength,omitempty" json:"maxLength,omitempty"`
+	Encode    string   `bson:"encode,omitempty" json:"encode,omitempty"`
+}
+
+// FuzzConfig is an attack against the stored request RequestID. Grep is a
+// list of regular expressions searched in every response.
+type FuzzConfig struct {
+	RequestID     string            `bson:"requestId" json:"requestId"`
+	Mode          string            `bson:"mode" json:"mode"`
+	Points        []*InsertionPoint `bson:"points" json:"points"`
+	Payloads      []*PayloadSet     `bson:"payloads" json:"payloads"`
+	Grep          []string          `bson:"grep,omitempty" json:"grep,omitempty"`
+	Concurrency   int               `bson:"concurrency" json:"concurrency"`
+	TimeoutMs     int64             `bson:"timeoutMs" json:"timeoutMs"`
+	SaveResponses bool              `bson:"saveResponses" json:"saveResponses"`
+}
+
+type FuzzAttack struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	Config     *FuzzConfig        `bson:"config" json:"config"`
+	SessionID  string             `bson:"sessionId" json:"sessionId"`
+	Status     string             `bson:"status" json:"status"`
+	Total      int                `bson:"total" json:"total"`
+	Done       int                `bson:"done" json:"done"`
+	Error      string             `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+	FinishedAt *time.Time         `bson:"finishedAt,omitempty" json:"finishedAt,omitempty"`
+}
+
+// NewFuzzAttack makes attack on the request stored in the session.
+func NewFuzzAttack(cfg *FuzzConfig, sessionID string, total int) *FuzzAttack {
+	return &FuzzAttack{
+		ID:        primitive.NewObjectID(),
+		Config:    cfg,
+		SessionID: sessionID,
+		Status:    FuzzRunning,
+		Total:     total,
+		CreatedAt: time.Now(),
+	}
+}
+
+// FuzzResult is one request of the attack. Payloads[i] was put into point
+// Positions[i]. Length is the size of the response body as received, Matches
+// are grep expressions found in the response. RequestID is set when the
+// exchange is stored.
+type FuzzResult struct {
+	AttackID   string   `bson:"attackId" json:"attackId"`
+	Index      int      `bson:"index" json:"index"`
+	Positions  []int    `bson:"positions" json:"positions"`
+	Payloads   []string `bson:"payloads" json:"payloads"`
+	StatusCode int      `bson:"statusCode" json:"statusCode"`
+	Length     int64    `bson:"length" json:"length"`
+	TimeMs     int64    `bson:"timeMs" json:"timeMs"`
+	Matches    []string `bson:"matches,omitempty" json:"matches,omitempty"`
+	Error      string   `bson:"error,omitempty" json:"error,omitempty"`
+	RequestID  string   `bson:"requestId,omitempty" json:"requestId,omitempty"`
+}
+
+// Fuzz result sorts.
+const (
+	FuzzSortIndex  = "index"
+	FuzzSortStatus = "status"
+	FuzzSortLength = "length"
+	FuzzSortTime   = "time"
+)
+
+// FuzzResultOptions selects attack results. Zero StatusCode matches any
+// status, Matched leaves only results with grep matches.
+type FuzzResultOptions struct {
+	SortBy     string
+	Desc       bool
+	StatusCode int
+	Matched    bool
+	Offset     int
+	Limit      int
+}
+
+// Match reports whether result passes the filter part of options.
+func (o *FuzzResultOptions) Match(r *FuzzResult) bool {
+	if o.StatusCode != 0 && r.StatusCode != o.StatusCode {
+		return false
+	}
+	return !o.Matched || len(r.Matches) > 0
+}
+
+// SortValue returns value of result used to sort by o.SortBy.
+func (o *FuzzResultOptions) SortValue(r *FuzzResult) int64 {
+	switch o.SortBy {
+	case FuzzSortStatus:
+		return int64(r.StatusCode)
+	case FuzzSortLength:
+		return r.Length
+	case FuzzSortTime:
+		return r.TimeMs
+	default:
+		return int64(r.Index)
+	}
+}
@@ -0,0 +1,8 @@
+package models
+
+// RequestNode is a request in the history tree of repeats, children are
+// requests repeated from it.
+type RequestNode struct {
+	*RequestSummary
+	Children []*RequestNode `json:"children"`
+}
@@ -0,0 +1,224 @@
+package models
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	SortByTime = "time"
+	SortBySize = "size"
+)
+
+// Matcher is a compiled filter expression, see pkg/flowfilter.
+type Matcher interface {
+	Match(req *RequestInfoWithID) bool
+}
+
+type RequestFilter struct {
+	SessionID   string
+	ParentID    string
+	Host        string
+	Method      string
+	StatusCode  int
+	ContentType string
+	From        time.Time
+	To          time.Time
+	Search      string
+	Expr        Matcher
+}
+
+// Cursor points at the last item of the previous page.
+type Cursor struct {
+	Value int64  `json:"v"`
+	ID    string `json:"id"`
+}
+
+type ListOptions struct {
+	Filter RequestFilter
+	SortBy string
+	Desc   bool
+	Cursor *Cursor
+	Limit  int
+}
+
+// RequestSummary is a lightweight listing entry without bodies.
+type RequestSummary struct {
+	ID          primitive.ObjectID
+	SessionID   string
+	ParentID    string
+	Method      string
+	URL         string
+	Host        string
+	StatusCode  int
+	ContentType string
+	Size        int64
+	CreatedAt   time.Time
+}
+
+type RequestPage struct {
+	Items      []*RequestSummary `json:"items"`
+	NextCursor string            `json:"nextCursor,omitempty"`
+}
+
+func NewRequestSummary(req *RequestInfoWithID) *RequestSummary {
+	summary := &RequestSummary{
+		ID:        req.ID,
+		SessionID: req.SessionID,
+		ParentID:  req.ParentID,
+		Size:      req.Size,
+		CreatedAt: req.CreatedAt,
+	}
+	if req.Request != nil {
+		summary.Method = req.Request.Method
+		summary.URL = req.Request.URL
+		summary.Host = req.Request.Host
+	}
+	if req.Response != nil {
+		summary.StatusCode = req.Response.StatusCode
+		summary.ContentType = req.Response.BodyMeta.MimeType
+	}
+	return summary
+}
+
+// SortValue returns value of the sort field used in cursors.
+func (rs *RequestSummary) SortValue(sortBy string) int64 {
+	if sortBy == SortBySize {
+		return rs.Size
+	}
+	return rs.CreatedAt.UnixMilli()
+}
+
+func EncodeCursor(c *Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("wrong cursor: %v", err)
+	}
+
+	c := &Cursor{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("wrong cursor: %v", err)
+	}
+	if _, err := primitive.ObjectIDFromHex(c.ID); err != nil {
+		return nil, fmt.Errorf("wrong cursor: %v", err)
+	}
+	return c, nil
+}
+
+// IsEmpty reports whether filter has no conditions besides session.
+func (f *RequestFilter) IsEmpty() bool {
+	return f.ParentID == "" && f.Host == "" && f.Method == "" && f.StatusCode == 0 && f.ContentType == "" &&
+		f.From.IsZero() && f.To.IsZero() && f.Search == "" && f.Expr == nil
+}
+
+// Match checks request against filter in process, it follows the same rules
+// as the Mongo query: string filters are case-insensitive, host, content type
+// and search match substrings.
+func (f *RequestFilter) Match(req *RequestInfoWithID) bool {
+	if req.Request == nil {
+		return false
+	}
+
+	if f.SessionID != "" && req.SessionID != f.SessionID {
+		return false
+	}
+	if f.ParentID != "" && req.ParentID != f.ParentID {
+		return false
+	}
+	if f.Host != "" && !containsFold(req.Request.Host, f.Host) {
+		return false
+	}
+	if f.Method != "" && !strings.EqualFold(req.Request.Method, f.Method) {
+		return false
+	}
+	if f.StatusCode != 0 && (req.Response == nil || req.Response.StatusCode != f.StatusCode) {
+		return false
+	}
+	if f.ContentType != "" && (req.Response == nil || !containsFold(req.Response.BodyMeta.MimeType, f.ContentType)) {
+		return false
+	}
+	// Mongo keeps dates with millisecond precision
+	if !f.From.IsZero() && req.CreatedAt.Truncate(time.Millisecond).Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && req.CreatedAt.Truncate(time.Millisecond).After(f.To) {
+		return false
+	}
+	if f.Search != "" && !matchSearch(req, f.Search) {
+		return false
+	}
+	if f.Expr != nil && !f.Expr.Match(req) {
+		return false
+	}
+
+	return true
+}
+
+// AfterCursor reports whether summary goes after cursor in the given order.
+func (o *ListOptions) AfterCursor(rs *RequestSummary) bool {
+	if o.Cursor == nil {
+		return true
+	}
+
+	v := rs.SortValue(o.SortBy)
+	if v != o.Cursor.Value {
+		return (v > o.Cursor.Value) != o.Desc
+	}
+	id := rs.ID.Hex()
+	if id == o.Cursor.ID {
+		return false
+	}
+	return (id > o.Cursor.ID) != o.Desc
+}
+
+func matchSearch(req *RequestInfoWithID, search string) bool {
+	if containsFold(req.Request.URL, search) {
+		return true
+	}
+	if headersContain(req.Request.Header, search) || bodyContains(req.Request.Body, search) {
+		return true
+	}
+	if req.Response != nil && (headersContain(req.Response.Header, search) || bodyContains(req.Response.Body, search)) {
+		return true
+	}
+	return false
+}
+
+func headersContain(header map[string][]string, search string) bool {
+	for k, values := range header {
+		if containsFold(k, search) {
+			return true
+		}
+		for _, v := range values {
+			if containsFold(v, search) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bodyContains skips binary bodies, they are stored as BSON binary and
+// can't be matched by Mongo regex either.
+func bodyContains(body Body, search string) bool {
+	if !utf8.Valid(body) {
+		return false
+	}
+	return bytes.Contains(bytes.ToLower(body), []byte(strings.ToLower(search)))
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"github.com/MatiXxD/go-mitm-proxy/pkg/decoder"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"io"
 	"net/http"
@@ -16,7 +17,8 @@ type ParsedRequest struct {
 	Form          url.Values     `bson:"queryParams"`
 	Header        http.Header    `bson:"headers"`
 	Cookies       []*http.Cookie `bson:"cookies"`
-	Body          string         `bson:"body"`
+	Body          Body           `bson:"body"`
+	BodyMeta      BodyMeta       `bson:"bodyMeta"`
 	ContentLength int64          `bson:"contentLength"`
 	PostForm      url.Values     `bson:"postForm"`
 }
@@ -27,14 +29,14 @@ func NewParsedRequest(r *http.Request) (*ParsedRequest, error) {
 		url = "https://" + r.Host + r.URL.Path
 	}
 
-	body := ""
+	var body []byte
 	if r.Body != nil {
 		bytes, err := io.ReadAll(r.Body)
 		if err != nil {
 			return nil, err
 		}
-		body = string(bytes)
-		r.Body = io.NopCloser(strings.NewReader(body))
+		body = bytes
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
 	}
 
 	err := r.ParseForm()
@@ -46,15 +48,29 @@ func NewParsedRequest(r *http.Request) (*ParsedRequest, error) {
 		Method:        r.Method,
 		URL:           url,
 		Host:          r.Host,
-		Form:          r.Form,
+		Form:          storableValues(r.Form),
 		Header:        r.Header,
 		Cookies:       r.Cookies(),
 		Body:          body,
+		BodyMeta:      NewBodyMeta(r.Header, body, false),
 		ContentLength: r.ContentLength,
-		PostForm:      r.PostForm,
+		PostForm:      storableValues(r.PostForm),
 	}, nil
 }
 
+// storableValues replaces NUL bytes in parameter names, BSON keys can't hold
+// them. URL and body keep the original bytes.
+func storableValues(values url.Values) url.Values {
+	for k, v := range values {
+		if strings.Contains(k, "\x00") {
+			delete(values, k)
+			key := strings.ReplaceAll(k, "\x00", "\uFFFD")
+			values[key] = append(values[key], v...)
+		}
+	}
+	return values
+}
+
 func CloneParsedRequest(original *ParsedRequest) *ParsedRequest {
 	if original == nil {
 		return nil
@@ -94,7 +110,8 @@ func CloneParsedRequest(original *ParsedRequest) *ParsedRequest {
 		Form:          cloneValues(original.Form),
 		Header:        cloneHeaders(original.Header),
 		Cookies:       cloneCookies(original.Cookies),
-		Body:          original.Body,
+		Body:          append([]byte(nil), original.Body...),
+		BodyMeta:      original.BodyMeta,
 		ContentLength: original.ContentLength,
 		PostForm:      cloneValues(original.PostForm),
 	}
@@ -105,48 +122,108 @@ type ParsedResponse struct {
 	StatusCode    int            `bson:"statusCode"`
 	Header        http.Header    `bson:"headers"`
 	Cookies       []*http.Cookie `bson:"cookies"`
-	Body          string         `bson:"body"`
+	Body          Body           `bson:"body"`
+	BodyMeta      BodyMeta       `bson:"bodyMeta"`
 	ContentLength int64          `bson:"contentLength"`
+	RawSize       int64          `bson:"rawSize"`
+	DecodedSize   int64          `bson:"decodedSize"`
 }
 
 func NewParsedResponse(r *http.Response) (*ParsedResponse, error) {
-	body := ""
+	var raw []byte
 	if r.Body != nil {
 		bytes, err := io.ReadAll(r.Body)
 		if err != nil {
 			return nil, err
 		}
-		body = string(bytes)
-		r.Body = io.NopCloser(strings.NewReader(body))
+		raw = bytes
+		// client must get the original bytes, only the stored copy is decoded
+		r.Body = io.NopCloser(strings.NewReader(string(raw)))
 	}
 
+	body, decoded := decodeResponseBody(raw, r.Header)
+
 	return &ParsedResponse{
 		Status:        r.Status,
 		StatusCode:    r.StatusCode,
 		Header:        r.Header,
 		Cookies:       r.Cookies(),
 		Body:          body,
+		BodyMeta:      NewBodyMeta(r.Header, body, decoded),
 		ContentLength: r.ContentLength,
+		RawSize:       int64(len(raw)),
+		DecodedSize:   int64(len(body)),
 	}, nil
 }
 
+// decodeResponseBody returns body without content codings and reports
+// whether any coding was removed. Chunked framing is already removed by
+// http.ReadResponse. If some coding can't be removed the body is returned
+// as is.
+func decodeResponseBody(body []byte, header http.Header) ([]byte, bool) {
+	ce := strings.Join(header.Values("Content-Encoding"), ",")
+	if len(body) == 0 || ce == "" {
+		return body, false
+	}
+	decodedBody, err := decoder.DecodeBody(body, ce)
+	if err != nil {
+		return body, false
+	}
+	return decodedBody, true
+}
+
+// Timings of one exchange, as seen by the proxy.
+type Timings struct {
+	StartedAt time.Time     `bson:"startedAt"`
+	Connect   time.Duration `bson:"connect"`
+	Send      time.Duration `bson:"send"`
+	Wait      time.Duration `bson:"wait"`
+	Receive   time.Duration `bson:"receive"`
+}
+
 type RequestInfo struct {
 	Request   *ParsedRequest  `bson:"request"`
 	Response  *ParsedResponse `bson:"response"`
-	CreatedAt time.Time       `bson:"createdAt"`
+	Timings   *Timings        `bson:"timings,omitempty"`
+	SessionID string          `bson:"sessionId"`
+	// ParentID is set for repeated requests and points at the origin.
+	ParentID  string    `bson:"parentId,omitempty"`
+	Size      int64     `bson:"size"`
+	CreatedAt time.Time `bson:"createdAt"`
 }
 
 func NewRequestInfo(req *ParsedRequest, resp *ParsedResponse) *RequestInfo {
+	size := req.BodyMeta.Size
+	if resp != nil {
+		size += resp.BodyMeta.Size
+	}
+
 	return &RequestInfo{
 		Request:   req,
 		Response:  resp,
+		Size:      size,
 		CreatedAt: time.Now(),
 	}
 }
 
+// Capture is an exchange captured by the proxy, passed to capture listeners
+// once stored. Body is the decoded response body, it is kept even if the
+// stored copy was moved to the blob store.
+type Capture struct {
+	ID        string
+	SessionID string
+	Request   *ParsedRequest
+	Response  *ParsedResponse
+	Body      []byte
+}
+
 type RequestInfoWithID struct {
 	ID        primitive.ObjectID `bson:"_id"`
 	Request   *ParsedRequest     `bson:"request"`
 	Response  *ParsedResponse    `bson:"response"`
+	Timings   *Timings           `bson:"timings,omitempty"`
+	SessionID string             `bson:"sessionId"`
+	ParentID  string             `bson:"parentId,omitempty"`
+	Size      int64              `bson:"size"`
 	CreatedAt time.Time          `bson:"createdAt"`
 }
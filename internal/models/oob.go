@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Out-of-band interaction protocols.
+const (
+	OOBHTTP = "http"
+	OOBDNS  = "dns"
+)
+
+// Interaction is a request to the out-of-band server. PayloadID is the ID
+// handed out with the payload. ScanID and Finding are set when the payload
+// was sent by a scan, Finding tells the check and the point it tested.
+type Interaction struct {
+	PayloadID  string    `json:"payloadId"`
+	Protocol   string    `json:"protocol"`
+	RemoteAddr string    `json:"remoteAddr"`
+	Data       string    `json:"data"`
+	Time       time.Time `json:"time"`
+	ScanID     string    `json:"scanId,omitempty"`
+	Finding    *Finding  `json:"finding,omitempty"`
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Scan job statuses.
+const (
+	ScanRunning   = "running"
+	ScanDone      = "done"
+	ScanCancelled = "cancelled"
+	ScanFailed    = "failed"
+)
+
+// ScanJob is a scan of the stored request RequestID running in background.
+// Done and Total count mutated requests. ReportID is set when the job is
+// finished, cancelled jobs keep report of what was scanned.
+type ScanJob struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	RequestID  string             `bson:"requestId" json:"requestId"`
+	SessionID  string             `bson:"sessionId" json:"sessionId"`
+	Host       string             `bson:"host" json:"host"`
+	Policy     *ScanPolicy        `bson:"policy" json:"policy"`
+	Status     string             `bson:"status" json:"status"`
+	Total      int                `bson:"total" json:"total"`
+	Done       int                `bson:"done" json:"done"`
+	Error      string             `bson:"error,omitempty" json:"error,omitempty"`
+	ReportID   string             `bson:"reportId,omitempty" json:"reportId,omitempty"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+	FinishedAt *time.Time         `bson:"finishedAt,omitempty" json:"finishedAt,omitempty"`
+}
+
+// NewScanJob makes job of the request stored in the session.
+func NewScanJob(requestID, sessionID, host string, policy *ScanPolicy) *ScanJob {
+	return &ScanJob{
+		ID:        primitive.NewObjectID(),
+		RequestID: requestID,
+		SessionID: sessionID,
+		Host:      host,
+		Policy:    policy,
+		Status:    ScanRunning,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Finding severities.
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+	SeverityInfo   = "info"
+)
+
+// ScanPolicy selects checks and insertion point types of a scan, empty
+// lists enable everything.
+type ScanPolicy struct {
+	Checks []string `bson:"checks,omitempty" json:"checks,omitempty"`
+	Points []string `bson:"points,omitempty" json:"points,omitempty"`
+}
+
+// ScanCheck describes a check which can be enabled in ScanPolicy.
+type ScanCheck struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Finding is a vulnerability found by check Check at the insertion point
+// Location/Param. Evidence is the part of the response proving it, Detail
+// tells how it was found when the check has several techniques.
+type Finding struct {
+	Check    string `bson:"check" json:"check"`
+	Name     string `bson:"name" json:"name"`
+	Severity string `bson:"severity" json:"severity"`
+	Location string `bson:"location" json:"location"`
+	Param    string `bson:"param" json:"param"`
+	Payload  string `bson:"payload" json:"payload"`
+	Evidence string `bson:"evidence,omitempty" json:"evidence,omitempty"`
+	Detail   string `bson:"detail,omitempty" json:"detail,omitempty"`
+}
+
+type ScanResult struct {
+	Findings []*Finding    `bson:"findings" json:"findings"`
+	Requests int           `bson:"requests" json:"requests"`
+	Errors   []*ProbeError `bson:"errors" json:"errors"`
+}
+
+// ProbeError is a mutated request which got no response, so nothing is
+// known about the point it tested.
+type ProbeError struct {
+	Location string `bson:"location" json:"location"`
+	Name     string `bson:"name" json:"name"`
+	Payload  string `bson:"payload" json:"payload"`
+	Error    string `bson:"error" json:"error"`
+}
+
+// ScanReport is the stored result of a scan job.
+type ScanReport struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	JobID     string             `bson:"jobId" json:"jobId"`
+	RequestID string             `bson:"requestId" json:"requestId"`
+	SessionID string             `bson:"sessionId" json:"sessionId"`
+	Host      string             `bson:"host" json:"host"`
+	Method    string             `bson:"method" json:"method"`
+	URL       string             `bson:"url" json:"url"`
+	Policy    *ScanPolicy        `bson:"policy" json:"policy"`
+	Complete  bool               `bson:"complete" json:"complete"`
+	Result    *ScanResult        `bson:"result" json:"result"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// ReportFilter selects scan reports, Host matches part of the host. Empty
+// SessionID selects reports of every session.
+type ReportFilter struct {
+	SessionID string
+	Host      string
+	RequestID string
+	Limit     int
+}
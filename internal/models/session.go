@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session groups captured traffic of one engagement or test run. Only one
+// session is active at a time, new traffic is tagged with it.
+type Session struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Name      string             `bson:"name"`
+	Active    bool               `bson:"active"`
+	Archived  bool               `bson:"archived"`
+	CreatedAt time.Time          `bson:"createdAt"`
+}
+
+func NewSession(name string) *Session {
+	return &Session{
+		ID:        primitive.NewObjectID(),
+		Name:      name,
+		CreatedAt: time.Now(),
+	}
+}
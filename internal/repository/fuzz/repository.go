@@ -0,0 +1,20 @@
+package fuzz
+
+import (
+	"errors"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+var ErrNotFound = errors.New("attack not found")
+
+type FuzzRepository interface {
+	AddAttack(attack *models.FuzzAttack) error
+	UpdateAttack(attack *models.FuzzAttack) error
+	GetAttackById(id string) (*models.FuzzAttack, error)
+	// GetAttacks returns attacks of the session, of every session when it is
+	// empty.
+	GetAttacks(sessionID string) ([]*models.FuzzAttack, error)
+	AddResults(results []*models.FuzzResult) error
+	GetResults(attackID string, opts *models.FuzzResultOptions) ([]*models.FuzzResult, error)
+}
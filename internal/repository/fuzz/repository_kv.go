@@ -0,0 +1,200 @@
+package fuzz
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/db/kv"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const (
+	attackBucket = "fuzz_attack"
+	resultBucket = "fuzz_result"
+)
+
+// KVFuzzRepository keeps results keyed by attack ID followed by result
+// index, so results of one attack are read with a prefix scan.
+type KVFuzzRepository struct {
+	attacks kv.Store
+	results kv.Store
+	logger  *zap.Logger
+}
+
+func NewMemFuzzRepository(logger *zap.Logger) *KVFuzzRepository {
+	return &KVFuzzRepository{
+		attacks: kv.NewMemStore(),
+		results: kv.NewMemStore(),
+		logger:  logger,
+	}
+}
+
+func NewBoltFuzzRepository(db *bbolt.DB, logger *zap.Logger) (*KVFuzzRepository, error) {
+	attacks, err := kv.NewBoltStore(db, attackBucket)
+	if err != nil {
+		return nil, err
+	}
+	results, err := kv.NewBoltStore(db, resultBucket)
+	if err != nil {
+		return nil, err
+	}
+	return &KVFuzzRepository{
+		attacks: attacks,
+		results: results,
+		logger:  logger,
+	}, nil
+}
+
+func (fr *KVFuzzRepository) AddAttack(attack *models.FuzzAttack) error {
+	if err := fr.putAttack(attack); err != nil {
+		fr.logger.Error("Failed to insert attack", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (fr *KVFuzzRepository) UpdateAttack(attack *models.FuzzAttack) error {
+	if _, err := fr.attacks.Get(attack.ID[:]); errors.Is(err, kv.ErrNotFound) {
+		return ErrNotFound
+	} else if err != nil {
+		fr.logger.Error("Failed to update attack", zap.Error(err))
+		return err
+	}
+
+	if err := fr.putAttack(attack); err != nil {
+		fr.logger.Error("Failed to update attack", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (fr *KVFuzzRepository) GetAttackById(id string) (*models.FuzzAttack, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		fr.logger.Error("Failed to get attack by id", zap.Error(err))
+		return nil, err
+	}
+
+	doc, err := fr.attacks.Get(objID[:])
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		fr.logger.Error("Failed to get attack by id", zap.Error(err))
+		return nil, err
+	}
+
+	attack := models.FuzzAttack{}
+	if err := bson.Unmarshal(doc, &attack); err != nil {
+		fr.logger.Error("Failed to get attack by id", zap.Error(err))
+		return nil, err
+	}
+	return &attack, nil
+}
+
+func (fr *KVFuzzRepository) GetAttacks(sessionID string) ([]*models.FuzzAttack, error) {
+	attacks := make([]*models.FuzzAttack, 0)
+	err := fr.attacks.ForEach(func(_, doc []byte) error {
+		attack := models.FuzzAttack{}
+		if err := bson.Unmarshal(doc, &attack); err != nil {
+			return err
+		}
+		if sessionID != "" && attack.SessionID != sessionID {
+			return nil
+		}
+		attacks = append(attacks, &attack)
+		return nil
+	})
+	if err != nil {
+		fr.logger.Error("Failed to get attacks", zap.Error(err))
+		return nil, err
+	}
+	return attacks, nil
+}
+
+func (fr *KVFuzzRepository) AddResults(results []*models.FuzzResult) error {
+	keys := make([][]byte, 0, len(results))
+	docs := make([][]byte, 0, len(results))
+	for _, res := range results {
+		key, err := resultKey(res.AttackID, res.Index)
+		if err != nil {
+			fr.logger.Error("Failed to insert fuzz results", zap.Error(err))
+			return err
+		}
+		doc, err := bson.Marshal(res)
+		if err != nil {
+			fr.logger.Error("Failed to insert fuzz results", zap.Error(err))
+			return err
+		}
+		keys = append(keys, key)
+		docs = append(docs, doc)
+	}
+
+	if err := fr.results.PutBatch(keys, docs); err != nil {
+		fr.logger.Error("Failed to insert fuzz results", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (fr *KVFuzzRepository) GetResults(attackID string, opts *models.FuzzResultOptions) ([]*models.FuzzResult, error) {
+	objID, err := primitive.ObjectIDFromHex(attackID)
+	if err != nil {
+		fr.logger.Error("Failed to get fuzz results", zap.Error(err))
+		return nil, err
+	}
+
+	results := make([]*models.FuzzResult, 0)
+	err = fr.results.ForEachPrefix(objID[:], func(_, doc []byte) error {
+		res := models.FuzzResult{}
+		if err := bson.Unmarshal(doc, &res); err != nil {
+			return err
+		}
+		if opts.Match(&res) {
+			results = append(results, &res)
+		}
+		return nil
+	})
+	if err != nil {
+		fr.logger.Error("Failed to get fuzz results", zap.Error(err))
+		return nil, err
+	}
+
+	// keys keep index order, it is the tie breaker
+	sort.SliceStable(results, func(i, j int) bool {
+		va, vb := opts.SortValue(results[i]), opts.SortValue(results[j])
+		if va != vb {
+			return (va < vb) != opts.Desc
+		}
+		return false
+	})
+
+	if opts.Offset >= len(results) {
+		return make([]*models.FuzzResult, 0), nil
+	}
+	results = results[opts.Offset:]
+	if len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results, nil
+}
+
+func (fr *KVFuzzRepository) putAttack(attack *models.FuzzAttack) error {
+	doc, err := bson.Marshal(attack)
+	if err != nil {
+		return err
+	}
+	return fr.attacks.Put(attack.ID[:], doc)
+}
+
+func resultKey(attackID string, index int) ([]byte, error) {
+	objID, err := primitive.ObjectIDFromHex(attackID)
+	if err != nil {
+		return nil, err
+	}
+	return binary.BigEndian.AppendUint32(objID[:], uint32(index)), nil
+}
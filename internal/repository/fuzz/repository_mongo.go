@@ -0,0 +1,167 @@
+package fuzz
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+type MongoFuzzRepository struct {
+	db     *mongo.Database
+	logger *zap.Logger
+}
+
+func NewMongoFuzzRepository(db *mongo.Database, logger *zap.Logger) *MongoFuzzRepository {
+	fr := &MongoFuzzRepository{
+		db:     db,
+		logger: logger,
+	}
+	fr.createIndexes()
+	return fr
+}
+
+// createIndexes adds indexes used by result sorts, failure is only logged.
+func (fr *MongoFuzzRepository) createIndexes() {
+	_, err := fr.db.Collection("fuzz_result").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "attackId", Value: 1}, {Key: "index", Value: 1}}},
+		{Keys: bson.D{{Key: "attackId", Value: 1}, {Key: "statusCode", Value: 1}}},
+		{Keys: bson.D{{Key: "attackId", Value: 1}, {Key: "length", Value: 1}}},
+		{Keys: bson.D{{Key: "attackId", Value: 1}, {Key: "timeMs", Value: 1}}},
+	})
+	if err != nil {
+		fr.logger.Error("Failed to create fuzz result indexes", zap.Error(err))
+	}
+}
+
+func (fr *MongoFuzzRepository) AddAttack(attack *models.FuzzAttack) error {
+	if _, err := fr.db.Collection("fuzz_attack").InsertOne(context.Background(), attack); err != nil {
+		fr.logger.Error("Failed to insert attack", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (fr *MongoFuzzRepository) UpdateAttack(attack *models.FuzzAttack) error {
+	res, err := fr.db.Collection("fuzz_attack").ReplaceOne(context.Background(), bson.M{"_id": attack.ID}, attack)
+	if err != nil {
+		fr.logger.Error("Failed to update attack", zap.Error(err))
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (fr *MongoFuzzRepository) GetAttackById(id string) (*models.FuzzAttack, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		fr.logger.Error("Failed to get attack by id", zap.Error(err))
+		return nil, err
+	}
+
+	attack := models.FuzzAttack{}
+	if err := fr.db.Collection("fuzz_attack").FindOne(context.Background(), bson.M{"_id": objID}).Decode(&attack); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		fr.logger.Error("Failed to get attack by id", zap.Error(err))
+		return nil, err
+	}
+	return &attack, nil
+}
+
+func (fr *MongoFuzzRepository) GetAttacks(sessionID string) ([]*models.FuzzAttack, error) {
+	query := bson.M{}
+	if sessionID != "" {
+		query["sessionId"] = sessionID
+	}
+	findOpts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := fr.db.Collection("fuzz_attack").Find(context.Background(), query, findOpts)
+	if err != nil {
+		fr.logger.Error("Failed to get attacks", zap.Error(err))
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	attacks := make([]*models.FuzzAttack, 0)
+	for cursor.Next(context.Background()) {
+		attack := models.FuzzAttack{}
+		if err := cursor.Decode(&attack); err != nil {
+			fr.logger.Error("Failed to get attacks", zap.Error(err))
+			return nil, err
+		}
+		attacks = append(attacks, &attack)
+	}
+
+	return attacks, nil
+}
+
+func (fr *MongoFuzzRepository) AddResults(results []*models.FuzzResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, len(results))
+	for i, res := range results {
+		docs[i] = res
+	}
+	if _, err := fr.db.Collection("fuzz_result").InsertMany(context.Background(), docs); err != nil {
+		fr.logger.Error("Failed to insert fuzz results", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (fr *MongoFuzzRepository) GetResults(attackID string, opts *models.FuzzResultOptions) ([]*models.FuzzResult, error) {
+	filter := bson.M{"attackId": attackID}
+	if opts.StatusCode != 0 {
+		filter["statusCode"] = opts.StatusCode
+	}
+	if opts.Matched {
+		filter["matches.0"] = bson.M{"$exists": true}
+	}
+
+	sortField := "index"
+	switch opts.SortBy {
+	case models.FuzzSortStatus:
+		sortField = "statusCode"
+	case models.FuzzSortLength:
+		sortField = "length"
+	case models.FuzzSortTime:
+		sortField = "timeMs"
+	}
+	sortDir := 1
+	if opts.Desc {
+		sortDir = -1
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: sortField, Value: sortDir}, {Key: "index", Value: 1}}).
+		SetSkip(int64(opts.Offset)).
+		SetLimit(int64(opts.Limit))
+	cursor, err := fr.db.Collection("fuzz_result").Find(context.Background(), filter, findOpts)
+	if err != nil {
+		fr.logger.Error("Failed to get fuzz results", zap.Error(err))
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	results := make([]*models.FuzzResult, 0, opts.Limit)
+	for cursor.Next(context.Background()) {
+		res := models.FuzzResult{}
+		if err := cursor.Decode(&res); err != nil {
+			fr.logger.Error("Failed to get fuzz results", zap.Error(err))
+			return nil, err
+		}
+		results = append(results, &res)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,190 @@
+package request
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/flowfilter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// summaryProjection leaves only fields needed by models.RequestSummary.
+var summaryProjection = bson.M{
+	"request.method":             1,
+	"request.url":                1,
+	"request.host":               1,
+	"response.statusCode":        1,
+	"response.bodyMeta.mimeType": 1,
+	"sessionId":                  1,
+	"parentId":                   1,
+	"size":                       1,
+	"createdAt":                  1,
+}
+
+// mongoFilter fails on Expr which is not flowfilter.Expr, other matchers
+// can't be compiled to a query.
+func mongoFilter(f *models.RequestFilter) (bson.M, error) {
+	filter := bson.M{}
+	if f.SessionID != "" {
+		filter["sessionId"] = f.SessionID
+	}
+	if f.ParentID != "" {
+		filter["parentId"] = f.ParentID
+	}
+	if f.Host != "" {
+		filter["request.host"] = containsRegex(f.Host)
+	}
+	if f.Method != "" {
+		filter["request.method"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(f.Method) + "$", Options: "i"}
+	}
+	if f.StatusCode != 0 {
+		filter["response.statusCode"] = f.StatusCode
+	}
+	if f.ContentType != "" {
+		filter["response.bodyMeta.mimeType"] = containsRegex(f.ContentType)
+	}
+
+	createdAt := bson.M{}
+	if !f.From.IsZero() {
+		createdAt["$gte"] = f.From
+	}
+	if !f.To.IsZero() {
+		createdAt["$lte"] = f.To
+	}
+	if len(createdAt) > 0 {
+		filter["createdAt"] = createdAt
+	}
+
+	var and bson.A
+	if f.Expr != nil {
+		expr, ok := f.Expr.(flowfilter.Expr)
+		if !ok {
+			return nil, fmt.Errorf("can't compile filter %T to mongo query", f.Expr)
+		}
+		and = append(and, compileExpr(expr))
+	}
+
+	if f.Search != "" {
+		re := containsRegex(f.Search)
+		filter["$or"] = bson.A{
+			bson.M{"request.url": re},
+			bson.M{"request.body": re},
+			bson.M{"response.body": re},
+			bson.M{"$expr": headersMatch("$request.headers", re)},
+			bson.M{"$expr": headersMatch("$response.headers", re)},
+		}
+	}
+
+	if len(and) > 0 {
+		filter["$and"] = and
+	}
+	return filter, nil
+}
+
+// compileExpr builds Mongo query with the same meaning as expr.Match.
+func compileExpr(expr flowfilter.Expr) bson.M {
+	switch e := expr.(type) {
+	case *flowfilter.And:
+		return bson.M{"$and": bson.A{compileExpr(e.Left), compileExpr(e.Right)}}
+	case *flowfilter.Or:
+		return bson.M{"$or": bson.A{compileExpr(e.Left), compileExpr(e.Right)}}
+	case *flowfilter.Not:
+		return bson.M{"$nor": bson.A{compileExpr(e.Expr)}}
+	case *flowfilter.Filter:
+		return compileFilter(e)
+	}
+	// unknown node must not match anything
+	return bson.M{"_id": bson.M{"$exists": false}}
+}
+
+func compileFilter(f *flowfilter.Filter) bson.M {
+	re := primitive.Regex{Pattern: f.Pattern, Options: "i"}
+	switch f.Op {
+	case flowfilter.OpAll:
+		return bson.M{}
+	case flowfilter.OpRequest:
+		return bson.M{"response": nil}
+	case flowfilter.OpResponse:
+		return bson.M{"response": bson.M{"$ne": nil}}
+	case flowfilter.OpDomain:
+		return bson.M{"request.host": re}
+	case flowfilter.OpMethod:
+		return bson.M{"request.method": re}
+	case flowfilter.OpURL:
+		return bson.M{"request.url": re}
+	case flowfilter.OpCode:
+		return bson.M{"response.statusCode": f.Code}
+	case flowfilter.OpHeader, flowfilter.OpHeaderRequest, flowfilter.OpHeaderResponse:
+		return partsFilter(f.Part,
+			bson.M{"$expr": headersMatch("$request.headers", re)},
+			bson.M{"$expr": headersMatch("$response.headers", re)},
+		)
+	case flowfilter.OpBody, flowfilter.OpBodyRequest, flowfilter.OpBodyResponse:
+		return partsFilter(f.Part,
+			bson.M{"request.body": re},
+			bson.M{"response.body": re},
+		)
+	case flowfilter.OpType, flowfilter.OpTypeRequest, flowfilter.OpTypeResponse:
+		return partsFilter(f.Part,
+			bson.M{"request.bodyMeta.mimeType": re},
+			bson.M{"response.bodyMeta.mimeType": re},
+		)
+	}
+	return bson.M{"_id": bson.M{"$exists": false}}
+}
+
+func partsFilter(part flowfilter.Part, req, resp bson.M) bson.M {
+	switch part {
+	case flowfilter.PartRequest:
+		return req
+	case flowfilter.PartResponse:
+		return resp
+	default:
+		return bson.M{"$or": bson.A{req, resp}}
+	}
+}
+
+func cursorFilter(opts *models.ListOptions, sortField string) bson.M {
+	op := "$gt"
+	if opts.Desc {
+		op = "$lt"
+	}
+
+	var value interface{} = opts.Cursor.Value
+	if opts.SortBy != models.SortBySize {
+		value = time.UnixMilli(opts.Cursor.Value)
+	}
+	id, _ := primitive.ObjectIDFromHex(opts.Cursor.ID)
+
+	return bson.M{"$or": bson.A{
+		bson.M{sortField: bson.M{op: value}},
+		bson.M{sortField: value, "_id": bson.M{op: id}},
+	}}
+}
+
+// headersMatch matches regex against name and every value of each header
+// separately, headers are stored as a document keyed by name.
+func headersMatch(field string, re primitive.Regex) bson.M {
+	regexMatch := func(input string) bson.M {
+		return bson.M{"$regexMatch": bson.M{"input": input, "regex": re.Pattern, "options": re.Options}}
+	}
+	return bson.M{"$anyElementTrue": bson.A{bson.M{"$map": bson.M{
+		"input": bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{field, bson.M{}}}},
+		"as":    "h",
+		"in": bson.M{"$or": bson.A{
+			regexMatch("$$h.k"),
+			bson.M{"$anyElementTrue": bson.A{bson.M{"$map": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$$h.v", bson.A{}}},
+				"as":    "v",
+				"in":    regexMatch("$$v"),
+			}}}},
+		}},
+	}}}}
+}
+
+func containsRegex(s string) primitive.Regex {
+	return primitive.Regex{Pattern: regexp.QuoteMeta(s), Options: "i"}
+}
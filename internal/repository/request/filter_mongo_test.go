@@ -0,0 +1,125 @@
+package request
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/flowfilter"
+	"go.uber.org/zap"
+)
+
+type matchFunc func(*models.RequestInfoWithID) bool
+
+func (f matchFunc) Match(req *models.RequestInfoWithID) bool { return f(req) }
+
+func TestMongoFilterUnknownMatcher(t *testing.T) {
+	f := &models.RequestFilter{Expr: matchFunc(func(*models.RequestInfoWithID) bool { return true })}
+	if _, err := mongoFilter(f); err == nil {
+		t.Error("mongoFilter accepted matcher which is not flowfilter.Expr")
+	}
+}
+
+// filterRequests have every field flowfilter looks at, some of them only
+// on one side of the exchange.
+func filterRequests() []*models.RequestInfo {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []*models.RequestInfo{
+		{
+			Request: &models.ParsedRequest{
+				Method:   "POST",
+				URL:      "https://api.example.com/login?next=/home",
+				Host:     "api.example.com",
+				Header:   http.Header{"Content-Type": {"application/json"}, "X-Token": {"abc", "def"}},
+				Body:     models.Body(`{"user":"admin"}`),
+				BodyMeta: models.BodyMeta{MimeType: "application/json"},
+			},
+			Response: &models.ParsedResponse{
+				StatusCode: 302,
+				Header:     http.Header{"Location": {"/home"}},
+				Body:       models.Body("Found"),
+				BodyMeta:   models.BodyMeta{MimeType: "text/html"},
+			},
+			CreatedAt: created,
+		},
+		{
+			Request: &models.ParsedRequest{
+				Method: "GET",
+				URL:    "http://static.test/logo.png",
+				Host:   "static.test",
+				Header: http.Header{"Accept": {"image/*"}},
+			},
+			Response: &models.ParsedResponse{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": {"image/png"}},
+				Body:       models.Body{0x89, 'P', 'N', 'G', 0xff},
+				BodyMeta:   models.BodyMeta{MimeType: "image/png", Binary: true},
+			},
+			CreatedAt: created.Add(time.Second),
+		},
+		{
+			Request: &models.ParsedRequest{
+				Method: "get",
+				URL:    "https://www.example.com/search?q=admin",
+				Host:   "www.example.com",
+			},
+			CreatedAt: created.Add(2 * time.Second),
+		},
+	}
+}
+
+var filterExprs = []string{
+	"~all", "~q", "~s",
+	"~d example", "~d ^EXAMPLE", "~d ^api\\.", "~m ^get$", "~u logo", "admin",
+	"~c 302", "!~c 200", "~c 404",
+	"~h token", "~h ^x-token$", "~h ^def$", `~h "X-Token abc"`, "~hq location", "~hs location", "~h image",
+	"~b admin", "~bq admin", "~bs admin", "~bs found", "~b png",
+	"~t json", "~tq html", "~ts html", "~ts image",
+	"~m get | ~c 302", "~d example & !~s", "!(~q | ~d example)",
+}
+
+// TestCompileExprAgreesWithMatch runs every expression as Mongo query and
+// in process, both must select the same requests.
+func TestCompileExprAgreesWithMatch(t *testing.T) {
+	repo := NewMongoRequestRepository(testMongo(t), zap.NewNop())
+	for _, req := range filterRequests() {
+		if _, err := repo.AddRequest(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var all []*models.RequestInfoWithID
+	err := repo.WalkRequests(&models.RequestFilter{}, func(req *models.RequestInfoWithID) error {
+		all = append(all, req)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range filterExprs {
+		expr, err := flowfilter.Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", s, err)
+		}
+
+		var want, got []string
+		for _, req := range all {
+			if expr.Match(req) {
+				want = append(want, req.ID.Hex())
+			}
+		}
+		err = repo.WalkRequests(&models.RequestFilter{Expr: expr}, func(req *models.RequestInfoWithID) error {
+			got = append(got, req.ID.Hex())
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%q: mongo selects %v, Match selects %v", s, got, want)
+		}
+	}
+}
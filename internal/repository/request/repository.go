@@ -0,0 +1,28 @@
+package request
+
+import (
+	"errors"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+var ErrNotFound = errors.New("request not found")
+
+type RequestRepository interface {
+	// AddRequest stores request and returns its ID.
+	AddRequest(requestInfo *models.RequestInfo) (string, error)
+	ListRequests(opts *models.ListOptions) ([]*models.RequestSummary, error)
+	GetRequestById(id string) (*models.RequestInfo, error)
+	// WalkRequests calls fn for every full request matching filter, from the
+	// oldest one. Walking stops on the first error returned by fn.
+	WalkRequests(filter *models.RequestFilter, fn func(*models.RequestInfoWithID) error) error
+	DeleteRequestById(id string) error
+	DeleteRequests(filter *models.RequestFilter) (int64, error)
+	// TrimRequests deletes the oldest requests until there are at most
+	// maxCount of them with total size at most maxSize, zero disables a limit.
+	TrimRequests(maxCount, maxSize int64) (int64, error)
+	// AssignSession puts requests stored without session into sessionID.
+	AssignSession(sessionID string) (int64, error)
+	// BodyRefs returns blob refs of all offloaded bodies.
+	BodyRefs() (map[string]struct{}, error)
+}
@@ -0,0 +1,466 @@
+package request
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/db/kv"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const (
+	requestBucket      = "request"
+	requestIndexBucket = "request_index"
+)
+
+// Index keys are the prefix of the sort order, the sort value and the ID,
+// so keys of one prefix go in listing order.
+const (
+	indexByTime byte = 't'
+	indexBySize byte = 's'
+)
+
+// KVRequestRepository keeps requests as BSON documents in an ordered
+// key-value store, keyed by ObjectID, so documents look the same as in Mongo.
+// Index keeps summary documents in listing orders, listing reads full
+// documents only for filters which look at headers and bodies.
+type KVRequestRepository struct {
+	store  kv.Store
+	index  kv.Store
+	logger *zap.Logger
+}
+
+func NewMemRequestRepository(logger *zap.Logger) *KVRequestRepository {
+	return &KVRequestRepository{
+		store:  kv.NewMemStore(),
+		index:  kv.NewMemStore(),
+		logger: logger,
+	}
+}
+
+func NewBoltRequestRepository(db *bbolt.DB, logger *zap.Logger) (*KVRequestRepository, error) {
+	store, err := kv.NewBoltStore(db, requestBucket)
+	if err != nil {
+		return nil, err
+	}
+	index, err := kv.NewBoltStore(db, requestIndexBucket)
+	if err != nil {
+		return nil, err
+	}
+	rr := &KVRequestRepository{
+		store:  store,
+		index:  index,
+		logger: logger,
+	}
+	if err := rr.buildIndex(); err != nil {
+		return nil, err
+	}
+	return rr, nil
+}
+
+// buildIndex indexes requests stored before the index existed.
+func (rr *KVRequestRepository) buildIndex() error {
+	empty := true
+	err := rr.index.Seek(nil, nil, false, func(_, _ []byte) error {
+		empty = false
+		return kv.ErrStop
+	})
+	if err != nil || !empty {
+		return err
+	}
+
+	var keys, values [][]byte
+	err = rr.store.ForEach(func(_, doc []byte) error {
+		req := models.RequestInfoWithID{}
+		if err := bson.Unmarshal(doc, &req); err != nil {
+			return err
+		}
+		summary, err := bson.Marshal(summaryDoc(&req))
+		if err != nil {
+			return err
+		}
+		for _, key := range indexKeys(&req) {
+			keys, values = append(keys, key), append(values, summary)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("can't build request index: %v", err)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return rr.index.PutBatch(keys, values)
+}
+
+func (rr *KVRequestRepository) AddRequest(requestInfo *models.RequestInfo) (string, error) {
+	req := &models.RequestInfoWithID{
+		ID:        primitive.NewObjectID(),
+		Request:   requestInfo.Request,
+		Response:  requestInfo.Response,
+		Timings:   requestInfo.Timings,
+		SessionID: requestInfo.SessionID,
+		ParentID:  requestInfo.ParentID,
+		Size:      requestInfo.Size,
+		CreatedAt: requestInfo.CreatedAt,
+	}
+
+	doc, err := bson.Marshal(req)
+	if err != nil {
+		rr.logger.Error("Failed to insert request", zap.Error(err))
+		return "", err
+	}
+	if err := rr.store.Put(req.ID[:], doc); err != nil {
+		rr.logger.Error("Failed to insert request", zap.Error(err))
+		return "", err
+	}
+	if err := rr.addToIndex(req); err != nil {
+		rr.logger.Error("Failed to insert request", zap.Error(err))
+		return "", err
+	}
+	return req.ID.Hex(), nil
+}
+
+func (rr *KVRequestRepository) ListRequests(opts *models.ListOptions) ([]*models.RequestSummary, error) {
+	prefix := indexByTime
+	if opts.SortBy == models.SortBySize {
+		prefix = indexBySize
+	}
+	var start []byte
+	if opts.Cursor != nil {
+		id, _ := primitive.ObjectIDFromHex(opts.Cursor.ID)
+		start = indexKey(prefix, opts.Cursor.Value, id)
+	}
+
+	// search and expressions look at headers and bodies, summaries passing
+	// the rest of the filter are checked against full documents afterwards
+	filter := opts.Filter
+	full := filter.Search != "" || filter.Expr != nil
+	filter.Search, filter.Expr = "", nil
+
+	requests := make([]*models.RequestSummary, 0)
+	err := rr.index.Seek([]byte{prefix}, start, opts.Desc, func(_, doc []byte) error {
+		req := models.RequestInfoWithID{}
+		if err := bson.Unmarshal(doc, &req); err != nil {
+			return err
+		}
+		summary := models.NewRequestSummary(&req)
+		if !opts.AfterCursor(summary) || !filter.Match(&req) {
+			return nil
+		}
+		requests = append(requests, summary)
+		if !full && len(requests) >= opts.Limit {
+			return kv.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		rr.logger.Error("Failed to list requests", zap.Error(err))
+		return nil, err
+	}
+	if !full {
+		return requests, nil
+	}
+
+	matched := make([]*models.RequestSummary, 0)
+	for _, summary := range requests {
+		if len(matched) >= opts.Limit {
+			break
+		}
+		req, err := rr.get(summary.ID)
+		if errors.Is(err, kv.ErrNotFound) {
+			continue
+		} else if err != nil {
+			rr.logger.Error("Failed to list requests", zap.Error(err))
+			return nil, err
+		}
+		if opts.Filter.Match(req) {
+			matched = append(matched, summary)
+		}
+	}
+	return matched, nil
+}
+
+func (rr *KVRequestRepository) GetRequestById(id string) (*models.RequestInfo, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		rr.logger.Error("Failed to get request by id", zap.Error(err))
+		return nil, err
+	}
+
+	req, err := rr.get(objID)
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		rr.logger.Error("Failed to get request by id", zap.Error(err))
+		return nil, err
+	}
+
+	return &models.RequestInfo{
+		Request:   req.Request,
+		Response:  req.Response,
+		Timings:   req.Timings,
+		SessionID: req.SessionID,
+		ParentID:  req.ParentID,
+		Size:      req.Size,
+		CreatedAt: req.CreatedAt,
+	}, nil
+}
+
+func (rr *KVRequestRepository) WalkRequests(filter *models.RequestFilter, fn func(*models.RequestInfoWithID) error) error {
+	var requests []*models.RequestInfoWithID
+	err := rr.store.ForEach(func(_, doc []byte) error {
+		req := models.RequestInfoWithID{}
+		if err := bson.Unmarshal(doc, &req); err != nil {
+			return err
+		}
+		if filter.Match(&req) {
+			requests = append(requests, &req)
+		}
+		return nil
+	})
+	if err != nil {
+		rr.logger.Error("Failed to walk requests", zap.Error(err))
+		return err
+	}
+
+	// imported requests keep their original time, so key order is not enough
+	sort.SliceStable(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.Before(requests[j].CreatedAt)
+	})
+
+	for _, req := range requests {
+		if err := fn(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rr *KVRequestRepository) DeleteRequestById(id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		rr.logger.Error("Failed to delete request", zap.Error(err))
+		return err
+	}
+
+	req, err := rr.get(objID)
+	if errors.Is(err, kv.ErrNotFound) {
+		return ErrNotFound
+	} else if err != nil {
+		rr.logger.Error("Failed to delete request", zap.Error(err))
+		return err
+	}
+
+	if _, err := rr.deleteRequests([]*models.RequestInfoWithID{req}); err != nil {
+		rr.logger.Error("Failed to delete request", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (rr *KVRequestRepository) DeleteRequests(filter *models.RequestFilter) (int64, error) {
+	var requests []*models.RequestInfoWithID
+	err := rr.store.ForEach(func(_, doc []byte) error {
+		req := models.RequestInfoWithID{}
+		if err := bson.Unmarshal(doc, &req); err != nil {
+			return err
+		}
+		if filter.Match(&req) {
+			requests = append(requests, &req)
+		}
+		return nil
+	})
+	if err != nil {
+		rr.logger.Error("Failed to delete requests", zap.Error(err))
+		return 0, err
+	}
+
+	return rr.deleteRequests(requests)
+}
+
+func (rr *KVRequestRepository) TrimRequests(maxCount, maxSize int64) (int64, error) {
+	if maxCount <= 0 && maxSize <= 0 {
+		return 0, nil
+	}
+
+	// the time index goes from the oldest request like the Mongo sort,
+	// imported requests keep their original time, so key order is not enough
+	var requests []*models.RequestInfoWithID
+	var size int64
+	err := rr.index.Seek([]byte{indexByTime}, nil, false, func(_, doc []byte) error {
+		req := models.RequestInfoWithID{}
+		if err := bson.Unmarshal(doc, &req); err != nil {
+			return err
+		}
+		requests = append(requests, &req)
+		size += req.Size
+		return nil
+	})
+	if err != nil {
+		rr.logger.Error("Failed to trim requests", zap.Error(err))
+		return 0, err
+	}
+
+	count := int64(len(requests))
+	var oldest []*models.RequestInfoWithID
+	for _, req := range requests {
+		if (maxCount <= 0 || count <= maxCount) && (maxSize <= 0 || size <= maxSize) {
+			break
+		}
+		oldest = append(oldest, req)
+		count--
+		size -= req.Size
+	}
+
+	return rr.deleteRequests(oldest)
+}
+
+func (rr *KVRequestRepository) AssignSession(sessionID string) (int64, error) {
+	var requests []*models.RequestInfoWithID
+	err := rr.store.ForEach(func(_, doc []byte) error {
+		req := models.RequestInfoWithID{}
+		if err := bson.Unmarshal(doc, &req); err != nil {
+			return err
+		}
+		if req.SessionID == "" {
+			requests = append(requests, &req)
+		}
+		return nil
+	})
+	if err != nil {
+		rr.logger.Error("Failed to assign session", zap.Error(err))
+		return 0, err
+	}
+
+	var assigned int64
+	for _, req := range requests {
+		req.SessionID = sessionID
+		doc, err := bson.Marshal(req)
+		if err != nil {
+			rr.logger.Error("Failed to assign session", zap.Error(err))
+			return assigned, err
+		}
+		if err := rr.store.Put(req.ID[:], doc); err != nil {
+			rr.logger.Error("Failed to assign session", zap.Error(err))
+			return assigned, err
+		}
+		if err := rr.addToIndex(req); err != nil {
+			rr.logger.Error("Failed to assign session", zap.Error(err))
+			return assigned, err
+		}
+		assigned++
+	}
+	return assigned, nil
+}
+
+func (rr *KVRequestRepository) BodyRefs() (map[string]struct{}, error) {
+	refs := make(map[string]struct{})
+	err := rr.store.ForEach(func(_, doc []byte) error {
+		req := models.RequestInfoWithID{}
+		if err := bson.Unmarshal(doc, &req); err != nil {
+			return err
+		}
+		if req.Request != nil && req.Request.BodyMeta.Ref != "" {
+			refs[req.Request.BodyMeta.Ref] = struct{}{}
+		}
+		if req.Response != nil && req.Response.BodyMeta.Ref != "" {
+			refs[req.Response.BodyMeta.Ref] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		rr.logger.Error("Failed to get body refs", zap.Error(err))
+		return nil, err
+	}
+	return refs, nil
+}
+
+// deleteRequests deletes index entries before documents, a request left
+// without them is not listed, but is still found by full scans.
+func (rr *KVRequestRepository) deleteRequests(requests []*models.RequestInfoWithID) (int64, error) {
+	var deleted int64
+	for _, req := range requests {
+		for _, key := range indexKeys(req) {
+			if err := rr.index.Delete(key); err != nil {
+				rr.logger.Error("Failed to delete requests", zap.Error(err))
+				return deleted, err
+			}
+		}
+		if err := rr.store.Delete(req.ID[:]); err != nil {
+			rr.logger.Error("Failed to delete requests", zap.Error(err))
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (rr *KVRequestRepository) get(id primitive.ObjectID) (*models.RequestInfoWithID, error) {
+	doc, err := rr.store.Get(id[:])
+	if err != nil {
+		return nil, err
+	}
+	req := &models.RequestInfoWithID{}
+	if err := bson.Unmarshal(doc, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (rr *KVRequestRepository) addToIndex(req *models.RequestInfoWithID) error {
+	summary, err := bson.Marshal(summaryDoc(req))
+	if err != nil {
+		return err
+	}
+	keys := indexKeys(req)
+	return rr.index.PutBatch(keys, [][]byte{summary, summary})
+}
+
+func indexKeys(req *models.RequestInfoWithID) [][]byte {
+	return [][]byte{
+		indexKey(indexByTime, req.CreatedAt.UnixMilli(), req.ID),
+		indexKey(indexBySize, req.Size, req.ID),
+	}
+}
+
+// indexKey flips the sign bit of value, so negative values go first in byte
+// order too.
+func indexKey(prefix byte, value int64, id primitive.ObjectID) []byte {
+	key := make([]byte, 1, 1+8+len(id))
+	key[0] = prefix
+	key = binary.BigEndian.AppendUint64(key, uint64(value)^(1<<63))
+	return append(key, id[:]...)
+}
+
+// summaryDoc leaves fields of models.RequestSummary, like summaryProjection
+// in Mongo.
+func summaryDoc(req *models.RequestInfoWithID) *models.RequestInfoWithID {
+	doc := &models.RequestInfoWithID{
+		ID:        req.ID,
+		SessionID: req.SessionID,
+		ParentID:  req.ParentID,
+		Size:      req.Size,
+		CreatedAt: req.CreatedAt,
+	}
+	if req.Request != nil {
+		doc.Request = &models.ParsedRequest{
+			Method: req.Request.Method,
+			URL:    req.Request.URL,
+			Host:   req.Request.Host,
+		}
+	}
+	if req.Response != nil {
+		doc.Response = &models.ParsedResponse{
+			StatusCode: req.Response.StatusCode,
+			BodyMeta:   models.BodyMeta{MimeType: req.Response.BodyMeta.MimeType},
+		}
+	}
+	return doc
+}
@@ -0,0 +1,274 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+type MongoRequestRepository struct {
+	db     *mongo.Database
+	logger *zap.Logger
+}
+
+func NewMongoRequestRepository(db *mongo.Database, logger *zap.Logger) *MongoRequestRepository {
+	rr := &MongoRequestRepository{
+		db:     db,
+		logger: logger,
+	}
+	rr.createIndexes()
+	return rr
+}
+
+// createIndexes adds indexes used by listing sorts, failure only makes
+// listing slower, so it is logged and ignored.
+func (rr *MongoRequestRepository) createIndexes() {
+	_, err := rr.db.Collection("request").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "size", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "request.host", Value: 1}}},
+		{Keys: bson.D{{Key: "sessionId", Value: 1}, {Key: "createdAt", Value: 1}}},
+		{Keys: bson.D{{Key: "parentId", Value: 1}}},
+	})
+	if err != nil {
+		rr.logger.Error("Failed to create request indexes", zap.Error(err))
+	}
+}
+
+func (rr *MongoRequestRepository) AddRequest(requestInfo *models.RequestInfo) (string, error) {
+	res, err := rr.db.Collection("request").InsertOne(context.Background(), requestInfo)
+	if err != nil {
+		rr.logger.Error("Failed to insert request", zap.Error(err))
+		return "", err
+	}
+	id, _ := res.InsertedID.(primitive.ObjectID)
+	return id.Hex(), nil
+}
+
+func (rr *MongoRequestRepository) ListRequests(opts *models.ListOptions) ([]*models.RequestSummary, error) {
+	sortField := "createdAt"
+	if opts.SortBy == models.SortBySize {
+		sortField = "size"
+	}
+	sortDir := 1
+	if opts.Desc {
+		sortDir = -1
+	}
+
+	filter, err := mongoFilter(&opts.Filter)
+	if err != nil {
+		rr.logger.Error("Failed to list requests", zap.Error(err))
+		return nil, err
+	}
+	if opts.Cursor != nil {
+		filter = bson.M{"$and": bson.A{filter, cursorFilter(opts, sortField)}}
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: sortField, Value: sortDir}, {Key: "_id", Value: sortDir}}).
+		SetLimit(int64(opts.Limit)).
+		SetProjection(summaryProjection)
+
+	cursor, err := rr.db.Collection("request").Find(context.Background(), filter, findOpts)
+	if err != nil {
+		rr.logger.Error("Failed to list requests", zap.Error(err))
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	requests := make([]*models.RequestSummary, 0, opts.Limit)
+	for cursor.Next(context.Background()) {
+		req := models.RequestInfoWithID{}
+		if err := cursor.Decode(&req); err != nil {
+			rr.logger.Error("Failed to list requests", zap.Error(err))
+			return nil, err
+		}
+		requests = append(requests, models.NewRequestSummary(&req))
+	}
+
+	return requests, nil
+}
+
+func (rr *MongoRequestRepository) GetRequestById(id string) (*models.RequestInfo, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		rr.logger.Error("Failed to get request by id", zap.Error(err))
+		return nil, err
+	}
+
+	req := models.RequestInfo{}
+	if err := rr.db.Collection("request").FindOne(context.Background(), bson.M{"_id": objID}).Decode(&req); err != nil {
+		rr.logger.Error("Failed to get request by id", zap.Error(err))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+func (rr *MongoRequestRepository) WalkRequests(filter *models.RequestFilter, fn func(*models.RequestInfoWithID) error) error {
+	query, err := mongoFilter(filter)
+	if err != nil {
+		rr.logger.Error("Failed to walk requests", zap.Error(err))
+		return err
+	}
+	findOpts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := rr.db.Collection("request").Find(context.Background(), query, findOpts)
+	if err != nil {
+		rr.logger.Error("Failed to walk requests", zap.Error(err))
+		return err
+	}
+	defer cursor.Close(context.Background())
+
+	for cursor.Next(context.Background()) {
+		req := models.RequestInfoWithID{}
+		if err := cursor.Decode(&req); err != nil {
+			rr.logger.Error("Failed to walk requests", zap.Error(err))
+			return err
+		}
+		if err := fn(&req); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (rr *MongoRequestRepository) DeleteRequestById(id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		rr.logger.Error("Failed to delete request", zap.Error(err))
+		return err
+	}
+
+	res, err := rr.db.Collection("request").DeleteOne(context.Background(), bson.M{"_id": objID})
+	if err != nil {
+		rr.logger.Error("Failed to delete request", zap.Error(err))
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (rr *MongoRequestRepository) AssignSession(sessionID string) (int64, error) {
+	res, err := rr.db.Collection("request").UpdateMany(context.Background(),
+		bson.M{"sessionId": bson.M{"$in": bson.A{nil, ""}}},
+		bson.M{"$set": bson.M{"sessionId": sessionID}},
+	)
+	if err != nil {
+		rr.logger.Error("Failed to assign session", zap.Error(err))
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+func (rr *MongoRequestRepository) DeleteRequests(filter *models.RequestFilter) (int64, error) {
+	query, err := mongoFilter(filter)
+	if err != nil {
+		rr.logger.Error("Failed to delete requests", zap.Error(err))
+		return 0, err
+	}
+	res, err := rr.db.Collection("request").DeleteMany(context.Background(), query)
+	if err != nil {
+		rr.logger.Error("Failed to delete requests", zap.Error(err))
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+func (rr *MongoRequestRepository) TrimRequests(maxCount, maxSize int64) (int64, error) {
+	if maxCount <= 0 && maxSize <= 0 {
+		return 0, nil
+	}
+
+	coll := rr.db.Collection("request")
+	cursor, err := coll.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   nil,
+			"count": bson.M{"$sum": 1},
+			"size":  bson.M{"$sum": "$size"},
+		}}},
+	})
+	if err != nil {
+		rr.logger.Error("Failed to trim requests", zap.Error(err))
+		return 0, err
+	}
+	var stats []struct {
+		Count int64 `bson:"count"`
+		Size  int64 `bson:"size"`
+	}
+	if err := cursor.All(context.Background(), &stats); err != nil {
+		rr.logger.Error("Failed to trim requests", zap.Error(err))
+		return 0, err
+	}
+	if len(stats) == 0 {
+		return 0, nil
+	}
+
+	count, size := stats[0].Count, stats[0].Size
+	if (maxCount <= 0 || count <= maxCount) && (maxSize <= 0 || size <= maxSize) {
+		return 0, nil
+	}
+
+	oldest, err := coll.Find(context.Background(), bson.M{}, options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"size": 1}))
+	if err != nil {
+		rr.logger.Error("Failed to trim requests", zap.Error(err))
+		return 0, err
+	}
+	defer oldest.Close(context.Background())
+
+	var ids []primitive.ObjectID
+	for oldest.Next(context.Background()) {
+		if (maxCount <= 0 || count <= maxCount) && (maxSize <= 0 || size <= maxSize) {
+			break
+		}
+		doc := struct {
+			ID   primitive.ObjectID `bson:"_id"`
+			Size int64              `bson:"size"`
+		}{}
+		if err := oldest.Decode(&doc); err != nil {
+			rr.logger.Error("Failed to trim requests", zap.Error(err))
+			return 0, err
+		}
+		ids = append(ids, doc.ID)
+		count--
+		size -= doc.Size
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	res, err := coll.DeleteMany(context.Background(), bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		rr.logger.Error("Failed to trim requests", zap.Error(err))
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+func (rr *MongoRequestRepository) BodyRefs() (map[string]struct{}, error) {
+	refs := make(map[string]struct{})
+	for _, field := range []string{"request.bodyMeta.ref", "response.bodyMeta.ref"} {
+		values, err := rr.db.Collection("request").Distinct(context.Background(), field, bson.M{field: bson.M{"$gt": ""}})
+		if err != nil {
+			rr.logger.Error("Failed to get body refs", zap.Error(err))
+			return nil, err
+		}
+		for _, v := range values {
+			if ref, ok := v.(string); ok {
+				refs[ref] = struct{}{}
+			}
+		}
+	}
+	return refs, nil
+}
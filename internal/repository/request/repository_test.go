@@ -0,0 +1,399 @@
+package request
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/flowfilter"
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// repositories makes an empty repository of every backend. Mongo is
+// skipped without MONGO_TEST_URI.
+var repositories = []struct {
+	name string
+	new  func(t *testing.T) RequestRepository
+}{
+	{"memory", func(t *testing.T) RequestRepository {
+		return NewMemRequestRepository(zap.NewNop())
+	}},
+	{"bolt", func(t *testing.T) RequestRepository {
+		db, err := bbolt.Open(filepath.Join(t.TempDir(), "test.db"), 0o600, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		rr, err := NewBoltRequestRepository(db, zap.NewNop())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rr
+	}},
+	{"mongo", func(t *testing.T) RequestRepository {
+		return NewMongoRequestRepository(testMongo(t), zap.NewNop())
+	}},
+}
+
+// seed stores requests named by their path. Creation time doesn't follow
+// insertion order, like for imported requests.
+type seed struct {
+	path    string
+	session string
+	status  int
+	size    int64
+	age     time.Duration
+	ref     string
+}
+
+var seeds = []seed{
+	{path: "a", session: "s1", status: 200, size: 30, age: 3 * time.Minute},
+	{path: "b", session: "s1", status: 404, size: 10, age: 5 * time.Minute, ref: "blob-b"},
+	{path: "c", session: "s2", status: 200, size: 50, age: 1 * time.Minute},
+	{path: "d", session: "s1", size: 20, age: 4 * time.Minute},
+	{path: "e", session: "s2", status: 500, size: 40, age: 2 * time.Minute, ref: "blob-e"},
+}
+
+var now = time.Now().Truncate(time.Millisecond)
+
+func addSeeds(t *testing.T, rr RequestRepository) map[string]string {
+	t.Helper()
+	ids := make(map[string]string)
+	for _, s := range seeds {
+		req := &models.RequestInfo{
+			Request: &models.ParsedRequest{
+				Method: "GET",
+				URL:    "http://example.com/" + s.path,
+				Host:   "example.com",
+				Body:   models.Body("body " + s.path),
+			},
+			SessionID: s.session,
+			Size:      s.size,
+			CreatedAt: now.Add(-s.age),
+		}
+		if s.status != 0 {
+			req.Response = &models.ParsedResponse{
+				StatusCode: s.status,
+				Body:       models.Body("response " + s.path),
+				BodyMeta:   models.BodyMeta{MimeType: "text/plain", Ref: s.ref},
+			}
+		}
+		id, err := rr.AddRequest(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[s.path] = id
+	}
+	return ids
+}
+
+func paths(summaries []*models.RequestSummary) []string {
+	res := make([]string, 0, len(summaries))
+	for _, s := range summaries {
+		res = append(res, s.URL[len("http://example.com/"):])
+	}
+	return res
+}
+
+func walkPaths(t *testing.T, rr RequestRepository, filter *models.RequestFilter) []string {
+	t.Helper()
+	var res []string
+	err := rr.WalkRequests(filter, func(req *models.RequestInfoWithID) error {
+		res = append(res, req.Request.URL[len("http://example.com/"):])
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestRepositories(t *testing.T) {
+	for _, repo := range repositories {
+		t.Run(repo.name, func(t *testing.T) {
+			t.Run("get", func(t *testing.T) {
+				rr := repo.new(t)
+				ids := addSeeds(t, rr)
+
+				req, err := rr.GetRequestById(ids["b"])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if req.Request.URL != "http://example.com/b" || string(req.Request.Body) != "body b" ||
+					req.Response.StatusCode != 404 || req.SessionID != "s1" || !req.CreatedAt.Equal(now.Add(-5*time.Minute)) {
+					t.Errorf("got %+v", req)
+				}
+
+				req, err = rr.GetRequestById(ids["d"])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if req.Response != nil {
+					t.Errorf("request without response got %+v", req.Response)
+				}
+
+				if _, err := rr.GetRequestById("000000000000000000000000"); !errors.Is(err, ErrNotFound) {
+					t.Errorf("missing request: %v", err)
+				}
+			})
+
+			t.Run("list", func(t *testing.T) {
+				rr := repo.new(t)
+				addSeeds(t, rr)
+
+				tests := []struct {
+					name string
+					opts models.ListOptions
+					want []string
+				}{
+					{"time", models.ListOptions{SortBy: models.SortByTime, Limit: 10}, []string{"b", "d", "a", "e", "c"}},
+					{"time desc", models.ListOptions{SortBy: models.SortByTime, Desc: true, Limit: 10}, []string{"c", "e", "a", "d", "b"}},
+					{"size", models.ListOptions{SortBy: models.SortBySize, Limit: 10}, []string{"b", "d", "a", "e", "c"}},
+					{"limit", models.ListOptions{SortBy: models.SortByTime, Desc: true, Limit: 2}, []string{"c", "e"}},
+					{"session", models.ListOptions{Filter: models.RequestFilter{SessionID: "s2"}, Limit: 10}, []string{"e", "c"}},
+					{"status", models.ListOptions{Filter: models.RequestFilter{StatusCode: 200}, Limit: 10}, []string{"a", "c"}},
+					{"search", models.ListOptions{Filter: models.RequestFilter{Search: "RESPONSE e"}, Limit: 10}, []string{"e"}},
+					{"from", models.ListOptions{Filter: models.RequestFilter{From: now.Add(-3 * time.Minute)}, Limit: 10}, []string{"a", "e", "c"}},
+				}
+				for _, tt := range tests {
+					got, err := rr.ListRequests(&tt.opts)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !slices.Equal(paths(got), tt.want) {
+						t.Errorf("%s: got %v, want %v", tt.name, paths(got), tt.want)
+					}
+				}
+
+				list, err := rr.ListRequests(&models.ListOptions{Limit: 10})
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, summary := range list {
+					i := slices.IndexFunc(seeds, func(s seed) bool { return summary.URL == "http://example.com/"+s.path })
+					s := seeds[i]
+					if summary.SessionID != s.session || summary.StatusCode != s.status || summary.Size != s.size ||
+						summary.Method != "GET" || summary.Host != "example.com" || !summary.CreatedAt.Equal(now.Add(-s.age)) {
+						t.Errorf("summary of %s: %+v", s.path, summary)
+					}
+				}
+			})
+
+			t.Run("pages", func(t *testing.T) {
+				rr := repo.new(t)
+				addSeeds(t, rr)
+
+				for _, sortBy := range []string{models.SortByTime, models.SortBySize} {
+					for _, desc := range []bool{false, true} {
+						opts := &models.ListOptions{SortBy: sortBy, Desc: desc, Limit: 2}
+						var got []string
+						for range len(seeds) {
+							page, err := rr.ListRequests(opts)
+							if err != nil {
+								t.Fatal(err)
+							}
+							got = append(got, paths(page)...)
+							if len(page) < opts.Limit {
+								break
+							}
+							last := page[len(page)-1]
+							opts.Cursor = &models.Cursor{Value: last.SortValue(sortBy), ID: last.ID.Hex()}
+						}
+
+						all, err := rr.ListRequests(&models.ListOptions{SortBy: sortBy, Desc: desc, Limit: 10})
+						if err != nil {
+							t.Fatal(err)
+						}
+						if !slices.Equal(got, paths(all)) {
+							t.Errorf("%s desc=%v: pages %v, all %v", sortBy, desc, got, paths(all))
+						}
+					}
+				}
+			})
+
+			t.Run("walk", func(t *testing.T) {
+				rr := repo.new(t)
+				addSeeds(t, rr)
+
+				if got := walkPaths(t, rr, &models.RequestFilter{}); !slices.Equal(got, []string{"b", "d", "a", "e", "c"}) {
+					t.Errorf("walk got %v", got)
+				}
+				if got := walkPaths(t, rr, &models.RequestFilter{SessionID: "s1"}); !slices.Equal(got, []string{"b", "d", "a"}) {
+					t.Errorf("walk of session got %v", got)
+				}
+			})
+
+			t.Run("delete", func(t *testing.T) {
+				rr := repo.new(t)
+				ids := addSeeds(t, rr)
+
+				if err := rr.DeleteRequestById(ids["a"]); err != nil {
+					t.Fatal(err)
+				}
+				if err := rr.DeleteRequestById(ids["a"]); !errors.Is(err, ErrNotFound) {
+					t.Errorf("second delete: %v", err)
+				}
+				deleted, err := rr.DeleteRequests(&models.RequestFilter{SessionID: "s2"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if deleted != 2 {
+					t.Errorf("deleted %d of session", deleted)
+				}
+
+				list, err := rr.ListRequests(&models.ListOptions{Limit: 10})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := paths(list); !slices.Equal(got, []string{"b", "d"}) {
+					t.Errorf("left %v", got)
+				}
+			})
+
+			t.Run("trim", func(t *testing.T) {
+				tests := []struct {
+					maxCount, maxSize int64
+					deleted           int64
+					left              []string
+				}{
+					{0, 0, 0, []string{"b", "d", "a", "e", "c"}},
+					{3, 0, 2, []string{"a", "e", "c"}},
+					{0, 120, 2, []string{"a", "e", "c"}},
+					{0, 90, 3, []string{"e", "c"}},
+					{4, 140, 1, []string{"d", "a", "e", "c"}},
+				}
+				for _, tt := range tests {
+					rr := repo.new(t)
+					addSeeds(t, rr)
+
+					deleted, err := rr.TrimRequests(tt.maxCount, tt.maxSize)
+					if err != nil {
+						t.Fatal(err)
+					}
+					left := walkPaths(t, rr, &models.RequestFilter{})
+					if deleted != tt.deleted || !slices.Equal(left, tt.left) {
+						t.Errorf("trim %d, %d: deleted %d, left %v, want %d, %v", tt.maxCount, tt.maxSize, deleted, left, tt.deleted, tt.left)
+					}
+				}
+			})
+
+			t.Run("refs", func(t *testing.T) {
+				rr := repo.new(t)
+				addSeeds(t, rr)
+
+				refs, err := rr.BodyRefs()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(refs) != 2 {
+					t.Errorf("got refs %v", refs)
+				}
+				for _, ref := range []string{"blob-b", "blob-e"} {
+					if _, ok := refs[ref]; !ok {
+						t.Errorf("no ref %s in %v", ref, refs)
+					}
+				}
+			})
+
+			t.Run("assign session", func(t *testing.T) {
+				rr := repo.new(t)
+				addSeeds(t, rr)
+				if _, err := rr.AddRequest(&models.RequestInfo{
+					Request:   &models.ParsedRequest{Method: "GET", URL: "http://example.com/old", Host: "example.com"},
+					CreatedAt: now,
+				}); err != nil {
+					t.Fatal(err)
+				}
+
+				assigned, err := rr.AssignSession("s3")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if assigned != 1 {
+					t.Errorf("assigned %d", assigned)
+				}
+				list, err := rr.ListRequests(&models.ListOptions{Filter: models.RequestFilter{SessionID: "s3"}, Limit: 10})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := paths(list); !slices.Equal(got, []string{"old"}) {
+					t.Errorf("session got %v", got)
+				}
+				if assigned, err := rr.AssignSession("s4"); err != nil || assigned != 0 {
+					t.Errorf("second assign: %d, %v", assigned, err)
+				}
+			})
+		})
+	}
+}
+
+// TestRepositoriesFilter checks that every backend selects the same
+// requests for a search or an expression, headers are matched by name and
+// by each value separately.
+func TestRepositoriesFilter(t *testing.T) {
+	const (
+		login  = "https://api.example.com/login?next=/home"
+		logo   = "http://static.test/logo.png"
+		search = "https://www.example.com/search?q=admin"
+	)
+	tests := []struct {
+		search, expr string
+		want         []string
+	}{
+		{search: "token", want: []string{login}},
+		{search: "DEF", want: []string{login}},
+		{search: "x-token abc"},
+		{search: "image/", want: []string{logo}},
+		{search: "admin", want: []string{login, search}},
+		{expr: "~h token", want: []string{login}},
+		{expr: "~h ^x-token$", want: []string{login}},
+		{expr: "~h ^def$", want: []string{login}},
+		{expr: `~h "X-Token abc"`},
+		{expr: `~h "token.*abc"`},
+		{expr: "~hq ^/home$"},
+		{expr: "~hs ^/home$", want: []string{login}},
+		{expr: "~h ^image/png$", want: []string{logo}},
+		{expr: "~hq image", want: []string{logo}},
+		{expr: "~h json & ~m post", want: []string{login}},
+		{expr: "!~h .", want: []string{search}},
+	}
+	for _, repo := range repositories {
+		t.Run(repo.name, func(t *testing.T) {
+			rr := repo.new(t)
+			for _, req := range filterRequests() {
+				if _, err := rr.AddRequest(req); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, tt := range tests {
+				filter := &models.RequestFilter{Search: tt.search}
+				if tt.expr != "" {
+					expr, err := flowfilter.Parse(tt.expr)
+					if err != nil {
+						t.Fatalf("Parse(%q): %v", tt.expr, err)
+					}
+					filter.Expr = expr
+				}
+
+				var got []string
+				err := rr.WalkRequests(filter, func(req *models.RequestInfoWithID) error {
+					got = append(got, req.Request.URL)
+					return nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				slices.Sort(got)
+				want := slices.Sorted(slices.Values(tt.want))
+				if !slices.Equal(got, want) {
+					t.Errorf("search %q, expr %q: got %v, want %v", tt.search, tt.expr, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package scan
+
+import (
+	"errors"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+var ErrNotFound = errors.New("not found")
+
+type ScanRepository interface {
+	AddJob(job *models.ScanJob) error
+	UpdateJob(job *models.ScanJob) error
+	GetJobById(id string) (*models.ScanJob, error)
+	// GetJobs returns jobs of the session, of every session when it is empty.
+	GetJobs(sessionID string) ([]*models.ScanJob, error)
+	AddReport(report *models.ScanReport) error
+	UpdateReport(report *models.ScanReport) error
+	GetReportById(id string) (*models.ScanReport, error)
+	// ListReports returns reports from the newest one.
+	ListReports(filter *models.ReportFilter) ([]*models.ScanReport, error)
+}
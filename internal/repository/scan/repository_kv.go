@@ -0,0 +1,194 @@
+package scan
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/db/kv"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const (
+	jobBucket    = "scan_job"
+	reportBucket = "scan_report"
+)
+
+type KVScanRepository struct {
+	jobs    kv.Store
+	reports kv.Store
+	logger  *zap.Logger
+}
+
+func NewMemScanRepository(logger *zap.Logger) *KVScanRepository {
+	return &KVScanRepository{
+		jobs:    kv.NewMemStore(),
+		reports: kv.NewMemStore(),
+		logger:  logger,
+	}
+}
+
+func NewBoltScanRepository(db *bbolt.DB, logger *zap.Logger) (*KVScanRepository, error) {
+	jobs, err := kv.NewBoltStore(db, jobBucket)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := kv.NewBoltStore(db, reportBucket)
+	if err != nil {
+		return nil, err
+	}
+	return &KVScanRepository{
+		jobs:    jobs,
+		reports: reports,
+		logger:  logger,
+	}, nil
+}
+
+func (sr *KVScanRepository) AddJob(job *models.ScanJob) error {
+	if err := put(sr.jobs, job.ID, job); err != nil {
+		sr.logger.Error("Failed to insert scan job", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (sr *KVScanRepository) UpdateJob(job *models.ScanJob) error {
+	if _, err := sr.jobs.Get(job.ID[:]); errors.Is(err, kv.ErrNotFound) {
+		return ErrNotFound
+	} else if err != nil {
+		sr.logger.Error("Failed to update scan job", zap.Error(err))
+		return err
+	}
+
+	if err := put(sr.jobs, job.ID, job); err != nil {
+		sr.logger.Error("Failed to update scan job", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (sr *KVScanRepository) GetJobById(id string) (*models.ScanJob, error) {
+	job := models.ScanJob{}
+	if err := get(sr.jobs, id, &job); err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			sr.logger.Error("Failed to get scan job by id", zap.Error(err))
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (sr *KVScanRepository) GetJobs(sessionID string) ([]*models.ScanJob, error) {
+	jobs := make([]*models.ScanJob, 0)
+	err := sr.jobs.ForEach(func(_, doc []byte) error {
+		job := models.ScanJob{}
+		if err := bson.Unmarshal(doc, &job); err != nil {
+			return err
+		}
+		if sessionID != "" && job.SessionID != sessionID {
+			return nil
+		}
+		jobs = append(jobs, &job)
+		return nil
+	})
+	if err != nil {
+		sr.logger.Error("Failed to get scan jobs", zap.Error(err))
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (sr *KVScanRepository) AddReport(report *models.ScanReport) error {
+	if err := put(sr.reports, report.ID, report); err != nil {
+		sr.logger.Error("Failed to insert scan report", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (sr *KVScanRepository) UpdateReport(report *models.ScanReport) error {
+	if _, err := sr.reports.Get(report.ID[:]); errors.Is(err, kv.ErrNotFound) {
+		return ErrNotFound
+	} else if err != nil {
+		sr.logger.Error("Failed to update scan report", zap.Error(err))
+		return err
+	}
+
+	if err := put(sr.reports, report.ID, report); err != nil {
+		sr.logger.Error("Failed to update scan report", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (sr *KVScanRepository) GetReportById(id string) (*models.ScanReport, error) {
+	report := models.ScanReport{}
+	if err := get(sr.reports, id, &report); err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			sr.logger.Error("Failed to get scan report by id", zap.Error(err))
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (sr *KVScanRepository) ListReports(filter *models.ReportFilter) ([]*models.ScanReport, error) {
+	host := strings.ToLower(filter.Host)
+	reports := make([]*models.ScanReport, 0)
+	err := sr.reports.ForEach(func(_, doc []byte) error {
+		report := models.ScanReport{}
+		if err := bson.Unmarshal(doc, &report); err != nil {
+			return err
+		}
+		if filter.SessionID != "" && report.SessionID != filter.SessionID {
+			return nil
+		}
+		if host != "" && !strings.Contains(strings.ToLower(report.Host), host) {
+			return nil
+		}
+		if filter.RequestID != "" && report.RequestID != filter.RequestID {
+			return nil
+		}
+		reports = append(reports, &report)
+		return nil
+	})
+	if err != nil {
+		sr.logger.Error("Failed to list scan reports", zap.Error(err))
+		return nil, err
+	}
+
+	// keys are ObjectIDs, so reversed store order is from the newest
+	for i, j := 0, len(reports)-1; i < j; i, j = i+1, j-1 {
+		reports[i], reports[j] = reports[j], reports[i]
+	}
+	if len(reports) > filter.Limit {
+		reports = reports[:filter.Limit]
+	}
+	return reports, nil
+}
+
+func put(store kv.Store, id primitive.ObjectID, v interface{}) error {
+	doc, err := bson.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return store.Put(id[:], doc)
+}
+
+func get(store kv.Store, id string, v interface{}) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	doc, err := store.Get(objID[:])
+	if errors.Is(err, kv.ErrNotFound) {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	return bson.Unmarshal(doc, v)
+}
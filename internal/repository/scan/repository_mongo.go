@@ -0,0 +1,177 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+type MongoScanRepository struct {
+	db     *mongo.Database
+	logger *zap.Logger
+}
+
+func NewMongoScanRepository(db *mongo.Database, logger *zap.Logger) *MongoScanRepository {
+	sr := &MongoScanRepository{
+		db:     db,
+		logger: logger,
+	}
+	sr.createIndexes()
+	return sr
+}
+
+// createIndexes adds indexes used by report listing, failure is only logged.
+func (sr *MongoScanRepository) createIndexes() {
+	_, err := sr.db.Collection("scan_report").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "host", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "requestId", Value: 1}}},
+	})
+	if err != nil {
+		sr.logger.Error("Failed to create scan report indexes", zap.Error(err))
+	}
+}
+
+func (sr *MongoScanRepository) AddJob(job *models.ScanJob) error {
+	if _, err := sr.db.Collection("scan_job").InsertOne(context.Background(), job); err != nil {
+		sr.logger.Error("Failed to insert scan job", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (sr *MongoScanRepository) UpdateJob(job *models.ScanJob) error {
+	res, err := sr.db.Collection("scan_job").ReplaceOne(context.Background(), bson.M{"_id": job.ID}, job)
+	if err != nil {
+		sr.logger.Error("Failed to update scan job", zap.Error(err))
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (sr *MongoScanRepository) GetJobById(id string) (*models.ScanJob, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		sr.logger.Error("Failed to get scan job by id", zap.Error(err))
+		return nil, err
+	}
+
+	job := models.ScanJob{}
+	if err := sr.db.Collection("scan_job").FindOne(context.Background(), bson.M{"_id": objID}).Decode(&job); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		sr.logger.Error("Failed to get scan job by id", zap.Error(err))
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (sr *MongoScanRepository) GetJobs(sessionID string) ([]*models.ScanJob, error) {
+	query := bson.M{}
+	if sessionID != "" {
+		query["sessionId"] = sessionID
+	}
+	findOpts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := sr.db.Collection("scan_job").Find(context.Background(), query, findOpts)
+	if err != nil {
+		sr.logger.Error("Failed to get scan jobs", zap.Error(err))
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	jobs := make([]*models.ScanJob, 0)
+	for cursor.Next(context.Background()) {
+		job := models.ScanJob{}
+		if err := cursor.Decode(&job); err != nil {
+			sr.logger.Error("Failed to get scan jobs", zap.Error(err))
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, nil
+}
+
+func (sr *MongoScanRepository) AddReport(report *models.ScanReport) error {
+	if _, err := sr.db.Collection("scan_report").InsertOne(context.Background(), report); err != nil {
+		sr.logger.Error("Failed to insert scan report", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (sr *MongoScanRepository) UpdateReport(report *models.ScanReport) error {
+	res, err := sr.db.Collection("scan_report").ReplaceOne(context.Background(), bson.M{"_id": report.ID}, report)
+	if err != nil {
+		sr.logger.Error("Failed to update scan report", zap.Error(err))
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (sr *MongoScanRepository) GetReportById(id string) (*models.ScanReport, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		sr.logger.Error("Failed to get scan report by id", zap.Error(err))
+		return nil, err
+	}
+
+	report := models.ScanReport{}
+	if err := sr.db.Collection("scan_report").FindOne(context.Background(), bson.M{"_id": objID}).Decode(&report); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		sr.logger.Error("Failed to get scan report by id", zap.Error(err))
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (sr *MongoScanRepository) ListReports(filter *models.ReportFilter) ([]*models.ScanReport, error) {
+	query := bson.M{}
+	if filter.SessionID != "" {
+		query["sessionId"] = filter.SessionID
+	}
+	if filter.Host != "" {
+		query["host"] = primitive.Regex{Pattern: regexp.QuoteMeta(filter.Host), Options: "i"}
+	}
+	if filter.RequestID != "" {
+		query["requestId"] = filter.RequestID
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(filter.Limit))
+	cursor, err := sr.db.Collection("scan_report").Find(context.Background(), query, findOpts)
+	if err != nil {
+		sr.logger.Error("Failed to list scan reports", zap.Error(err))
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	reports := make([]*models.ScanReport, 0)
+	for cursor.Next(context.Background()) {
+		report := models.ScanReport{}
+		if err := cursor.Decode(&report); err != nil {
+			sr.logger.Error("Failed to list scan reports", zap.Error(err))
+			return nil, err
+		}
+		reports = append(reports, &report)
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,16 @@
+package session
+
+import (
+	"errors"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+var ErrNotFound = errors.New("session not found")
+
+type SessionRepository interface {
+	AddSession(session *models.Session) error
+	UpdateSession(session *models.Session) error
+	GetSessionById(id string) (*models.Session, error)
+	GetSessions() ([]*models.Session, error)
+}
@@ -0,0 +1,108 @@
+package session
+
+import (
+	"errors"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/db/kv"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const sessionBucket = "session"
+
+type KVSessionRepository struct {
+	store  kv.Store
+	logger *zap.Logger
+}
+
+func NewMemSessionRepository(logger *zap.Logger) *KVSessionRepository {
+	return &KVSessionRepository{
+		store:  kv.NewMemStore(),
+		logger: logger,
+	}
+}
+
+func NewBoltSessionRepository(db *bbolt.DB, logger *zap.Logger) (*KVSessionRepository, error) {
+	store, err := kv.NewBoltStore(db, sessionBucket)
+	if err != nil {
+		return nil, err
+	}
+	return &KVSessionRepository{
+		store:  store,
+		logger: logger,
+	}, nil
+}
+
+func (sr *KVSessionRepository) AddSession(session *models.Session) error {
+	if err := sr.put(session); err != nil {
+		sr.logger.Error("Failed to insert session", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (sr *KVSessionRepository) UpdateSession(session *models.Session) error {
+	if _, err := sr.store.Get(session.ID[:]); errors.Is(err, kv.ErrNotFound) {
+		return ErrNotFound
+	} else if err != nil {
+		sr.logger.Error("Failed to update session", zap.Error(err))
+		return err
+	}
+
+	if err := sr.put(session); err != nil {
+		sr.logger.Error("Failed to update session", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (sr *KVSessionRepository) GetSessionById(id string) (*models.Session, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		sr.logger.Error("Failed to get session by id", zap.Error(err))
+		return nil, err
+	}
+
+	doc, err := sr.store.Get(objID[:])
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		sr.logger.Error("Failed to get session by id", zap.Error(err))
+		return nil, err
+	}
+
+	session := models.Session{}
+	if err := bson.Unmarshal(doc, &session); err != nil {
+		sr.logger.Error("Failed to get session by id", zap.Error(err))
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (sr *KVSessionRepository) GetSessions() ([]*models.Session, error) {
+	sessions := make([]*models.Session, 0)
+	err := sr.store.ForEach(func(_, doc []byte) error {
+		session := models.Session{}
+		if err := bson.Unmarshal(doc, &session); err != nil {
+			return err
+		}
+		sessions = append(sessions, &session)
+		return nil
+	})
+	if err != nil {
+		sr.logger.Error("Failed to get sessions", zap.Error(err))
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (sr *KVSessionRepository) put(session *models.Session) error {
+	doc, err := bson.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return sr.store.Put(session.ID[:], doc)
+}
@@ -0,0 +1,86 @@
+package session
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+type MongoSessionRepository struct {
+	db     *mongo.Database
+	logger *zap.Logger
+}
+
+func NewMongoSessionRepository(db *mongo.Database, logger *zap.Logger) *MongoSessionRepository {
+	return &MongoSessionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (sr *MongoSessionRepository) AddSession(session *models.Session) error {
+	if _, err := sr.db.Collection("session").InsertOne(context.Background(), session); err != nil {
+		sr.logger.Error("Failed to insert session", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (sr *MongoSessionRepository) UpdateSession(session *models.Session) error {
+	res, err := sr.db.Collection("session").ReplaceOne(context.Background(), bson.M{"_id": session.ID}, session)
+	if err != nil {
+		sr.logger.Error("Failed to update session", zap.Error(err))
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (sr *MongoSessionRepository) GetSessionById(id string) (*models.Session, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		sr.logger.Error("Failed to get session by id", zap.Error(err))
+		return nil, err
+	}
+
+	session := models.Session{}
+	if err := sr.db.Collection("session").FindOne(context.Background(), bson.M{"_id": objID}).Decode(&session); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		sr.logger.Error("Failed to get session by id", zap.Error(err))
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (sr *MongoSessionRepository) GetSessions() ([]*models.Session, error) {
+	findOpts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := sr.db.Collection("session").Find(context.Background(), bson.D{}, findOpts)
+	if err != nil {
+		sr.logger.Error("Failed to get sessions", zap.Error(err))
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	sessions := make([]*models.Session, 0)
+	for cursor.Next(context.Background()) {
+		session := models.Session{}
+		if err := cursor.Decode(&session); err != nil {
+			sr.logger.Error("Failed to get sessions", zap.Error(err))
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+
+	return sessions, nil
+}
@@ -0,0 +1,259 @@
+package fuzz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/internal/repository/fuzz"
+	"github.com/MatiXxD/go-mitm-proxy/internal/usecase/request"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/fuzzer"
+	"go.uber.org/zap"
+)
+
+const (
+	// results are stored in batches, progress is updated with every batch
+	resultBatchSize     = 100
+	resultFlushInterval = time.Second
+
+	maxTimeout = 2 * time.Minute
+
+	errInterrupted = "attack was interrupted by restart"
+)
+
+var (
+	ErrAttackNotFound  = errors.New("attack not found")
+	ErrAttackFinished  = errors.New("attack is already finished")
+	ErrRequestNotFound = errors.New("request not found")
+	ErrInvalidAttack   = errors.New("invalid attack")
+)
+
+type FuzzUsecase struct {
+	repo     fuzz.FuzzRepository
+	requests *request.RequestUsecase
+	cancels  map[string]context.CancelFunc
+	mu       sync.Mutex
+	logger   *zap.Logger
+}
+
+// NewFuzzUsecase marks attacks left running by the previous process as
+// failed, they can't be resumed.
+func NewFuzzUsecase(repo fuzz.FuzzRepository, requests *request.RequestUsecase, logger *zap.Logger) (*FuzzUsecase, error) {
+	fu := &FuzzUsecase{
+		repo:     repo,
+		requests: requests,
+		cancels:  make(map[string]context.CancelFunc),
+		logger:   logger,
+	}
+
+	attacks, err := repo.GetAttacks("")
+	if err != nil {
+		return nil, fmt.Errorf("can't load attacks: %v", err)
+	}
+	for _, attack := range attacks {
+		if attack.Status == models.FuzzRunning {
+			fu.finish(attack, models.FuzzFailed, errInterrupted)
+		}
+	}
+
+	return fu, nil
+}
+
+// StartAttack checks the config against the stored request and runs the
+// attack in background. Returned attack is in running state.
+func (fu *FuzzUsecase) StartAttack(cfg *models.FuzzConfig) (*models.FuzzAttack, error) {
+	reqInfo, err := fu.requests.GetFullRequestById(cfg.RequestID)
+	if err != nil {
+		return nil, ErrRequestNotFound
+	}
+
+	attack, err := fuzzer.NewAttack(cfg.Mode, len(cfg.Points), cfg.Payloads)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAttack, err)
+	}
+	for _, point := range cfg.Points {
+		if point == nil {
+			return nil, fmt.Errorf("%w: insertion point is empty", ErrInvalidAttack)
+		}
+	}
+	// every item uses the same points, so the first one shows config errors
+	if _, err := fuzzer.Inject(reqInfo.Request, cfg.Points, attack.Item(0)); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAttack, err)
+	}
+
+	// defaults are stored, so the attack shows what was actually used
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = fuzzer.DefaultConcurrency
+	}
+	if cfg.TimeoutMs <= 0 {
+		cfg.TimeoutMs = fuzzer.DefaultTimeout.Milliseconds()
+	}
+	timeout := time.Duration(cfg.TimeoutMs) * time.Millisecond
+	if timeout > maxTimeout {
+		return nil, fmt.Errorf("%w: timeout must be at most %v", ErrInvalidAttack, maxTimeout)
+	}
+	engine, err := fuzzer.NewEngine(cfg.Concurrency, timeout, cfg.Grep)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAttack, err)
+	}
+
+	a := models.NewFuzzAttack(cfg, reqInfo.SessionID, attack.Total())
+	if err := fu.repo.AddAttack(a); err != nil {
+		fu.logger.Error("failed to add attack", zap.Error(err))
+		return nil, fmt.Errorf("failed to add attack to db")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fu.mu.Lock()
+	fu.cancels[a.ID.Hex()] = cancel
+	fu.mu.Unlock()
+
+	started := *a
+	go fu.run(ctx, a, engine, reqInfo.Request, attack)
+	return &started, nil
+}
+
+func (fu *FuzzUsecase) run(ctx context.Context, a *models.FuzzAttack, engine *fuzzer.Engine, base *models.ParsedRequest, attack *fuzzer.Attack) {
+	id := a.ID.Hex()
+	defer func() {
+		fu.mu.Lock()
+		fu.cancels[id]()
+		delete(fu.cancels, id)
+		fu.mu.Unlock()
+	}()
+
+	var mu sync.Mutex
+	var batch []*models.FuzzResult
+	var storeErr error
+	lastFlush := time.Now()
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := fu.repo.AddResults(batch); err != nil {
+			storeErr = err
+			return
+		}
+		a.Done += len(batch)
+		batch = batch[:0]
+		lastFlush = time.Now()
+		if err := fu.repo.UpdateAttack(a); err != nil {
+			fu.logger.Error("failed to update attack progress", zap.Error(err))
+		}
+	}
+
+	err := engine.Run(ctx, base, a.Config.Points, attack, func(res *models.FuzzResult, ex *fuzzer.Exchange) {
+		res.AttackID = id
+		if a.Config.SaveResponses && ex != nil {
+			reqID, _, err := fu.requests.AddRepeatedRequest(a.Config.RequestID, ex.Request, ex.Response, ex.Timings)
+			if err != nil {
+				fu.logger.Error("failed to store fuzz exchange", zap.Error(err))
+			}
+			res.RequestID = reqID
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if storeErr != nil {
+			return
+		}
+		batch = append(batch, res)
+		if len(batch) >= resultBatchSize || time.Since(lastFlush) >= resultFlushInterval {
+			flush()
+			if storeErr != nil {
+				fu.mu.Lock()
+				fu.cancels[id]()
+				fu.mu.Unlock()
+			}
+		}
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if storeErr == nil {
+		flush()
+	}
+
+	switch {
+	case storeErr != nil:
+		fu.logger.Error("failed to store fuzz results", zap.Error(storeErr))
+		fu.finish(a, models.FuzzFailed, "failed to store results")
+	case errors.Is(err, context.Canceled):
+		fu.finish(a, models.FuzzCancelled, "")
+	default:
+		fu.finish(a, models.FuzzDone, "")
+	}
+}
+
+func (fu *FuzzUsecase) finish(a *models.FuzzAttack, status, errMsg string) {
+	now := time.Now()
+	a.Status = status
+	a.Error = errMsg
+	a.FinishedAt = &now
+	if err := fu.repo.UpdateAttack(a); err != nil {
+		fu.logger.Error("failed to finish attack", zap.Error(err))
+	}
+}
+
+// CancelAttack stops running attack, results received so far are kept.
+// Attack becomes cancelled once in-flight requests are aborted.
+func (fu *FuzzUsecase) CancelAttack(id string) error {
+	fu.mu.Lock()
+	cancel, ok := fu.cancels[id]
+	fu.mu.Unlock()
+	if ok {
+		cancel()
+		return nil
+	}
+
+	if _, err := fu.GetAttack(id); err != nil {
+		return err
+	}
+	return ErrAttackFinished
+}
+
+func (fu *FuzzUsecase) GetAttack(id string) (*models.FuzzAttack, error) {
+	attack, err := fu.repo.GetAttackById(id)
+	if err != nil {
+		if errors.Is(err, fuzz.ErrNotFound) {
+			return nil, ErrAttackNotFound
+		}
+		fu.logger.Error("failed to get attack", zap.Error(err))
+		return nil, fmt.Errorf("failed to get attack from db")
+	}
+	return attack, nil
+}
+
+// ActiveSessionID returns session which listings are scoped to by default.
+func (fu *FuzzUsecase) ActiveSessionID() string {
+	return fu.requests.ActiveSessionID()
+}
+
+// GetAttacks returns attacks of the session, of every session when it is
+// empty.
+func (fu *FuzzUsecase) GetAttacks(sessionID string) ([]*models.FuzzAttack, error) {
+	attacks, err := fu.repo.GetAttacks(sessionID)
+	if err != nil {
+		fu.logger.Error("failed to get attacks", zap.Error(err))
+		return nil, fmt.Errorf("failed to get attacks from db")
+	}
+	return attacks, nil
+}
+
+// GetResults returns stored results of the attack, results of running
+// attack appear in batches.
+func (fu *FuzzUsecase) GetResults(id string, opts *models.FuzzResultOptions) ([]*models.FuzzResult, error) {
+	if _, err := fu.GetAttack(id); err != nil {
+		return nil, err
+	}
+
+	results, err := fu.repo.GetResults(id, opts)
+	if err != nil {
+		fu.logger.Error("failed to get fuzz results", zap.Error(err))
+		return nil, fmt.Errorf("failed to get fuzz results from db")
+	}
+	return results, nil
+}
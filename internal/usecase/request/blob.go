@@ -0,0 +1,51 @@
+package request
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+// offloadBody moves body to the blob store if it is bigger than threshold.
+// Identical bodies get the same ref, so they are stored only once.
+func (ru *RequestUsecase) offloadBody(body *models.Body, meta *models.BodyMeta) error {
+	if ru.blobs == nil || ru.blobThreshold <= 0 || int64(len(*body)) <= ru.blobThreshold {
+		return nil
+	}
+
+	hash, err := ru.blobs.Put(*body)
+	if err != nil {
+		return err
+	}
+	meta.Ref = hash
+	*body = nil
+	return nil
+}
+
+func (ru *RequestUsecase) loadBody(body *models.Body, meta *models.BodyMeta) error {
+	if meta.Ref == "" {
+		return nil
+	}
+
+	r, _, err := ru.OpenBody(meta.Ref)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("can't read body %s: %v", meta.Ref, err)
+	}
+	*body = data
+	return nil
+}
+
+// OpenBody streams offloaded body by its ref.
+func (ru *RequestUsecase) OpenBody(ref string) (io.ReadCloser, int64, error) {
+	if ru.blobs == nil {
+		return nil, 0, fmt.Errorf("blob store is not configured")
+	}
+	return ru.blobs.Open(ref)
+}
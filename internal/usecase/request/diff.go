@@ -0,0 +1,19 @@
+package request
+
+import (
+	"github.com/MatiXxD/go-mitm-proxy/pkg/diff"
+)
+
+// DiffRequests compares two stored exchanges, offloaded bodies are loaded
+// to be compared too.
+func (ru *RequestUsecase) DiffRequests(a, b string, opts *diff.Options) (*diff.Result, error) {
+	reqA, err := ru.GetFullRequestById(a)
+	if err != nil {
+		return nil, err
+	}
+	reqB, err := ru.GetFullRequestById(b)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Compare(reqA, reqB, opts), nil
+}
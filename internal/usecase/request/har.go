@@ -0,0 +1,83 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/har"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidHAR = errors.New("invalid har")
+
+// ExportHAR returns requests matching filter as HAR log, offloaded bodies are
+// loaded back so the log is self-contained.
+func (ru *RequestUsecase) ExportHAR(filter *models.RequestFilter) (*har.HAR, error) {
+	entries := make([]*har.Entry, 0)
+	err := ru.repo.WalkRequests(filter, func(req *models.RequestInfoWithID) error {
+		if err := ru.loadBody(&req.Request.Body, &req.Request.BodyMeta); err != nil {
+			return err
+		}
+		if req.Response != nil {
+			if err := ru.loadBody(&req.Response.Body, &req.Response.BodyMeta); err != nil {
+				return err
+			}
+		}
+
+		entries = append(entries, har.NewEntry(&models.RequestInfo{
+			Request:   req.Request,
+			Response:  req.Response,
+			Timings:   req.Timings,
+			SessionID: req.SessionID,
+			ParentID:  req.ParentID,
+			Size:      req.Size,
+			CreatedAt: req.CreatedAt,
+		}))
+		return nil
+	})
+	if err != nil {
+		ru.logger.Error("failed to export requests", zap.Error(err))
+		return nil, fmt.Errorf("failed to get requests from db")
+	}
+
+	return har.NewHAR(entries), nil
+}
+
+// ImportHAR stores HAR entries into a new session, so imported traffic is not
+// mixed with captured one. The session is not activated.
+func (ru *RequestUsecase) ImportHAR(h *har.HAR, name string) (*models.Session, int, error) {
+	if h.Log == nil {
+		return nil, 0, fmt.Errorf("%w: no log", ErrInvalidHAR)
+	}
+
+	reqInfos := make([]*models.RequestInfo, 0, len(h.Log.Entries))
+	for i, entry := range h.Log.Entries {
+		if entry == nil {
+			continue
+		}
+		reqInfo, err := entry.ToRequestInfo()
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: entry %d: %v", ErrInvalidHAR, i, err)
+		}
+		reqInfos = append(reqInfos, reqInfo)
+	}
+
+	if name == "" {
+		name = "import " + time.Now().Format(time.DateTime)
+	}
+	sess, err := ru.sessions.CreateSession(name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i, reqInfo := range reqInfos {
+		reqInfo.SessionID = sess.ID.Hex()
+		if _, err := ru.addRequestInfo(reqInfo); err != nil {
+			return sess, i, fmt.Errorf("can't add request to db")
+		}
+	}
+
+	return sess, len(reqInfos), nil
+}
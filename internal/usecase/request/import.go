@@ -0,0 +1,25 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"go.uber.org/zap"
+)
+
+// ImportRequest stores request which was never proxied, e.g. parsed from
+// curl command, without response. It goes to the active session.
+func (ru *RequestUsecase) ImportRequest(req *http.Request) (string, error) {
+	parsedReq, err := models.NewParsedRequest(req)
+	if err != nil {
+		ru.logger.Error("failed to parse request", zap.Error(err))
+		return "", fmt.Errorf("can't parse request: %v", err)
+	}
+
+	id, err := ru.addRequestInfo(models.NewRequestInfo(parsedReq, nil))
+	if err != nil {
+		return "", fmt.Errorf("can't add request to db")
+	}
+	return id, nil
+}
@@ -0,0 +1,122 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/internal/repository/request"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxHistorySize limits history tree, it also stops walking parent
+	// links if they ever form a cycle
+	maxHistorySize     = 1000
+	maxHistoryChildren = 500
+)
+
+// AddRepeatedRequest stores request repeated from parentID together with its
+// response and returns the new entry. It goes to the session of the parent,
+// which may be not the active one by the time the response comes.
+func (ru *RequestUsecase) AddRepeatedRequest(parentID string, req *http.Request, resp *http.Response, timings *models.Timings) (string, *models.RequestInfo, error) {
+	reqInfo, err := ru.newRequestInfo(req, resp, timings)
+	if err != nil {
+		return "", nil, err
+	}
+	reqInfo.ParentID = parentID
+
+	parent, err := ru.repo.GetRequestById(parentID)
+	if err == nil {
+		reqInfo.SessionID = parent.SessionID
+	} else if !errors.Is(err, request.ErrNotFound) {
+		ru.logger.Error("can't get repeated request", zap.Error(err))
+		return "", nil, fmt.Errorf("can't get request from db")
+	}
+
+	// bodies are kept in the returned entry even if they are offloaded
+	reqBody := reqInfo.Request.Body
+	respBody := reqInfo.Response.Body
+	id, err := ru.addRequestInfo(reqInfo)
+	if err != nil {
+		return "", nil, fmt.Errorf("can't add request to db")
+	}
+	reqInfo.Request.Body = reqBody
+	reqInfo.Response.Body = respBody
+	return id, reqInfo, nil
+}
+
+// GetHistory returns tree of repeats the request belongs to, starting from
+// the request it was originally repeated from. Requests whose parent was
+// deleted become the root.
+func (ru *RequestUsecase) GetHistory(id string) (*models.RequestNode, error) {
+	root, err := ru.historyRoot(id)
+	if err != nil {
+		return nil, err
+	}
+
+	size := 1
+	queue := []*models.RequestNode{root}
+	for len(queue) > 0 && size < maxHistorySize {
+		node := queue[0]
+		queue = queue[1:]
+
+		children, err := ru.repo.ListRequests(&models.ListOptions{
+			Filter: models.RequestFilter{ParentID: node.ID.Hex()},
+			SortBy: models.SortByTime,
+			Limit:  min(maxHistoryChildren, maxHistorySize-size),
+		})
+		if err != nil {
+			ru.logger.Error("failed to get request history", zap.Error(err))
+			return nil, fmt.Errorf("failed to get requests from db")
+		}
+
+		for _, child := range children {
+			childNode := &models.RequestNode{RequestSummary: child, Children: make([]*models.RequestNode, 0)}
+			node.Children = append(node.Children, childNode)
+			queue = append(queue, childNode)
+		}
+		size += len(children)
+	}
+
+	return root, nil
+}
+
+func (ru *RequestUsecase) historyRoot(id string) (*models.RequestNode, error) {
+	var root *models.RequestNode
+	for i := 0; i < maxHistorySize && id != ""; i++ {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			break
+		}
+
+		req, err := ru.repo.GetRequestById(id)
+		if errors.Is(err, request.ErrNotFound) {
+			break
+		} else if err != nil {
+			ru.logger.Error("failed to get request history", zap.Error(err))
+			return nil, fmt.Errorf("failed to get request from db")
+		}
+
+		root = &models.RequestNode{
+			RequestSummary: models.NewRequestSummary(&models.RequestInfoWithID{
+				ID:        objID,
+				Request:   req.Request,
+				Response:  req.Response,
+				SessionID: req.SessionID,
+				ParentID:  req.ParentID,
+				Size:      req.Size,
+				CreatedAt: req.CreatedAt,
+			}),
+			Children: make([]*models.RequestNode, 0),
+		}
+		id = req.ParentID
+	}
+
+	if root == nil {
+		return nil, ErrRequestNotFound
+	}
+	return root, nil
+}
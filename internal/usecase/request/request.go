@@ -1,60 +1,182 @@
 package request
 
 import (
+	"errors"
 	"fmt"
 	"github.com/MatiXxD/go-mitm-proxy/internal/models"
 	"github.com/MatiXxD/go-mitm-proxy/internal/repository/request"
+	"github.com/MatiXxD/go-mitm-proxy/internal/usecase/session"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/blobstore"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/env"
 	"go.uber.org/zap"
 	"net/http"
 )
 
 type RequestUsecase struct {
-	repo   *request.RequestRepository
-	logger *zap.Logger
+	repo          request.RequestRepository
+	sessions      *session.SessionUsecase
+	blobs         blobstore.Store
+	blobThreshold int64
+	retention     env.RetentionConfig
+	listeners     []func(*models.Capture)
+	logger        *zap.Logger
 }
 
-func NewRequestUsecase(repo *request.RequestRepository, logger *zap.Logger) *RequestUsecase {
+func NewRequestUsecase(repo request.RequestRepository, sessions *session.SessionUsecase, blobs blobstore.Store, cfg *env.Config, logger *zap.Logger) *RequestUsecase {
 	return &RequestUsecase{
-		repo:   repo,
-		logger: logger,
+		repo:          repo,
+		sessions:      sessions,
+		blobs:         blobs,
+		blobThreshold: cfg.BlobConfig.Threshold,
+		retention:     cfg.Retention,
+		logger:        logger,
 	}
 }
 
-func (ru *RequestUsecase) AddRequest(req *http.Request, resp *http.Response) error {
+// OnCapture registers fn to be called with every exchange captured by the
+// proxy after it is stored. Listeners are called from proxy connections,
+// so they must be fast. Must be called before the proxy is started.
+func (ru *RequestUsecase) OnCapture(fn func(*models.Capture)) {
+	ru.listeners = append(ru.listeners, fn)
+}
+
+func (ru *RequestUsecase) AddRequest(req *http.Request, resp *http.Response, timings *models.Timings) error {
+	reqInfo, err := ru.newRequestInfo(req, resp, timings)
+	if err != nil {
+		return err
+	}
+	// offloading drops body from the document
+	var body []byte
+	if reqInfo.Response != nil {
+		body = reqInfo.Response.Body
+	}
+
+	id, err := ru.addRequestInfo(reqInfo)
+	if err != nil {
+		return fmt.Errorf("can't add request to db")
+	}
+
+	capture := &models.Capture{ID: id, SessionID: reqInfo.SessionID, Request: reqInfo.Request, Response: reqInfo.Response, Body: body}
+	for _, fn := range ru.listeners {
+		fn(capture)
+	}
+	return nil
+}
+
+func (ru *RequestUsecase) newRequestInfo(req *http.Request, resp *http.Response, timings *models.Timings) (*models.RequestInfo, error) {
 	parsedReq, err := models.NewParsedRequest(req)
 	if err != nil {
 		ru.logger.Error("failed to parse request", zap.Error(err))
-		return fmt.Errorf("can't add request to db")
+		return nil, fmt.Errorf("can't add request to db")
 	}
 
 	parsedResp, err := models.NewParsedResponse(resp)
 	if err != nil {
 		ru.logger.Error("failed to parse response", zap.Error(err))
-		return fmt.Errorf("can't add request to db")
+		return nil, fmt.Errorf("can't add request to db")
 	}
 
-	if err := ru.repo.AddRequest(parsedReq, parsedResp); err != nil {
+	reqInfo := models.NewRequestInfo(parsedReq, parsedResp)
+	reqInfo.Timings = timings
+	return reqInfo, nil
+}
+
+// addRequestInfo stores request in the active session, unless it already
+// has one, and returns its ID. Big bodies are offloaded before storing.
+func (ru *RequestUsecase) addRequestInfo(reqInfo *models.RequestInfo) (string, error) {
+	if reqInfo.SessionID == "" {
+		reqInfo.SessionID = ru.sessions.ActiveSessionID()
+	}
+
+	if err := ru.offloadBody(&reqInfo.Request.Body, &reqInfo.Request.BodyMeta); err != nil {
+		ru.logger.Error("failed to offload request body", zap.Error(err))
+		return "", err
+	}
+	if reqInfo.Response != nil {
+		if err := ru.offloadBody(&reqInfo.Response.Body, &reqInfo.Response.BodyMeta); err != nil {
+			ru.logger.Error("failed to offload response body", zap.Error(err))
+			return "", err
+		}
+	}
+
+	id, err := ru.repo.AddRequest(reqInfo)
+	if err != nil {
 		ru.logger.Error("can't add request", zap.Error(err))
-		return fmt.Errorf("can't add request to db")
+		return "", err
 	}
+	return id, nil
+}
+
+// ActiveSessionID returns session which listings are scoped to by default.
+func (ru *RequestUsecase) ActiveSessionID() string {
+	return ru.sessions.ActiveSessionID()
+}
 
+// MigrateSessions puts requests captured before sessions existed into the
+// default session, otherwise session scoped listings never show them.
+func (ru *RequestUsecase) MigrateSessions() error {
+	assigned, err := ru.repo.AssignSession(ru.sessions.DefaultSessionID())
+	if err != nil {
+		return fmt.Errorf("can't assign requests to default session: %v", err)
+	}
+	if assigned > 0 {
+		ru.logger.Info("requests assigned to default session", zap.Int64("count", assigned))
+	}
 	return nil
 }
 
-func (ru *RequestUsecase) GetRequestsInfo() ([]*models.RequestInfoWithID, error) {
-	reqs, err := ru.repo.GetRequests()
+func (ru *RequestUsecase) ListRequests(opts *models.ListOptions) (*models.RequestPage, error) {
+	// one extra item tells if there is a next page
+	limit := opts.Limit
+	opts.Limit++
+	reqs, err := ru.repo.ListRequests(opts)
+	opts.Limit = limit
 	if err != nil {
-		ru.logger.Error("failed to get requests", zap.Error(err))
+		ru.logger.Error("failed to list requests", zap.Error(err))
 		return nil, fmt.Errorf("failed to get requests from db")
 	}
-	return reqs, nil
+
+	page := &models.RequestPage{Items: reqs}
+	if len(reqs) > limit {
+		page.Items = reqs[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = models.EncodeCursor(&models.Cursor{
+			Value: last.SortValue(opts.SortBy),
+			ID:    last.ID.Hex(),
+		})
+	}
+	return page, nil
 }
 
 func (ru *RequestUsecase) GetRequestById(id string) (*models.RequestInfo, error) {
 	req, err := ru.repo.GetRequestById(id)
-	if err != nil {
+	if errors.Is(err, request.ErrNotFound) {
+		return nil, ErrRequestNotFound
+	} else if err != nil {
 		ru.logger.Error("failed to get request", zap.Error(err))
 		return nil, fmt.Errorf("failed to get request from db")
 	}
 	return req, nil
 }
+
+// GetFullRequestById returns request with offloaded bodies loaded back,
+// it is used when request has to be sent again.
+func (ru *RequestUsecase) GetFullRequestById(id string) (*models.RequestInfo, error) {
+	req, err := ru.GetRequestById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ru.loadBody(&req.Request.Body, &req.Request.BodyMeta); err != nil {
+		ru.logger.Error("failed to load request body", zap.Error(err))
+		return nil, fmt.Errorf("failed to load request body")
+	}
+	if req.Response != nil {
+		if err := ru.loadBody(&req.Response.Body, &req.Response.BodyMeta); err != nil {
+			ru.logger.Error("failed to load response body", zap.Error(err))
+			return nil, fmt.Errorf("failed to load response body")
+		}
+	}
+
+	return req, nil
+}
@@ -0,0 +1,125 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/internal/repository/request"
+	"go.uber.org/zap"
+)
+
+// blobs younger than this may belong to a request which is not inserted yet
+const blobGracePeriod = 10 * time.Minute
+
+var ErrRequestNotFound = errors.New("request not found")
+
+// ErrNoSession means clearing without session, which would delete traffic
+// of every session.
+var ErrNoSession = errors.New("session to clear is not set")
+
+func (ru *RequestUsecase) DeleteRequestById(id string) error {
+	if err := ru.repo.DeleteRequestById(id); err != nil {
+		if errors.Is(err, request.ErrNotFound) {
+			return ErrRequestNotFound
+		}
+		ru.logger.Error("failed to delete request", zap.Error(err))
+		return fmt.Errorf("failed to delete request from db")
+	}
+	return nil
+}
+
+func (ru *RequestUsecase) DeleteRequests(filter *models.RequestFilter) (int64, error) {
+	deleted, err := ru.repo.DeleteRequests(filter)
+	if err != nil {
+		ru.logger.Error("failed to delete requests", zap.Error(err))
+		return deleted, fmt.Errorf("failed to delete requests from db")
+	}
+	return deleted, nil
+}
+
+// ClearRequests deletes all captured traffic of the session.
+func (ru *RequestUsecase) ClearRequests(sessionID string) (int64, error) {
+	if sessionID == "" {
+		return 0, ErrNoSession
+	}
+	return ru.DeleteRequests(&models.RequestFilter{SessionID: sessionID})
+}
+
+// RunRetention periodically deletes requests which are out of configured
+// limits and blobs which are not referenced anymore. It never returns.
+func (ru *RequestUsecase) RunRetention() {
+	if ru.retention.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(ru.retention.Interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		deleted, err := ru.enforceRetention()
+		if err != nil {
+			ru.logger.Error("failed to enforce retention", zap.Error(err))
+		} else if deleted > 0 {
+			ru.logger.Info("retention deleted requests", zap.Int64("count", deleted))
+		}
+
+		removed, err := ru.collectBlobs()
+		if err != nil {
+			ru.logger.Error("failed to collect blobs", zap.Error(err))
+		} else if removed > 0 {
+			ru.logger.Info("retention deleted blobs", zap.Int("count", removed))
+		}
+	}
+}
+
+func (ru *RequestUsecase) enforceRetention() (int64, error) {
+	var deleted int64
+	if ru.retention.MaxAge > 0 {
+		n, err := ru.repo.DeleteRequests(&models.RequestFilter{
+			To: time.Now().Add(-ru.retention.MaxAge),
+		})
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+
+	n, err := ru.repo.TrimRequests(ru.retention.MaxCount, ru.retention.MaxSize)
+	return deleted + n, err
+}
+
+// collectBlobs deletes offloaded bodies which no request refers to. Blobs
+// put or reused after the sweep started are kept, refs taken at the start
+// may miss them.
+func (ru *RequestUsecase) collectBlobs() (int, error) {
+	if ru.blobs == nil {
+		return 0, nil
+	}
+
+	start := time.Now()
+	refs, err := ru.repo.BodyRefs()
+	if err != nil {
+		return 0, err
+	}
+
+	var unused []string
+	deadline := start.Add(-blobGracePeriod)
+	err = ru.blobs.Walk(func(hash string, createdAt time.Time) error {
+		if _, ok := refs[hash]; !ok && createdAt.Before(deadline) {
+			unused = append(unused, hash)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i, hash := range unused {
+		if err := ru.blobs.Delete(hash); err != nil {
+			return i, err
+		}
+	}
+	return len(unused), nil
+}
@@ -0,0 +1,403 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/internal/repository/scan"
+	"github.com/MatiXxD/go-mitm-proxy/internal/usecase/request"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/oob"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const (
+	// progressInterval limits how often progress of running job is stored,
+	// GetJob returns live progress anyway
+	progressInterval = time.Second
+
+	errInterrupted = "scan was interrupted by restart"
+)
+
+var (
+	ErrJobNotFound     = errors.New("scan job not found")
+	ErrJobFinished     = errors.New("scan job is already finished")
+	ErrReportNotFound  = errors.New("scan report not found")
+	ErrRequestNotFound = errors.New("request not found")
+	ErrInvalidPolicy   = errors.New("invalid scan policy")
+)
+
+type ScanUsecase struct {
+	repo     scan.ScanRepository
+	requests *request.RequestUsecase
+	scanner  *scanner.Scanner
+	oob      *oob.Server
+	running  map[string]*runningJob
+	mu       sync.Mutex
+	logger   *zap.Logger
+}
+
+type runningJob struct {
+	job    *models.ScanJob
+	cancel context.CancelFunc
+	// findings are out-of-band ones which came before the report was stored
+	findings []*models.Finding
+}
+
+// NewScanUsecase marks jobs left running by the previous process as failed,
+// they can't be resumed. oob is nil when the out-of-band server is off.
+func NewScanUsecase(repo scan.ScanRepository, requests *request.RequestUsecase, scanner *scanner.Scanner, oob *oob.Server, logger *zap.Logger) (*ScanUsecase, error) {
+	su := &ScanUsecase{
+		repo:     repo,
+		requests: requests,
+		scanner:  scanner,
+		oob:      oob,
+		running:  make(map[string]*runningJob),
+		logger:   logger,
+	}
+	if oob != nil {
+		oob.OnInteraction(su.interaction)
+	}
+
+	jobs, err := repo.GetJobs("")
+	if err != nil {
+		return nil, fmt.Errorf("can't load scan jobs: %v", err)
+	}
+	for _, job := range jobs {
+		if job.Status == models.ScanRunning {
+			now := time.Now()
+			job.Status = models.ScanFailed
+			job.Error = errInterrupted
+			job.FinishedAt = &now
+			if err := repo.UpdateJob(job); err != nil {
+				return nil, fmt.Errorf("can't update scan job: %v", err)
+			}
+		}
+	}
+
+	return su, nil
+}
+
+// StartScan starts scan of the stored request in background and returns
+// the job. Nil policy runs every check against every insertion point.
+func (su *ScanUsecase) StartScan(requestID string, policy *models.ScanPolicy) (*models.ScanJob, error) {
+	if policy != nil {
+		if err := su.scanner.Registry().Validate(policy); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+		}
+	}
+
+	reqInfo, err := su.requests.GetFullRequestById(requestID)
+	if err != nil {
+		return nil, ErrRequestNotFound
+	}
+
+	job := models.NewScanJob(requestID, reqInfo.SessionID, reqInfo.Request.Host, policy)
+	if err := su.repo.AddJob(job); err != nil {
+		su.logger.Error("failed to add scan job", zap.Error(err))
+		return nil, fmt.Errorf("failed to add scan job to db")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	su.mu.Lock()
+	su.running[job.ID.Hex()] = &runningJob{job: job, cancel: cancel}
+	started := *job
+	su.mu.Unlock()
+
+	go su.run(ctx, job, reqInfo.Request)
+	return &started, nil
+}
+
+func (su *ScanUsecase) run(ctx context.Context, job *models.ScanJob, parsedReq *models.ParsedRequest) {
+	lastUpdate := time.Now()
+	result, err := su.scanner.Scan(ctx, job.ID.Hex(), parsedReq, job.Policy, func(done, total int) {
+		su.mu.Lock()
+		job.Done, job.Total = done, total
+		update := time.Since(lastUpdate) >= progressInterval
+		snapshot := *job
+		su.mu.Unlock()
+
+		if update {
+			lastUpdate = time.Now()
+			if err := su.repo.UpdateJob(&snapshot); err != nil {
+				su.logger.Error("failed to update scan progress", zap.Error(err))
+			}
+		}
+	})
+
+	if errors.Is(err, scanner.ErrBaseline) {
+		su.finish(job, models.ScanFailed, err.Error())
+		return
+	}
+
+	status := models.ScanDone
+	if errors.Is(err, context.Canceled) {
+		status = models.ScanCancelled
+	}
+
+	su.mu.Lock()
+	result.Findings = mergeFindings(result.Findings, su.running[job.ID.Hex()].findings...)
+	stored := &models.ScanReport{
+		ID:        primitive.NewObjectID(),
+		JobID:     job.ID.Hex(),
+		RequestID: job.RequestID,
+		SessionID: job.SessionID,
+		Host:      job.Host,
+		Method:    parsedReq.Method,
+		URL:       parsedReq.URL,
+		Policy:    job.Policy,
+		Complete:  status == models.ScanDone,
+		Result:    result,
+		CreatedAt: time.Now(),
+	}
+	err = su.repo.AddReport(stored)
+	if err == nil {
+		job.ReportID = stored.ID.Hex()
+	}
+	su.mu.Unlock()
+
+	if err != nil {
+		su.logger.Error("failed to add scan report", zap.Error(err))
+		su.finish(job, models.ScanFailed, "failed to store report")
+		return
+	}
+	su.finish(job, status, "")
+}
+
+// finish stores the final state of the job and forgets it.
+func (su *ScanUsecase) finish(job *models.ScanJob, status, errMsg string) {
+	su.mu.Lock()
+	defer su.mu.Unlock()
+	now := time.Now()
+	job.Status = status
+	job.Error = errMsg
+	job.FinishedAt = &now
+	if err := su.repo.UpdateJob(job); err != nil {
+		su.logger.Error("failed to finish scan job", zap.Error(err))
+	}
+
+	su.running[job.ID.Hex()].cancel()
+	delete(su.running, job.ID.Hex())
+}
+
+// CancelScan stops running job, report of what was scanned so far is
+// stored once in-flight requests are aborted.
+func (su *ScanUsecase) CancelScan(id string) error {
+	su.mu.Lock()
+	rj, ok := su.running[id]
+	su.mu.Unlock()
+	if ok {
+		rj.cancel()
+		return nil
+	}
+
+	if _, err := su.GetJob(id); err != nil {
+		return err
+	}
+	return ErrJobFinished
+}
+
+// GetJob returns live progress for running jobs.
+func (su *ScanUsecase) GetJob(id string) (*models.ScanJob, error) {
+	su.mu.Lock()
+	if rj, ok := su.running[id]; ok {
+		job := *rj.job
+		su.mu.Unlock()
+		return &job, nil
+	}
+	su.mu.Unlock()
+
+	job, err := su.repo.GetJobById(id)
+	if err != nil {
+		if errors.Is(err, scan.ErrNotFound) {
+			return nil, ErrJobNotFound
+		}
+		su.logger.Error("failed to get scan job", zap.Error(err))
+		return nil, fmt.Errorf("failed to get scan job from db")
+	}
+	return job, nil
+}
+
+// ActiveSessionID returns session which listings are scoped to by default.
+func (su *ScanUsecase) ActiveSessionID() string {
+	return su.requests.ActiveSessionID()
+}
+
+// GetJobs returns jobs of the session, of every session when it is empty.
+func (su *ScanUsecase) GetJobs(sessionID string) ([]*models.ScanJob, error) {
+	jobs, err := su.repo.GetJobs(sessionID)
+	if err != nil {
+		su.logger.Error("failed to get scan jobs", zap.Error(err))
+		return nil, fmt.Errorf("failed to get scan jobs from db")
+	}
+
+	su.mu.Lock()
+	defer su.mu.Unlock()
+	for i, job := range jobs {
+		if rj, ok := su.running[job.ID.Hex()]; ok {
+			live := *rj.job
+			jobs[i] = &live
+		}
+	}
+	return jobs, nil
+}
+
+func (su *ScanUsecase) GetReport(id string) (*models.ScanReport, error) {
+	report, err := su.repo.GetReportById(id)
+	if err != nil {
+		if errors.Is(err, scan.ErrNotFound) {
+			return nil, ErrReportNotFound
+		}
+		su.logger.Error("failed to get scan report", zap.Error(err))
+		return nil, fmt.Errorf("failed to get scan report from db")
+	}
+	return report, nil
+}
+
+// GetJobReport returns report of finished job, ErrReportNotFound means the
+// job is still running or failed before the report was stored.
+func (su *ScanUsecase) GetJobReport(id string) (*models.ScanReport, error) {
+	job, err := su.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.ReportID == "" {
+		return nil, ErrReportNotFound
+	}
+	return su.GetReport(job.ReportID)
+}
+
+func (su *ScanUsecase) ListReports(filter *models.ReportFilter) ([]*models.ScanReport, error) {
+	reports, err := su.repo.ListReports(filter)
+	if err != nil {
+		su.logger.Error("failed to list scan reports", zap.Error(err))
+		return nil, fmt.Errorf("failed to get scan reports from db")
+	}
+	return reports, nil
+}
+
+// Observe passes the captured exchange to checks looking at the traffic,
+// e.g. for payloads stored by earlier scans. Findings are stored as a report
+// of the captured request.
+func (su *ScanUsecase) Observe(capture *models.Capture) {
+	if capture.Response == nil {
+		return
+	}
+
+	findings := su.scanner.Observe(capture.Request, &scanner.Response{
+		URL:        capture.Request.URL,
+		StatusCode: capture.Response.StatusCode,
+		Header:     capture.Response.Header,
+		Body:       capture.Body,
+	})
+	if len(findings) == 0 {
+		return
+	}
+
+	report := &models.ScanReport{
+		ID:        primitive.NewObjectID(),
+		RequestID: capture.ID,
+		SessionID: capture.SessionID,
+		Host:      capture.Request.Host,
+		Method:    capture.Request.Method,
+		URL:       capture.Request.URL,
+		Complete:  true,
+		Result: &models.ScanResult{
+			Findings: findings,
+			Errors:   make([]*models.ProbeError, 0),
+		},
+		CreatedAt: time.Now(),
+	}
+	if err := su.repo.AddReport(report); err != nil {
+		su.logger.Error("failed to add scan report", zap.Error(err))
+	}
+}
+
+// interaction adds the finding of out-of-band payload to the report of its
+// scan. Findings which come while the scan runs are added when the report
+// is stored.
+func (su *ScanUsecase) interaction(i *models.Interaction) {
+	if i.Finding == nil || i.ScanID == "" {
+		return
+	}
+	finding := *i.Finding
+	line, _, _ := strings.Cut(i.Data, "\n")
+	finding.Evidence = fmt.Sprintf("%s interaction from %s: %s", i.Protocol, i.RemoteAddr, strings.TrimSpace(line))
+	finding.Detail = "out-of-band, " + i.Protocol
+
+	su.mu.Lock()
+	defer su.mu.Unlock()
+
+	var reportID string
+	if rj, ok := su.running[i.ScanID]; ok {
+		if rj.job.ReportID == "" {
+			rj.findings = mergeFindings(rj.findings, &finding)
+			return
+		}
+		reportID = rj.job.ReportID
+	} else {
+		job, err := su.repo.GetJobById(i.ScanID)
+		if err != nil {
+			su.logger.Error("failed to get scan job of interaction", zap.Error(err))
+			return
+		}
+		reportID = job.ReportID
+	}
+	if reportID == "" {
+		return
+	}
+
+	report, err := su.repo.GetReportById(reportID)
+	if err != nil {
+		su.logger.Error("failed to get scan report of interaction", zap.Error(err))
+		return
+	}
+	findings := mergeFindings(report.Result.Findings, &finding)
+	if len(findings) == len(report.Result.Findings) {
+		return
+	}
+	report.Result.Findings = findings
+	if err := su.repo.UpdateReport(report); err != nil {
+		su.logger.Error("failed to update scan report", zap.Error(err))
+	}
+}
+
+// mergeFindings adds findings of points which have none of the same check
+// yet.
+func mergeFindings(findings []*models.Finding, more ...*models.Finding) []*models.Finding {
+	for _, f := range more {
+		dup := slices.ContainsFunc(findings, func(g *models.Finding) bool {
+			return g.Check == f.Check && g.Location == f.Location && g.Param == f.Param
+		})
+		if !dup {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// GetInteractions returns recent out-of-band interactions, only ones of the
+// scan if scanID is set.
+func (su *ScanUsecase) GetInteractions(scanID string) []*models.Interaction {
+	if su.oob == nil {
+		return make([]*models.Interaction, 0)
+	}
+	return su.oob.Interactions(scanID)
+}
+
+// Checks returns checks which can be enabled in scan policy.
+func (su *ScanUsecase) Checks() []*models.ScanCheck {
+	checks := su.scanner.Registry().Checks()
+	res := make([]*models.ScanCheck, len(checks))
+	for i, c := range checks {
+		res[i] = &models.ScanCheck{ID: c.ID(), Name: c.Name()}
+	}
+	return res
+}
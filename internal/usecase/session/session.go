@@ -0,0 +1,180 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/internal/repository/session"
+	"go.uber.org/zap"
+)
+
+const defaultSessionName = "default"
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionArchived = errors.New("session is archived")
+	ErrSessionActive   = errors.New("active session can't be archived")
+)
+
+type SessionUsecase struct {
+	repo      session.SessionRepository
+	active    *models.Session
+	defaultID string
+	mu        sync.RWMutex
+	logger    *zap.Logger
+}
+
+// NewSessionUsecase loads active session, or creates the default one when
+// there is no active session yet.
+func NewSessionUsecase(repo session.SessionRepository, logger *zap.Logger) (*SessionUsecase, error) {
+	su := &SessionUsecase{
+		repo:   repo,
+		logger: logger,
+	}
+
+	sessions, err := repo.GetSessions()
+	if err != nil {
+		return nil, fmt.Errorf("can't load sessions: %v", err)
+	}
+	if len(sessions) > 0 {
+		su.defaultID = sessions[0].ID.Hex()
+	}
+	for _, s := range sessions {
+		if s.Active {
+			su.active = s
+			return su, nil
+		}
+	}
+
+	s := models.NewSession(defaultSessionName)
+	s.Active = true
+	if err := repo.AddSession(s); err != nil {
+		return nil, fmt.Errorf("can't create default session: %v", err)
+	}
+	su.active = s
+	if su.defaultID == "" {
+		su.defaultID = s.ID.Hex()
+	}
+
+	return su, nil
+}
+
+// DefaultSessionID returns the first session, created on the first start.
+// Traffic stored before sessions existed belongs to it.
+func (su *SessionUsecase) DefaultSessionID() string {
+	return su.defaultID
+}
+
+// ActiveSessionID returns ID of the session new traffic goes to.
+func (su *SessionUsecase) ActiveSessionID() string {
+	su.mu.RLock()
+	defer su.mu.RUnlock()
+	return su.active.ID.Hex()
+}
+
+func (su *SessionUsecase) GetActiveSession() *models.Session {
+	su.mu.RLock()
+	defer su.mu.RUnlock()
+	s := *su.active
+	return &s
+}
+
+func (su *SessionUsecase) CreateSession(name string) (*models.Session, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("session name is required")
+	}
+
+	s := models.NewSession(name)
+	if err := su.repo.AddSession(s); err != nil {
+		su.logger.Error("failed to create session", zap.Error(err))
+		return nil, fmt.Errorf("failed to add session to db")
+	}
+	return s, nil
+}
+
+func (su *SessionUsecase) GetSessions(withArchived bool) ([]*models.Session, error) {
+	sessions, err := su.repo.GetSessions()
+	if err != nil {
+		su.logger.Error("failed to get sessions", zap.Error(err))
+		return nil, fmt.Errorf("failed to get sessions from db")
+	}
+	if withArchived {
+		return sessions, nil
+	}
+
+	res := make([]*models.Session, 0, len(sessions))
+	for _, s := range sessions {
+		if !s.Archived {
+			res = append(res, s)
+		}
+	}
+	return res, nil
+}
+
+func (su *SessionUsecase) GetSessionById(id string) (*models.Session, error) {
+	s, err := su.repo.GetSessionById(id)
+	if err != nil {
+		if errors.Is(err, session.ErrNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		su.logger.Error("failed to get session", zap.Error(err))
+		return nil, fmt.Errorf("failed to get session from db")
+	}
+	return s, nil
+}
+
+func (su *SessionUsecase) ActivateSession(id string) (*models.Session, error) {
+	su.mu.Lock()
+	defer su.mu.Unlock()
+
+	s, err := su.GetSessionById(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.Archived {
+		return nil, ErrSessionArchived
+	}
+	if s.ID == su.active.ID {
+		return s, nil
+	}
+
+	prev := *su.active
+	prev.Active = false
+	if err := su.repo.UpdateSession(&prev); err != nil {
+		su.logger.Error("failed to deactivate session", zap.Error(err))
+		return nil, fmt.Errorf("failed to update session in db")
+	}
+
+	s.Active = true
+	if err := su.repo.UpdateSession(s); err != nil {
+		su.logger.Error("failed to activate session", zap.Error(err))
+		return nil, fmt.Errorf("failed to update session in db")
+	}
+	su.active = s
+
+	return s, nil
+}
+
+func (su *SessionUsecase) ArchiveSession(id string) (*models.Session, error) {
+	su.mu.Lock()
+	defer su.mu.Unlock()
+
+	s, err := su.GetSessionById(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.ID == su.active.ID {
+		return nil, ErrSessionActive
+	}
+
+	s.Archived = true
+	if err := su.repo.UpdateSession(s); err != nil {
+		su.logger.Error("failed to archive session", zap.Error(err))
+		return nil, fmt.Errorf("failed to update session in db")
+	}
+	return s, nil
+}
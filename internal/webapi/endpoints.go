@@ -1,10 +1,48 @@
 package webapi
 
-import "github.com/MatiXxD/go-mitm-proxy/internal/delivery/request"
+import (
+	"github.com/MatiXxD/go-mitm-proxy/internal/delivery/fuzz"
+	"github.com/MatiXxD/go-mitm-proxy/internal/delivery/request"
+	"github.com/MatiXxD/go-mitm-proxy/internal/delivery/scan"
+	"github.com/MatiXxD/go-mitm-proxy/internal/delivery/session"
+)
 
-func (s *Server) BindRoutes(rd *request.RequestDelivery) {
+func (s *Server) BindRoutes(rd *request.RequestDelivery, sd *session.SessionDelivery, fd *fuzz.FuzzDelivery, scd *scan.ScanDelivery) {
 	s.echo.GET("/requests", rd.GetRequestsInfo())
+	s.echo.DELETE("/requests", rd.DeleteRequests())
+	s.echo.POST("/requests/clear", rd.ClearRequests())
 	s.echo.GET("/requests/:id", rd.GetRequestById())
+	s.echo.DELETE("/requests/:id", rd.DeleteRequest())
+	s.echo.GET("/requests/:id/:part/body", rd.GetRequestBody())
+	s.echo.GET("/requests/:id/export/:format", rd.ExportRequest())
+	s.echo.GET("/requests/:id/history", rd.GetRequestHistory())
 	s.echo.GET("/repeat/:id", rd.RepeatRequest())
-	s.echo.GET("/scan/:id", rd.ScanRequest())
+	s.echo.POST("/repeat/:id", rd.RepeatEditedRequest())
+	s.echo.GET("/diff/:a/:b", rd.DiffRequests())
+	s.echo.GET("/export/har", rd.ExportHAR())
+	s.echo.POST("/import/har", rd.ImportHAR())
+	s.echo.POST("/import/curl", rd.ImportCurl())
+	s.echo.POST("/import/raw", rd.ImportRaw())
+
+	s.echo.GET("/sessions", sd.GetSessions())
+	s.echo.POST("/sessions", sd.CreateSession())
+	s.echo.GET("/sessions/active", sd.GetActiveSession())
+	s.echo.POST("/sessions/:id/activate", sd.ActivateSession())
+	s.echo.POST("/sessions/:id/archive", sd.ArchiveSession())
+
+	s.echo.GET("/fuzz", fd.GetAttacks())
+	s.echo.POST("/fuzz", fd.StartAttack())
+	s.echo.GET("/fuzz/:id", fd.GetAttack())
+	s.echo.GET("/fuzz/:id/results", fd.GetResults())
+	s.echo.POST("/fuzz/:id/cancel", fd.CancelAttack())
+
+	s.echo.GET("/scans", scd.GetJobs())
+	s.echo.POST("/scans", scd.StartScan())
+	s.echo.GET("/scans/checks", scd.GetChecks())
+	s.echo.GET("/scans/:id", scd.GetJob())
+	s.echo.POST("/scans/:id/cancel", scd.CancelScan())
+	s.echo.GET("/scans/:id/report", scd.GetJobReport())
+	s.echo.GET("/reports", scd.ListReports())
+	s.echo.GET("/reports/:id", scd.GetReport())
+	s.echo.GET("/interactions", scd.GetInteractions())
 }
@@ -0,0 +1,33 @@
+package blobstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps content-addressed blobs. Put of the same data twice stores it
+// only once and returns the same hash.
+type Store interface {
+	Put(data []byte) (string, error)
+	Open(hash string) (io.ReadCloser, int64, error)
+	Delete(hash string) error
+	Walk(fn func(hash string, createdAt time.Time) error) error
+}
+
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func validHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
@@ -0,0 +1,113 @@
+package blobstore
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type DirStore struct {
+	root string
+}
+
+func NewDirStore(root string) (*DirStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("can't create blob dir %s: %v", root, err)
+	}
+	return &DirStore{root: root}, nil
+}
+
+func (ds *DirStore) Put(data []byte) (string, error) {
+	hash := Hash(data)
+	path := ds.path(hash)
+	if _, err := os.Stat(path); err == nil {
+		// refresh time, so unused blob collection doesn't remove reused blob
+		now := time.Now()
+		if err := os.Chtimes(path, now, now); err != nil {
+			return "", fmt.Errorf("can't touch blob: %v", err)
+		}
+		return hash, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("can't create blob dir: %v", err)
+	}
+
+	// write to temp file first, so readers never see partial blobs
+	tmp, err := os.CreateTemp(filepath.Dir(path), hash+".tmp*")
+	if err != nil {
+		return "", fmt.Errorf("can't create blob file: %v", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("can't write blob: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("can't write blob: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("can't store blob: %v", err)
+	}
+
+	return hash, nil
+}
+
+func (ds *DirStore) Open(hash string) (io.ReadCloser, int64, error) {
+	if !validHash(hash) {
+		return nil, 0, ErrNotFound
+	}
+
+	f, err := os.Open(ds.path(hash))
+	if os.IsNotExist(err) {
+		return nil, 0, ErrNotFound
+	} else if err != nil {
+		return nil, 0, fmt.Errorf("can't open blob: %v", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("can't stat blob: %v", err)
+	}
+
+	return f, info.Size(), nil
+}
+
+func (ds *DirStore) Delete(hash string) error {
+	if !validHash(hash) {
+		return ErrNotFound
+	}
+	if err := os.Remove(ds.path(hash)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("can't delete blob: %v", err)
+	}
+	return nil
+}
+
+func (ds *DirStore) Walk(fn func(hash string, createdAt time.Time) error) error {
+	return filepath.WalkDir(ds.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		hash := filepath.Base(filepath.Dir(path)) + d.Name()
+		if !validHash(hash) {
+			// temp files of unfinished puts
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(hash, info.ModTime())
+	})
+}
+
+func (ds *DirStore) path(hash string) string {
+	return filepath.Join(ds.root, hash[:2], hash[2:])
+}
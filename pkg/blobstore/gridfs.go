@@ -0,0 +1,133 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	gridFSBucket = "bodies"
+	// orphanAge is the age of chunks without file after which they are
+	// taken for leftovers of a failed upload, younger ones may be still
+	// written by a concurrent upload.
+	orphanAge = time.Minute
+)
+
+type GridFSStore struct {
+	bucket *gridfs.Bucket
+}
+
+func NewGridFSStore(db *mongo.Database) (*GridFSStore, error) {
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName(gridFSBucket))
+	if err != nil {
+		return nil, fmt.Errorf("can't create gridfs bucket: %v", err)
+	}
+	return &GridFSStore{bucket: bucket}, nil
+}
+
+func (gs *GridFSStore) Put(data []byte) (string, error) {
+	hash := Hash(data)
+
+	// hash is used as file id, so existing file means same content. Upload
+	// date is refreshed, so unused blob collection doesn't remove reused blob.
+	res, err := gs.bucket.GetFilesCollection().UpdateOne(context.Background(),
+		bson.M{"_id": hash},
+		bson.M{"$set": bson.M{"uploadDate": time.Now()}},
+	)
+	if err != nil {
+		return "", fmt.Errorf("can't check blob: %v", err)
+	}
+	if res.MatchedCount > 0 {
+		return hash, nil
+	}
+
+	err = gs.bucket.UploadFromStreamWithID(hash, hash, bytes.NewReader(data))
+	if mongo.IsDuplicateKeyError(err) {
+		err = gs.reupload(hash, data)
+	}
+	if err != nil {
+		return "", fmt.Errorf("can't upload blob: %v", err)
+	}
+	return hash, nil
+}
+
+// reupload handles duplicate key on upload. The file is there when a
+// concurrent Put of the same content finished first. Otherwise chunks are
+// written before the file, so they are left by a failed upload and are
+// deleted before the upload is retried.
+func (gs *GridFSStore) reupload(hash string, data []byte) error {
+	n, err := gs.bucket.GetFilesCollection().CountDocuments(context.Background(), bson.M{"_id": hash})
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+
+	res, err := gs.bucket.GetChunksCollection().DeleteMany(context.Background(), bson.M{
+		"files_id": hash,
+		"_id":      bson.M{"$lt": primitive.NewObjectIDFromTimestamp(time.Now().Add(-orphanAge))},
+	})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("blob %s is being uploaded", hash)
+	}
+	return gs.bucket.UploadFromStreamWithID(hash, hash, bytes.NewReader(data))
+}
+
+func (gs *GridFSStore) Open(hash string) (io.ReadCloser, int64, error) {
+	if !validHash(hash) {
+		return nil, 0, ErrNotFound
+	}
+
+	stream, err := gs.bucket.OpenDownloadStream(hash)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return nil, 0, ErrNotFound
+	} else if err != nil {
+		return nil, 0, fmt.Errorf("can't open blob: %v", err)
+	}
+
+	return stream, stream.GetFile().Length, nil
+}
+
+func (gs *GridFSStore) Delete(hash string) error {
+	err := gs.bucket.Delete(hash)
+	if err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+		return fmt.Errorf("can't delete blob: %v", err)
+	}
+	return nil
+}
+
+func (gs *GridFSStore) Walk(fn func(hash string, createdAt time.Time) error) error {
+	cursor, err := gs.bucket.Find(bson.M{})
+	if err != nil {
+		return fmt.Errorf("can't list blobs: %v", err)
+	}
+	defer cursor.Close(context.Background())
+
+	for cursor.Next(context.Background()) {
+		file := struct {
+			ID         string    `bson:"_id"`
+			UploadDate time.Time `bson:"uploadDate"`
+		}{}
+		if err := cursor.Decode(&file); err != nil {
+			return fmt.Errorf("can't list blobs: %v", err)
+		}
+		if err := fn(file.ID, file.UploadDate); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,162 @@
+package bodyfmt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	FormatEmpty     = "empty"
+	FormatText      = "text"
+	FormatBase64    = "base64"
+	FormatJSON      = "json"
+	FormatXML       = "xml"
+	FormatForm      = "form"
+	FormatMultipart = "multipart"
+)
+
+// Format renders body for display. Known structured types are pretty-printed,
+// other text is returned as is and binary data is base64-encoded.
+// contentType may hold parameters, e.g. multipart boundary.
+func Format(body []byte, contentType string, binary bool) (string, string) {
+	if len(body) == 0 {
+		return "", FormatEmpty
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	switch {
+	case isJSON(mediaType):
+		if out, err := formatJSON(body); err == nil {
+			return out, FormatJSON
+		}
+	case isXML(mediaType):
+		if out, err := formatXML(body); err == nil {
+			return out, FormatXML
+		}
+	case mediaType == "application/x-www-form-urlencoded":
+		if out, err := formatForm(body); err == nil {
+			return out, FormatForm
+		}
+	case strings.HasPrefix(mediaType, "multipart/"):
+		if out, err := formatMultipart(body, params["boundary"]); err == nil {
+			return out, FormatMultipart
+		}
+	}
+
+	if binary {
+		return base64.StdEncoding.EncodeToString(body), FormatBase64
+	}
+	return string(body), FormatText
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func isXML(mediaType string) bool {
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+func formatJSON(body []byte) (string, error) {
+	var out bytes.Buffer
+	if err := json.Indent(&out, body, "", "  "); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func formatXML(body []byte) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	dec.Strict = false
+
+	var out bytes.Buffer
+	enc := xml.NewEncoder(&out)
+	enc.Indent("", "  ")
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		// whitespace between elements is replaced by indentation
+		if data, ok := token.(xml.CharData); ok && len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		if err := enc.EncodeToken(xml.CopyToken(token)); err != nil {
+			return "", err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func formatForm(body []byte) (string, error) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return "", err
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var out strings.Builder
+	for _, k := range keys {
+		for _, v := range values[k] {
+			fmt.Fprintf(&out, "%s: %s\n", k, v)
+		}
+	}
+	return out.String(), nil
+}
+
+func formatMultipart(body []byte, boundary string) (string, error) {
+	if boundary == "" {
+		return "", fmt.Errorf("no multipart boundary")
+	}
+
+	var out strings.Builder
+	r := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&out, "--- name=%q", part.FormName())
+		if part.FileName() != "" {
+			fmt.Fprintf(&out, " filename=%q", part.FileName())
+		}
+		ct := part.Header.Get("Content-Type")
+		if ct != "" {
+			fmt.Fprintf(&out, " content-type=%q", ct)
+		}
+		out.WriteString("\n")
+
+		content, _ := Format(data, ct, !utf8.Valid(data))
+		out.WriteString(content)
+		out.WriteString("\n")
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,19 @@
+package boltdb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/pkg/env"
+	"go.etcd.io/bbolt"
+)
+
+const openTimeout = 5 * time.Second
+
+func NewBoltDB(cfg *env.Config) (*bbolt.DB, error) {
+	db, err := bbolt.Open(cfg.StorageConfig.BoltPath, 0o600, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("bolt open error: %w", err)
+	}
+	return db, nil
+}
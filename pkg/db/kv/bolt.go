@@ -0,0 +1,129 @@
+package kv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+)
+
+type BoltStore struct {
+	db     *bbolt.DB
+	bucket []byte
+}
+
+func NewBoltStore(db *bbolt.DB, bucket string) (*BoltStore, error) {
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't create bucket %s: %v", bucket, err)
+	}
+
+	return &BoltStore{
+		db:     db,
+		bucket: []byte(bucket),
+	}, nil
+}
+
+func (bs *BoltStore) Put(key, value []byte) error {
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bs.bucket).Put(key, value)
+	})
+}
+
+func (bs *BoltStore) PutBatch(keys, values [][]byte) error {
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(bs.bucket)
+		for i := range keys {
+			if err := b.Put(keys[i], values[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (bs *BoltStore) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := bs.db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(bs.bucket).Get(key)
+		if v == nil {
+			return ErrNotFound
+		}
+		// bolt values are only valid inside transaction
+		value = bytes.Clone(v)
+		return nil
+	})
+	return value, err
+}
+
+func (bs *BoltStore) Delete(key []byte) error {
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bs.bucket).Delete(key)
+	})
+}
+
+func (bs *BoltStore) ForEach(fn func(key, value []byte) error) error {
+	return bs.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bs.bucket).ForEach(fn)
+	})
+}
+
+func (bs *BoltStore) ForEachPrefix(prefix []byte, fn func(key, value []byte) error) error {
+	return bs.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(bs.bucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if err := fn(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (bs *BoltStore) Seek(prefix, start []byte, desc bool, fn func(key, value []byte) error) error {
+	err := bs.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(bs.bucket).Cursor()
+		var k, v []byte
+		switch {
+		case !desc && start == nil:
+			k, v = c.Seek(prefix)
+		case !desc:
+			k, v = c.Seek(start)
+		default:
+			// step back from the first key after the range
+			from := start
+			if from == nil {
+				from = prefixEnd(prefix)
+			}
+			if from == nil {
+				k, v = c.Last()
+			} else if k, v = c.Seek(from); k == nil {
+				k, v = c.Last()
+			} else if start == nil || !bytes.Equal(k, start) {
+				k, v = c.Prev()
+			}
+		}
+
+		for ; k != nil && bytes.HasPrefix(k, prefix); k, v = step(c, desc) {
+			if err := fn(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, ErrStop) {
+		return nil
+	}
+	return err
+}
+
+func step(c *bbolt.Cursor, desc bool) ([]byte, []byte) {
+	if desc {
+		return c.Prev()
+	}
+	return c.Next()
+}
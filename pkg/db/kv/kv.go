@@ -0,0 +1,42 @@
+package kv
+
+import (
+	"bytes"
+	"errors"
+)
+
+var ErrNotFound = errors.New("key not found")
+
+// ErrStop returned from Seek callback ends iteration without error.
+var ErrStop = errors.New("stop iteration")
+
+// Store is an ordered key-value collection used by embedded repositories.
+// Values passed to ForEach are only valid inside the callback, and the
+// callback must not modify the store.
+type Store interface {
+	Put(key, value []byte) error
+	// PutBatch stores all pairs at once, keys and values have the same length.
+	PutBatch(keys, values [][]byte) error
+	Get(key []byte) ([]byte, error)
+	Delete(key []byte) error
+	ForEach(fn func(key, value []byte) error) error
+	// ForEachPrefix is like ForEach, but only visits keys with prefix.
+	ForEachPrefix(prefix []byte, fn func(key, value []byte) error) error
+	// Seek visits keys with prefix from start on, in descending order when
+	// desc is set. Nil start begins at the first key of the order, otherwise
+	// the key equal to start is visited too.
+	Seek(prefix, start []byte, desc bool, fn func(key, value []byte) error) error
+}
+
+// prefixEnd returns the first key after all keys with prefix, nil when
+// there is none.
+func prefixEnd(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
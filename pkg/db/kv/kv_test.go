@@ -0,0 +1,96 @@
+package kv
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.etcd.io/bbolt"
+)
+
+func testStores(t *testing.T) map[string]Store {
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "test.db"), 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	bolt, err := NewBoltStore(db, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]Store{"mem": NewMemStore(), "bolt": bolt}
+}
+
+func TestSeek(t *testing.T) {
+	keys := []string{"a1", "a3", "b1", "b2", "b4", "c1"}
+	tests := []struct {
+		prefix, start string
+		desc          bool
+		want          []string
+	}{
+		{"b", "", false, []string{"b1", "b2", "b4"}},
+		{"b", "", true, []string{"b4", "b2", "b1"}},
+		{"b", "b2", false, []string{"b2", "b4"}},
+		{"b", "b2", true, []string{"b2", "b1"}},
+		{"b", "b3", false, []string{"b4"}},
+		{"b", "b3", true, []string{"b2", "b1"}},
+		{"b", "b9", true, []string{"b4", "b2", "b1"}},
+		{"b", "b0", true, nil},
+		{"c", "", true, []string{"c1"}},
+		{"d", "", true, nil},
+		{"", "", false, keys},
+		{"", "", true, []string{"c1", "b4", "b2", "b1", "a3", "a1"}},
+		{"", "b2", true, []string{"b2", "b1", "a3", "a1"}},
+	}
+	for name, store := range testStores(t) {
+		for _, k := range keys {
+			if err := store.Put([]byte(k), []byte("v"+k)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, tt := range tests {
+			var start []byte
+			if tt.start != "" {
+				start = []byte(tt.start)
+			}
+			var got []string
+			err := store.Seek([]byte(tt.prefix), start, tt.desc, func(k, v []byte) error {
+				if string(v) != "v"+string(k) {
+					t.Errorf("%s: value of %s is %s", name, k, v)
+				}
+				got = append(got, string(k))
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("%s: Seek(%q, %q, %v) = %v, want %v", name, tt.prefix, tt.start, tt.desc, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestSeekStop(t *testing.T) {
+	for name, store := range testStores(t) {
+		for _, k := range []string{"a", "b", "c"} {
+			if err := store.Put([]byte(k), nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var got []string
+		err := store.Seek(nil, nil, false, func(k, _ []byte) error {
+			got = append(got, string(k))
+			if len(got) == 2 {
+				return ErrStop
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("%s: Seek returned %v", name, err)
+		}
+		if !slices.Equal(got, []string{"a", "b"}) {
+			t.Errorf("%s: visited %v", name, got)
+		}
+	}
+}
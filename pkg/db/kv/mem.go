@@ -0,0 +1,143 @@
+package kv
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type MemStore struct {
+	keys   []string
+	values map[string][]byte
+	mu     sync.RWMutex
+}
+
+func NewMemStore() *MemStore {
+	return &MemStore{
+		values: make(map[string][]byte),
+	}
+}
+
+func (ms *MemStore) Put(key, value []byte) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.put(key, value)
+	return nil
+}
+
+func (ms *MemStore) PutBatch(keys, values [][]byte) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i := range keys {
+		ms.put(keys[i], values[i])
+	}
+	return nil
+}
+
+func (ms *MemStore) put(key, value []byte) {
+	k := string(key)
+	if _, ok := ms.values[k]; !ok {
+		i := sort.SearchStrings(ms.keys, k)
+		ms.keys = append(ms.keys, "")
+		copy(ms.keys[i+1:], ms.keys[i:])
+		ms.keys[i] = k
+	}
+	ms.values[k] = bytes.Clone(value)
+}
+
+func (ms *MemStore) Get(key []byte) ([]byte, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	val, ok := ms.values[string(key)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return bytes.Clone(val), nil
+}
+
+func (ms *MemStore) Delete(key []byte) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	k := string(key)
+	if _, ok := ms.values[k]; !ok {
+		return nil
+	}
+	delete(ms.values, k)
+	i := sort.SearchStrings(ms.keys, k)
+	ms.keys = append(ms.keys[:i], ms.keys[i+1:]...)
+	return nil
+}
+
+func (ms *MemStore) ForEach(fn func(key, value []byte) error) error {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	for _, k := range ms.keys {
+		if err := fn([]byte(k), ms.values[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ms *MemStore) ForEachPrefix(prefix []byte, fn func(key, value []byte) error) error {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	p := string(prefix)
+	for i := sort.SearchStrings(ms.keys, p); i < len(ms.keys) && strings.HasPrefix(ms.keys[i], p); i++ {
+		k := ms.keys[i]
+		if err := fn([]byte(k), ms.values[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ms *MemStore) Seek(prefix, start []byte, desc bool, fn func(key, value []byte) error) error {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	p := string(prefix)
+	var i int
+	switch {
+	case !desc && start == nil:
+		i = sort.SearchStrings(ms.keys, p)
+	case !desc:
+		i = sort.SearchStrings(ms.keys, string(start))
+	case start == nil:
+		if end := prefixEnd(prefix); end == nil {
+			i = len(ms.keys) - 1
+		} else {
+			i = sort.SearchStrings(ms.keys, string(end)) - 1
+		}
+	default:
+		// the last key not after start
+		s := string(start)
+		i = sort.SearchStrings(ms.keys, s)
+		if i == len(ms.keys) || ms.keys[i] != s {
+			i--
+		}
+	}
+
+	for i >= 0 && i < len(ms.keys) && strings.HasPrefix(ms.keys[i], p) {
+		k := ms.keys[i]
+		if err := fn([]byte(k), ms.values[k]); errors.Is(err, ErrStop) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if desc {
+			i--
+		} else {
+			i++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package decoder
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httputil"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// MaxSize limits a decoded body, a small compressed body can expand to
+// gigabytes.
+const MaxSize = 64 << 20
+
+var ErrTooLarge = errors.New("decoded body is too large")
+
+// DecodeBody reverses the codings listed in a Content-Encoding header value.
+// Codings are applied by the server in the order they are listed, so they are
+// removed here in reverse order.
+func DecodeBody(body []byte, contentEncoding string) ([]byte, error) {
+	codings := strings.Split(contentEncoding, ",")
+	for i := len(codings) - 1; i >= 0; i-- {
+		coding := strings.ToLower(strings.TrimSpace(codings[i]))
+		if coding == "" || coding == "identity" {
+			continue
+		}
+
+		decoded, err := decode(body, coding)
+		if err != nil {
+			return nil, fmt.Errorf("can't decode %s body: %w", coding, err)
+		}
+		body = decoded
+	}
+	return body, nil
+}
+
+// Dechunk removes chunked transfer coding left in a body, for example when the
+// Transfer-Encoding header was kept but the framing was not consumed.
+func Dechunk(body []byte) ([]byte, error) {
+	return io.ReadAll(httputil.NewChunkedReader(bufio.NewReader(bytes.NewReader(body))))
+}
+
+func decode(body []byte, coding string) ([]byte, error) {
+	switch coding {
+	case "gzip", "x-gzip":
+		r, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return readAll(r)
+	case "deflate":
+		// most servers send zlib wrapped data, but some send raw deflate
+		if r, err := zlib.NewReader(bytes.NewReader(body)); err == nil {
+			defer r.Close()
+			if decoded, err := readAll(r); err == nil {
+				return decoded, nil
+			}
+		}
+		r := flate.NewReader(bytes.NewReader(body))
+		defer r.Close()
+		return readAll(r)
+	case "br":
+		return readAll(brotli.NewReader(bytes.NewReader(body)))
+	case "zstd":
+		r, err := zstd.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return readAll(r)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding")
+	}
+}
+
+// readAll reads up to MaxSize bytes and fails if there are more.
+func readAll(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxSize {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
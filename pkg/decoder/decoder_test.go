@@ -0,0 +1,122 @@
+package decoder
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// encoders compress data with the coding.
+var encoders = map[string]func(w io.Writer) io.WriteCloser{
+	"gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+	"deflate": func(w io.Writer) io.WriteCloser {
+		return zlib.NewWriter(w)
+	},
+	"raw deflate": func(w io.Writer) io.WriteCloser {
+		fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return fw
+	},
+	"br": func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+	"zstd": func(w io.Writer) io.WriteCloser {
+		zw, _ := zstd.NewWriter(w)
+		return zw
+	},
+}
+
+func encode(t *testing.T, coding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := encoders[coding](&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeBody(t *testing.T) {
+	data := []byte(strings.Repeat("<p>hello, world</p>\n", 100))
+	tests := []struct {
+		header string
+		body   []byte
+	}{
+		{"", data},
+		{"identity", data},
+		{"gzip", encode(t, "gzip", data)},
+		{"x-gzip", encode(t, "gzip", data)},
+		{"GZIP", encode(t, "gzip", data)},
+		{"deflate", encode(t, "deflate", data)},
+		{"deflate", encode(t, "raw deflate", data)},
+		{"br", encode(t, "br", data)},
+		{"zstd", encode(t, "zstd", data)},
+		// the last coding listed is applied last
+		{"gzip, br", encode(t, "br", encode(t, "gzip", data))},
+		{"zstd,identity, gzip", encode(t, "gzip", encode(t, "zstd", data))},
+	}
+	for _, tt := range tests {
+		got, err := DecodeBody(tt.body, tt.header)
+		if err != nil {
+			t.Errorf("%q: %v", tt.header, err)
+			continue
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%q: decoded %d bytes, want %d", tt.header, len(got), len(data))
+		}
+	}
+}
+
+func TestDecodeBodyErrors(t *testing.T) {
+	tests := []struct {
+		header string
+		body   []byte
+	}{
+		{"compress", []byte("data")},
+		{"gzip", []byte("not gzip")},
+		{"br", []byte("not brotli at all")},
+		{"zstd", []byte("not zstd")},
+		{"br, gzip", encode(t, "gzip", []byte("gzip only"))},
+	}
+	for _, tt := range tests {
+		if _, err := DecodeBody(tt.body, tt.header); err == nil {
+			t.Errorf("%q: %q decoded", tt.header, tt.body)
+		}
+	}
+}
+
+func TestDecodeBodyMaxSize(t *testing.T) {
+	for _, coding := range []string{"gzip", "zstd"} {
+		limit := encode(t, coding, make([]byte, MaxSize))
+		if got, err := DecodeBody(limit, coding); err != nil || len(got) != MaxSize {
+			t.Errorf("%s: body of MaxSize decoded to %d bytes, %v", coding, len(got), err)
+		}
+
+		// compressed zeros are about a thousand times smaller
+		bomb := encode(t, coding, make([]byte, MaxSize+1))
+		if _, err := DecodeBody(bomb, coding); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%s: bomb of %d bytes decoded with %v", coding, len(bomb), err)
+		}
+	}
+}
+
+func TestDechunk(t *testing.T) {
+	got, err := Dechunk([]byte("5\r\nhello\r\n7\r\n, world\r\n0\r\n\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "hello, world" {
+		t.Errorf("got %q", got)
+	}
+	if _, err := Dechunk([]byte("zz\r\nhello\r\n")); err == nil {
+		t.Error("broken chunk size accepted")
+	}
+}
@@ -0,0 +1,336 @@
+package diff
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+const (
+	BodyEmpty  = "empty"
+	BodyJSON   = "json"
+	BodyText   = "text"
+	BodyBinary = "binary"
+)
+
+// volatileHeaders differ between otherwise identical exchanges.
+var volatileHeaders = []string{
+	"Date",
+	"Age",
+	"Expires",
+	"Last-Modified",
+	"Server-Timing",
+	"Traceparent",
+	"X-Request-Id",
+	"X-Correlation-Id",
+	"X-Trace-Id",
+	"X-Runtime",
+	"X-Response-Time",
+	"Cf-Ray",
+	"X-Amz-Request-Id",
+	"X-Amz-Cf-Id",
+}
+
+// Change is a single difference. Volatile changes are reported, but don't
+// make exchanges unequal.
+type Change struct {
+	Path     string      `json:"path"`
+	Op       string      `json:"op"`
+	Old      interface{} `json:"old"`
+	New      interface{} `json:"new"`
+	Volatile bool        `json:"volatile,omitempty"`
+}
+
+// BodyDiff holds JSON changes, text hunks or, for binary bodies, hashes
+// depending on Mode.
+type BodyDiff struct {
+	Mode    string    `json:"mode"`
+	Equal   bool      `json:"equal"`
+	Changes []*Change `json:"changes,omitempty"`
+	Hunks   []*Hunk   `json:"hunks,omitempty"`
+	OldSize int       `json:"oldSize"`
+	NewSize int       `json:"newSize"`
+	OldHash string    `json:"oldHash,omitempty"`
+	NewHash string    `json:"newHash,omitempty"`
+}
+
+// PartDiff is a diff of request or response. Fields are method and URL for
+// request and status for response.
+type PartDiff struct {
+	Equal   bool      `json:"equal"`
+	Fields  []*Change `json:"fields"`
+	Headers []*Change `json:"headers"`
+	Cookies []*Change `json:"cookies"`
+	Body    *BodyDiff `json:"body,omitempty"`
+}
+
+type Result struct {
+	Equal    bool      `json:"equal"`
+	Request  *PartDiff `json:"request"`
+	Response *PartDiff `json:"response"`
+}
+
+type Options struct {
+	// Ignore holds header names, cookie names and JSON paths like "$.ts"
+	// which are volatile in addition to the default headers.
+	Ignore []string
+}
+
+type differ struct {
+	headers map[string]bool
+	names   map[string]bool
+}
+
+// Compare diffs two exchanges, bodies have to be loaded.
+func Compare(a, b *models.RequestInfo, opts *Options) *Result {
+	d := &differ{headers: make(map[string]bool), names: make(map[string]bool)}
+	for _, h := range volatileHeaders {
+		d.headers[h] = true
+	}
+	if opts != nil {
+		for _, name := range opts.Ignore {
+			d.headers[http.CanonicalHeaderKey(name)] = true
+			d.names[name] = true
+		}
+	}
+
+	res := &Result{
+		Request:  d.compareRequests(a.Request, b.Request),
+		Response: d.compareResponses(a.Response, b.Response),
+	}
+	res.Equal = res.Request.Equal && res.Response.Equal
+	return res
+}
+
+func (d *differ) compareRequests(a, b *models.ParsedRequest) *PartDiff {
+	part := newPartDiff()
+	if a.Method != b.Method {
+		part.Fields = append(part.Fields, &Change{Path: "method", Op: ChangeChanged, Old: a.Method, New: b.Method})
+	}
+	if a.URL != b.URL {
+		part.Fields = append(part.Fields, &Change{Path: "url", Op: ChangeChanged, Old: a.URL, New: b.URL})
+	}
+	part.Headers = d.compareHeaders(a.Header, b.Header, "Cookie")
+	part.Cookies = d.compareCookies(requestCookies(a.Cookies), requestCookies(b.Cookies))
+	part.Body = d.compareBodies(a.Body, b.Body, a.BodyMeta, b.BodyMeta)
+	part.Equal = isEqual(part)
+	return part
+}
+
+func (d *differ) compareResponses(a, b *models.ParsedResponse) *PartDiff {
+	part := newPartDiff()
+	switch {
+	case a == nil && b == nil:
+		part.Equal = true
+		return part
+	case a == nil || b == nil:
+		change := &Change{Path: "status", Op: ChangeAdded}
+		if a != nil {
+			change.Op, change.Old = ChangeRemoved, a.StatusCode
+		} else {
+			change.New = b.StatusCode
+		}
+		part.Fields = append(part.Fields, change)
+		return part
+	}
+
+	if a.StatusCode != b.StatusCode {
+		part.Fields = append(part.Fields, &Change{Path: "status", Op: ChangeChanged, Old: a.StatusCode, New: b.StatusCode})
+	}
+	part.Headers = d.compareHeaders(a.Header, b.Header, "Set-Cookie")
+	part.Cookies = d.compareCookies(responseCookies(a.Cookies), responseCookies(b.Cookies))
+	part.Body = d.compareBodies(a.Body, b.Body, a.BodyMeta, b.BodyMeta)
+	part.Equal = isEqual(part)
+	return part
+}
+
+func newPartDiff() *PartDiff {
+	return &PartDiff{
+		Fields:  make([]*Change, 0),
+		Headers: make([]*Change, 0),
+		Cookies: make([]*Change, 0),
+	}
+}
+
+// compareHeaders skips header which is compared as cookies.
+func (d *differ) compareHeaders(a, b http.Header, skip string) []*Change {
+	changes := compareValues(canonical(a), canonical(b), skip)
+	for _, c := range changes {
+		c.Volatile = d.headers[c.Path]
+	}
+	return changes
+}
+
+func (d *differ) compareCookies(a, b map[string][]string) []*Change {
+	changes := compareValues(a, b, "")
+	for _, c := range changes {
+		c.Volatile = d.names[c.Path]
+	}
+	return changes
+}
+
+func compareValues(a, b map[string][]string, skip string) []*Change {
+	names := make([]string, 0, len(a)+len(b))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]*Change, 0)
+	for _, name := range names {
+		if name == skip {
+			continue
+		}
+		va, inA := a[name]
+		vb, inB := b[name]
+		switch {
+		case !inA:
+			changes = append(changes, &Change{Path: name, Op: ChangeAdded, New: vb})
+		case !inB:
+			changes = append(changes, &Change{Path: name, Op: ChangeRemoved, Old: va})
+		case !slices.Equal(va, vb):
+			changes = append(changes, &Change{Path: name, Op: ChangeChanged, Old: va, New: vb})
+		}
+	}
+	return changes
+}
+
+func (d *differ) compareBodies(a, b []byte, metaA, metaB models.BodyMeta) *BodyDiff {
+	body := &BodyDiff{OldSize: len(a), NewSize: len(b), Equal: bytes.Equal(a, b)}
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		body.Mode = BodyEmpty
+	case isBinary(a, metaA) || isBinary(b, metaB):
+		body.Mode = BodyBinary
+		body.OldHash = hash(a)
+		body.NewHash = hash(b)
+	default:
+		if changes, ok := d.compareJSONBodies(a, b, metaA, metaB); ok {
+			body.Mode = BodyJSON
+			body.Changes = changes
+			body.Equal = !hasChanges(changes)
+			return body
+		}
+		body.Mode = BodyText
+		if !body.Equal {
+			body.Hunks = TextHunks(string(a), string(b))
+		}
+	}
+	return body
+}
+
+// compareJSONBodies compares bodies as JSON if both of them are JSON.
+func (d *differ) compareJSONBodies(a, b []byte, metaA, metaB models.BodyMeta) ([]*Change, bool) {
+	if !looksJSON(a, metaA) || !looksJSON(b, metaB) {
+		return nil, false
+	}
+	va, err := parseJSON(a)
+	if err != nil {
+		return nil, false
+	}
+	vb, err := parseJSON(b)
+	if err != nil {
+		return nil, false
+	}
+
+	changes := compareJSON("$", va, vb, make([]*Change, 0))
+	for _, c := range changes {
+		c.Volatile = d.volatilePath(c.Path)
+	}
+	return changes, true
+}
+
+// volatilePath reports whether path or any of its parents is ignored.
+func (d *differ) volatilePath(path string) bool {
+	for p := range d.names {
+		if !strings.HasPrefix(p, "$") || !strings.HasPrefix(path, p) {
+			continue
+		}
+		if len(path) == len(p) || path[len(p)] == '.' || path[len(p)] == '[' {
+			return true
+		}
+	}
+	return false
+}
+
+func looksJSON(body []byte, meta models.BodyMeta) bool {
+	if strings.Contains(meta.MimeType, "json") {
+		return true
+	}
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+func isBinary(body []byte, meta models.BodyMeta) bool {
+	return meta.Binary || !utf8.Valid(body)
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func isEqual(part *PartDiff) bool {
+	return !hasChanges(part.Fields) && !hasChanges(part.Headers) && !hasChanges(part.Cookies) &&
+		(part.Body == nil || part.Body.Equal)
+}
+
+func hasChanges(changes []*Change) bool {
+	for _, c := range changes {
+		if !c.Volatile {
+			return true
+		}
+	}
+	return false
+}
+
+func canonical(h http.Header) map[string][]string {
+	res := make(map[string][]string, len(h))
+	for name, values := range h {
+		name = http.CanonicalHeaderKey(name)
+		res[name] = append(res[name], values...)
+	}
+	return res
+}
+
+func requestCookies(cookies []*http.Cookie) map[string][]string {
+	res := make(map[string][]string)
+	for _, c := range cookies {
+		res[c.Name] = append(res[c.Name], c.Value)
+	}
+	return res
+}
+
+// responseCookies leaves out expiration, it changes on every response.
+func responseCookies(cookies []*http.Cookie) map[string][]string {
+	res := make(map[string][]string)
+	for _, c := range cookies {
+		v := c.Value
+		if c.Path != "" {
+			v += "; Path=" + c.Path
+		}
+		if c.Domain != "" {
+			v += "; Domain=" + c.Domain
+		}
+		if c.Secure {
+			v += "; Secure"
+		}
+		if c.HttpOnly {
+			v += "; HttpOnly"
+		}
+		res[c.Name] = append(res[c.Name], v)
+	}
+	return res
+}
@@ -0,0 +1,94 @@
+package diff
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+func exchange(url string, header http.Header, body string, status int, respBody string) *models.RequestInfo {
+	return &models.RequestInfo{
+		Request: &models.ParsedRequest{Method: "POST", URL: url, Header: header, Body: models.Body(body)},
+		Response: &models.ParsedResponse{
+			StatusCode: status,
+			Header:     http.Header{"Date": {"Mon, 19 Oct 2026 10:00:00 GMT"}},
+			Body:       models.Body(respBody),
+		},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	header := http.Header{"Content-Type": {"application/json"}, "X-Request-Id": {"1"}}
+	tests := []struct {
+		name   string
+		a, b   *models.RequestInfo
+		ignore []string
+		equal  bool
+		mode   string
+	}{
+		{"volatile headers",
+			exchange("http://a.test/", header, `{"a":1}`, 200, "ok"),
+			exchange("http://a.test/", http.Header{"Content-Type": {"application/json"}, "X-Request-Id": {"2"}}, `{"a":1}`, 200, "ok"),
+			nil, true, BodyJSON},
+		{"json key order",
+			exchange("http://a.test/", header, `{"a":1,"b":2}`, 200, "ok"),
+			exchange("http://a.test/", header, `{"b":2,"a":1.0}`, 200, "ok"),
+			nil, true, BodyJSON},
+		{"ignored json path",
+			exchange("http://a.test/", header, `{"ts":{"s":1},"a":1}`, 200, "ok"),
+			exchange("http://a.test/", header, `{"ts":{"s":2},"a":1}`, 200, "ok"),
+			[]string{"$.ts"}, true, BodyJSON},
+		{"prefix of ignored path",
+			exchange("http://a.test/", header, `{"tsx":1}`, 200, "ok"),
+			exchange("http://a.test/", header, `{"tsx":2}`, 200, "ok"),
+			[]string{"$.ts"}, false, BodyJSON},
+		{"ignored header",
+			exchange("http://a.test/", http.Header{"X-Nonce": {"1"}}, "", 200, "ok"),
+			exchange("http://a.test/", http.Header{"x-nonce": {"2"}}, "", 200, "ok"),
+			[]string{"x-nonce"}, true, BodyEmpty},
+		{"url",
+			exchange("http://a.test/1", nil, "", 200, "ok"),
+			exchange("http://a.test/2", nil, "", 200, "ok"),
+			nil, false, BodyEmpty},
+		{"text body",
+			exchange("http://a.test/", nil, "a\nb\n", 200, "ok"),
+			exchange("http://a.test/", nil, "a\nc\n", 200, "ok"),
+			nil, false, BodyText},
+		{"binary body",
+			exchange("http://a.test/", nil, "\xff\x00", 200, "ok"),
+			exchange("http://a.test/", nil, "\xff\x01", 200, "ok"),
+			nil, false, BodyBinary},
+	}
+	for _, tt := range tests {
+		res := Compare(tt.a, tt.b, &Options{Ignore: tt.ignore})
+		if res.Equal != tt.equal || res.Request.Body.Mode != tt.mode {
+			t.Errorf("%s: equal %v with %s body, want %v with %s", tt.name, res.Equal, res.Request.Body.Mode, tt.equal, tt.mode)
+		}
+		if !res.Response.Equal {
+			t.Errorf("%s: responses differ: %+v", tt.name, res.Response)
+		}
+	}
+}
+
+func TestCompareResponses(t *testing.T) {
+	a := exchange("http://a.test/", nil, "", 200, "ok")
+	a.Response.Cookies = []*http.Cookie{{Name: "sid", Value: "1", Path: "/"}}
+	b := exchange("http://a.test/", nil, "", 500, "ok")
+	b.Response.Cookies = []*http.Cookie{{Name: "sid", Value: "1", Path: "/", HttpOnly: true}}
+
+	res := Compare(a, b, nil)
+	if res.Equal || len(res.Response.Fields) != 1 || res.Response.Fields[0].Path != "status" || len(res.Response.Cookies) != 1 {
+		t.Errorf("got %+v", res.Response)
+	}
+
+	b.Response = nil
+	res = Compare(a, b, nil)
+	if res.Equal || res.Response.Fields[0].Op != ChangeRemoved {
+		t.Errorf("missing response: got %+v", res.Response)
+	}
+	a.Response = nil
+	if res = Compare(a, b, nil); !res.Equal {
+		t.Errorf("exchanges without responses differ: %+v", res.Response)
+	}
+}
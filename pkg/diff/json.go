@@ -0,0 +1,117 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// parseJSON decodes whole data as single JSON value, numbers are kept as
+// written to compare them without float rounding.
+func parseJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("data after json value")
+	}
+	return v, nil
+}
+
+// compareJSON compares values semantically: key order and number formatting
+// don't matter, arrays are compared by index.
+func compareJSON(path string, a, b interface{}, changes []*Change) []*Change {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(av)+len(bv))
+		for k := range av {
+			keys = append(keys, k)
+		}
+		for k := range bv {
+			if _, ok := av[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			p := jsonKeyPath(path, k)
+			va, inA := av[k]
+			vb, inB := bv[k]
+			switch {
+			case !inA:
+				changes = append(changes, &Change{Path: p, Op: ChangeAdded, New: vb})
+			case !inB:
+				changes = append(changes, &Change{Path: p, Op: ChangeRemoved, Old: va})
+			default:
+				changes = compareJSON(p, va, vb, changes)
+			}
+		}
+		return changes
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < max(len(av), len(bv)); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(av):
+				changes = append(changes, &Change{Path: p, Op: ChangeAdded, New: bv[i]})
+			case i >= len(bv):
+				changes = append(changes, &Change{Path: p, Op: ChangeRemoved, Old: av[i]})
+			default:
+				changes = compareJSON(p, av[i], bv[i], changes)
+			}
+		}
+		return changes
+	case json.Number:
+		if bv, ok := b.(json.Number); ok && numbersEqual(av, bv) {
+			return changes
+		}
+	default:
+		// strings, bools and nulls
+		if a == b {
+			return changes
+		}
+	}
+
+	return append(changes, &Change{Path: path, Op: ChangeChanged, Old: a, New: b})
+}
+
+func numbersEqual(a, b json.Number) bool {
+	if a == b {
+		return true
+	}
+	fa, errA := strconv.ParseFloat(string(a), 64)
+	fb, errB := strconv.ParseFloat(string(b), 64)
+	return errA == nil && errB == nil && fa == fb
+}
+
+var identRe = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// jsonKeyPath writes JSONPath-like path, keys which are not identifiers are
+// quoted.
+func jsonKeyPath(path, key string) string {
+	if identRe.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
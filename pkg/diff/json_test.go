@@ -0,0 +1,50 @@
+package diff
+
+import "testing"
+
+func TestCompareJSON(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []Change
+	}{
+		{`{"a":1,"b":[1,2]}`, `{"b":[1,2],"a":1}`, nil},
+		{`{"n":1}`, `{"n":1.0}`, nil},
+		{`{"n":1e2}`, `{"n":100}`, nil},
+		{`{"n":12345678901234567890}`, `{"n":12345678901234567891}`, nil},
+		{`{"a":1}`, `{"a":2}`, []Change{{Path: "$.a", Op: ChangeChanged}}},
+		{`{"a":1}`, `{"a":"1"}`, []Change{{Path: "$.a", Op: ChangeChanged}}},
+		{`{"a":null}`, `{"a":false}`, []Change{{Path: "$.a", Op: ChangeChanged}}},
+		{`{"a":1}`, `{"a":1,"b":2}`, []Change{{Path: "$.b", Op: ChangeAdded}}},
+		{`{"a":1,"b":2}`, `{"b":2}`, []Change{{Path: "$.a", Op: ChangeRemoved}}},
+		{`{"x-y":{"$id":1}}`, `{"x-y":{"$id":2}}`, []Change{{Path: `$["x-y"].$id`, Op: ChangeChanged}}},
+		{`[1,{"a":[true]}]`, `[1,{"a":[false,true]}]`, []Change{
+			{Path: "$[1].a[0]", Op: ChangeChanged},
+			{Path: "$[1].a[1]", Op: ChangeAdded},
+		}},
+		{`[1,2,3]`, `[1]`, []Change{{Path: "$[1]", Op: ChangeRemoved}, {Path: "$[2]", Op: ChangeRemoved}}},
+		{`{"a":{"b":1}}`, `{"a":[1]}`, []Change{{Path: "$.a", Op: ChangeChanged}}},
+	}
+	for _, tt := range tests {
+		a, errA := parseJSON([]byte(tt.a))
+		b, errB := parseJSON([]byte(tt.b))
+		if errA != nil || errB != nil {
+			t.Fatalf("can't parse %s or %s", tt.a, tt.b)
+		}
+		got := compareJSON("$", a, b, nil)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s vs %s: got %d changes, want %d", tt.a, tt.b, len(got), len(tt.want))
+			continue
+		}
+		for i, c := range got {
+			if c.Path != tt.want[i].Path || c.Op != tt.want[i].Op {
+				t.Errorf("%s vs %s: change %d is %s %s, want %s %s", tt.a, tt.b, i, c.Op, c.Path, tt.want[i].Op, tt.want[i].Path)
+			}
+		}
+	}
+
+	for _, data := range []string{`{"a":1} {"b":2}`, `{"a":`, ``} {
+		if _, err := parseJSON([]byte(data)); err == nil {
+			t.Errorf("%q is parsed as single json value", data)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package diff
+
+import "strings"
+
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// hunkContext is number of equal lines kept around changes.
+const hunkContext = 3
+
+// maxEdits bounds Myers search, bigger texts are shown as replaced at once.
+const maxEdits = 2000
+
+type Line struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Hunk is a group of changed lines with context, like in unified diff.
+// Starts are 1-based.
+type Hunk struct {
+	OldStart int     `json:"oldStart"`
+	OldLines int     `json:"oldLines"`
+	NewStart int     `json:"newStart"`
+	NewLines int     `json:"newLines"`
+	Lines    []*Line `json:"lines"`
+}
+
+// TextHunks returns line diff of a and b grouped into hunks.
+func TextHunks(a, b string) []*Hunk {
+	return hunks(lineOps(splitLines(a), splitLines(b)))
+}
+
+// splitLines keeps line endings, so missing newline at the end is a change.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+type lineOp struct {
+	op   string
+	text string
+}
+
+// lineOps finds the shortest edit script with Myers algorithm.
+func lineOps(a, b []string) []lineOp {
+	n, m := len(a), len(b)
+	offset := maxEdits + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= maxEdits && !found; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+
+	if !found {
+		ops := make([]lineOp, 0, n+m)
+		for _, l := range a {
+			ops = append(ops, lineOp{OpDelete, l})
+		}
+		for _, l := range b {
+			ops = append(ops, lineOp{OpInsert, l})
+		}
+		return ops
+	}
+
+	var ops []lineOp
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		get := func(k int) int { return prev[k+d-1] }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && get(k-1) < get(k+1)) {
+			prevK = k + 1
+		}
+		prevX := get(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, lineOp{OpEqual, a[x]})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, lineOp{OpInsert, b[y]})
+		} else {
+			x--
+			ops = append(ops, lineOp{OpDelete, a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, lineOp{OpEqual, a[x]})
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+func hunks(ops []lineOp) []*Hunk {
+	// line numbers before each op
+	oldAt := make([]int, len(ops)+1)
+	newAt := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldAt[i+1], newAt[i+1] = oldAt[i], newAt[i]
+		if op.op != OpInsert {
+			oldAt[i+1]++
+		}
+		if op.op != OpDelete {
+			newAt[i+1]++
+		}
+	}
+
+	res := make([]*Hunk, 0)
+	for i := 0; i < len(ops); {
+		if ops[i].op == OpEqual {
+			i++
+			continue
+		}
+
+		// changes closer than two contexts share a hunk
+		last := i
+		for j := i + 1; j < len(ops) && j-last <= 2*hunkContext+1; j++ {
+			if ops[j].op != OpEqual {
+				last = j
+			}
+		}
+		start := max(i-hunkContext, 0)
+		stop := min(last+hunkContext+1, len(ops))
+
+		h := &Hunk{
+			OldStart: oldAt[start] + 1,
+			OldLines: oldAt[stop] - oldAt[start],
+			NewStart: newAt[start] + 1,
+			NewLines: newAt[stop] - newAt[start],
+		}
+		for _, op := range ops[start:stop] {
+			h.Lines = append(h.Lines, &Line{Op: op.op, Text: op.text})
+		}
+		res = append(res, h)
+		i = stop
+	}
+	return res
+}
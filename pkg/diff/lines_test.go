@@ -0,0 +1,165 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// apply rebuilds both texts from ops.
+func apply(ops []lineOp) (a, b []string) {
+	for _, op := range ops {
+		if op.op != OpInsert {
+			a = append(a, op.text)
+		}
+		if op.op != OpDelete {
+			b = append(b, op.text)
+		}
+	}
+	return a, b
+}
+
+func countOps(ops []lineOp) map[string]int {
+	res := make(map[string]int)
+	for _, op := range ops {
+		res[op.op]++
+	}
+	return res
+}
+
+func numbered(prefix string, n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%s%d\n", prefix, i)
+	}
+	return lines
+}
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\n", []string{"a\n"}},
+		{"a\nb", []string{"a\n", "b"}},
+		{"a\n\nb\n", []string{"a\n", "\n", "b\n"}},
+		{"a\r\nb\r\n", []string{"a\r\n", "b\r\n"}},
+	}
+	for _, tt := range tests {
+		if got := splitLines(tt.s); !slices.Equal(got, tt.want) {
+			t.Errorf("splitLines(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestLineOps(t *testing.T) {
+	tests := []struct {
+		a, b string
+		// number of deleted and inserted lines of the shortest script
+		deleted, inserted int
+	}{
+		{"", "", 0, 0},
+		{"a\nb\n", "a\nb\n", 0, 0},
+		{"", "a\nb\n", 0, 2},
+		{"a\nb\n", "", 2, 0},
+		{"a\nb\nc\n", "a\nc\n", 1, 0},
+		{"a\nc\n", "a\nb\nc\n", 0, 1},
+		{"a\nb\nc\n", "a\nx\nc\n", 1, 1},
+		{"a\nb\nc\nd\n", "b\nc\nd\na\n", 1, 1},
+		{"a\nb", "a\nb\n", 1, 1},
+		{"a\nb\nc\na\nb\nb\na\n", "c\nb\na\nb\na\nc\n", 3, 2},
+	}
+	for _, tt := range tests {
+		a, b := splitLines(tt.a), splitLines(tt.b)
+		ops := lineOps(a, b)
+		gotA, gotB := apply(ops)
+		if !slices.Equal(gotA, a) || !slices.Equal(gotB, b) {
+			t.Errorf("lineOps(%q, %q) doesn't rebuild the texts: %v", tt.a, tt.b, ops)
+		}
+		n := countOps(ops)
+		if n[OpDelete] != tt.deleted || n[OpInsert] != tt.inserted {
+			t.Errorf("lineOps(%q, %q) deletes %d and inserts %d lines, want %d and %d",
+				tt.a, tt.b, n[OpDelete], n[OpInsert], tt.deleted, tt.inserted)
+		}
+	}
+}
+
+func TestLineOpsMaxEdits(t *testing.T) {
+	tests := []struct {
+		name string
+		// number of different lines on each side, a common line follows them
+		changed int
+		// the common line is kept when the script is found
+		found bool
+	}{
+		{"under limit", maxEdits/2 - 1, true},
+		{"at limit", maxEdits / 2, true},
+		{"over limit", maxEdits/2 + 1, false},
+	}
+	for _, tt := range tests {
+		a := append(numbered("a", tt.changed), "same\n")
+		b := append(numbered("b", tt.changed), "same\n")
+		ops := lineOps(a, b)
+		gotA, gotB := apply(ops)
+		if !slices.Equal(gotA, a) || !slices.Equal(gotB, b) {
+			t.Errorf("%s: ops don't rebuild the texts", tt.name)
+		}
+
+		n := countOps(ops)
+		if tt.found && (n[OpEqual] != 1 || n[OpDelete] != tt.changed || n[OpInsert] != tt.changed) {
+			t.Errorf("%s: got %v, want the common line kept", tt.name, n)
+		}
+		if !tt.found {
+			// replaced at once: every line of a is deleted before b is inserted
+			if n[OpEqual] != 0 || n[OpDelete] != len(a) || n[OpInsert] != len(b) || ops[len(a)-1].op != OpDelete {
+				t.Errorf("%s: got %v, want the whole text replaced", tt.name, n)
+			}
+		}
+	}
+}
+
+func TestTextHunks(t *testing.T) {
+	lines := func(from, to int) string {
+		return strings.Join(numbered("l", to)[from:], "")
+	}
+	tests := []struct {
+		name string
+		a, b string
+		want []Hunk
+	}{
+		{"equal", lines(0, 10), lines(0, 10), nil},
+		{"one change in the middle", lines(0, 10), strings.Replace(lines(0, 10), "l5\n", "x\n", 1),
+			[]Hunk{{OldStart: 3, OldLines: 7, NewStart: 3, NewLines: 7}}},
+		{"change at the start", lines(0, 10), "x\n" + lines(1, 10),
+			[]Hunk{{OldStart: 1, OldLines: 4, NewStart: 1, NewLines: 4}}},
+		{"insert at the end", lines(0, 10), lines(0, 10) + "x\n",
+			[]Hunk{{OldStart: 8, OldLines: 3, NewStart: 8, NewLines: 4}}},
+		{"from empty", "", "a\nb\n",
+			[]Hunk{{OldStart: 1, OldLines: 0, NewStart: 1, NewLines: 2}}},
+		// 6 equal lines between changes fit into two contexts
+		{"close changes", lines(0, 20), strings.NewReplacer("l3\n", "x\n", "l10\n", "y\n").Replace(lines(0, 20)),
+			[]Hunk{{OldStart: 1, OldLines: 14, NewStart: 1, NewLines: 14}}},
+		{"far changes", lines(0, 20), strings.NewReplacer("l3\n", "x\n", "l11\n", "y\n").Replace(lines(0, 20)),
+			[]Hunk{
+				{OldStart: 1, OldLines: 7, NewStart: 1, NewLines: 7},
+				{OldStart: 9, OldLines: 7, NewStart: 9, NewLines: 7},
+			}},
+	}
+	for _, tt := range tests {
+		got := TextHunks(tt.a, tt.b)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %d hunks, want %d", tt.name, len(got), len(tt.want))
+			continue
+		}
+		for i, h := range got {
+			w := tt.want[i]
+			if h.OldStart != w.OldStart || h.OldLines != w.OldLines || h.NewStart != w.NewStart || h.NewLines != w.NewLines {
+				t.Errorf("%s: hunk %d is -%d,%d +%d,%d, want -%d,%d +%d,%d", tt.name, i,
+					h.OldStart, h.OldLines, h.NewStart, h.NewLines, w.OldStart, w.OldLines, w.NewStart, w.NewLines)
+			}
+		}
+	}
+}
@@ -4,9 +4,21 @@ import (
 	"fmt"
 	"github.com/joho/godotenv"
 	"os"
+	"strconv"
 	"time"
 )
 
+const (
+	// bodies bigger than this are moved out of request documents
+	defaultBlobThreshold     = 1 << 20
+	defaultRetentionInterval = time.Minute
+
+	defaultScanConcurrency = 10
+	defaultScanRateLimit   = 20
+	defaultScanTimeout     = 10 * time.Second
+	defaultScanDelay       = 3 * time.Second
+)
+
 type MongoConfig struct {
 	User     string
 	Password string
@@ -28,10 +40,54 @@ type ProxyConfig struct {
 	KeyPath  string
 	CertPath string
 }
+
+type StorageConfig struct {
+	Backend  string
+	BoltPath string
+}
+
+type BlobConfig struct {
+	Store     string
+	Dir       string
+	Threshold int64
+}
+
+// RetentionConfig limits stored traffic, zero value disables a limit.
+type RetentionConfig struct {
+	MaxAge   time.Duration
+	MaxCount int64
+	MaxSize  int64
+	Interval time.Duration
+}
+
+// ScanConfig limits load the scanner puts on scanned hosts. RateLimit is
+// requests per second to one host, zero disables it. Delay is the sleep
+// time-based checks ask the server for, they wait for twice as long too.
+type ScanConfig struct {
+	Concurrency int
+	RateLimit   int
+	Timeout     time.Duration
+	Delay       time.Duration
+}
+
+// OOBConfig is the out-of-band callback server, both addresses empty
+// disable it. PublicHost is how scanned hosts reach the HTTP listener.
+type OOBConfig struct {
+	HTTPAddr   string
+	DNSAddr    string
+	Domain     string
+	PublicHost string
+}
+
 type Config struct {
-	ProxyConfig  ProxyConfig
-	MongoConfig  MongoConfig
-	ServerConfig ServerConfig
+	ProxyConfig   ProxyConfig
+	MongoConfig   MongoConfig
+	ServerConfig  ServerConfig
+	StorageConfig StorageConfig
+	BlobConfig    BlobConfig
+	Retention     RetentionConfig
+	Scan          ScanConfig
+	OOB           OOBConfig
 }
 
 func NewConfig(envPath string) (*Config, error) {
@@ -40,6 +96,21 @@ func NewConfig(envPath string) (*Config, error) {
 		return nil, fmt.Errorf("can't create config: %v", err)
 	}
 
+	blobThreshold, err := getInt64("BLOB_THRESHOLD", defaultBlobThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("can't create config: %v", err)
+	}
+
+	retention, err := newRetentionConfig()
+	if err != nil {
+		return nil, fmt.Errorf("can't create config: %v", err)
+	}
+
+	scan, err := newScanConfig()
+	if err != nil {
+		return nil, fmt.Errorf("can't create config: %v", err)
+	}
+
 	cfg := &Config{
 		ProxyConfig: ProxyConfig{
 			Addr:     os.Getenv("PROXY_ADDR"),
@@ -58,7 +129,113 @@ func NewConfig(envPath string) (*Config, error) {
 			Port:     os.Getenv("MONGO_PORT"),
 			Database: os.Getenv("MONGO_DATABASE"),
 		},
+		StorageConfig: StorageConfig{
+			Backend:  getString("STORAGE_BACKEND", "mongo"),
+			BoltPath: getString("BOLT_PATH", "mitmproxy.db"),
+		},
+		BlobConfig: BlobConfig{
+			Store:     os.Getenv("BLOB_STORE"),
+			Dir:       getString("BLOB_DIR", "blobs"),
+			Threshold: blobThreshold,
+		},
+		Retention: *retention,
+		Scan:      *scan,
+		OOB: OOBConfig{
+			HTTPAddr:   os.Getenv("OOB_HTTP_ADDR"),
+			DNSAddr:    os.Getenv("OOB_DNS_ADDR"),
+			Domain:     getString("OOB_DOMAIN", "oob.local"),
+			PublicHost: os.Getenv("OOB_PUBLIC_HOST"),
+		},
 	}
 
 	return cfg, nil
 }
+
+func newRetentionConfig() (*RetentionConfig, error) {
+	maxAge, err := getDuration("RETENTION_MAX_AGE", 0)
+	if err != nil {
+		return nil, err
+	}
+	maxCount, err := getInt64("RETENTION_MAX_COUNT", 0)
+	if err != nil {
+		return nil, err
+	}
+	maxSize, err := getInt64("RETENTION_MAX_SIZE", 0)
+	if err != nil {
+		return nil, err
+	}
+	interval, err := getDuration("RETENTION_INTERVAL", defaultRetentionInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RetentionConfig{
+		MaxAge:   maxAge,
+		MaxCount: maxCount,
+		MaxSize:  maxSize,
+		Interval: interval,
+	}, nil
+}
+
+func newScanConfig() (*ScanConfig, error) {
+	concurrency, err := getInt64("SCAN_CONCURRENCY", defaultScanConcurrency)
+	if err != nil {
+		return nil, err
+	}
+	if concurrency <= 0 {
+		return nil, fmt.Errorf("SCAN_CONCURRENCY must be positive")
+	}
+	rateLimit, err := getInt64("SCAN_RATE_LIMIT", defaultScanRateLimit)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := getDuration("SCAN_TIMEOUT", defaultScanTimeout)
+	if err != nil {
+		return nil, err
+	}
+	delay, err := getDuration("SCAN_DELAY", defaultScanDelay)
+	if err != nil {
+		return nil, err
+	}
+	if delay < time.Second || 2*delay >= timeout {
+		return nil, fmt.Errorf("SCAN_DELAY must be at least 1s and less than half of SCAN_TIMEOUT")
+	}
+
+	return &ScanConfig{
+		Concurrency: int(concurrency),
+		RateLimit:   int(rateLimit),
+		Timeout:     timeout,
+		Delay:       delay,
+	}, nil
+}
+
+func getString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func getDuration(key string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("wrong %s value %q: %v", key, v, err)
+	}
+	return d, nil
+}
+
+func getInt64(key string, def int64) (int64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("wrong %s value %q: %v", key, v, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,149 @@
+package flowfilter
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+// Expr is a parsed filter expression. It can be matched in process or walked
+// by other compilers, e.g. to build a Mongo query.
+type Expr interface {
+	Match(req *models.RequestInfoWithID) bool
+	String() string
+}
+
+type And struct {
+	Left, Right Expr
+}
+
+type Or struct {
+	Left, Right Expr
+}
+
+type Not struct {
+	Expr Expr
+}
+
+// Part tells which side of exchange a filter looks at.
+type Part int
+
+const (
+	PartAny Part = iota
+	PartRequest
+	PartResponse
+)
+
+// Filter is a single ~x filter. Regex filters keep both the source pattern
+// and the compiled regexp, so other compilers can reuse the pattern.
+type Filter struct {
+	Op      string
+	Part    Part
+	Pattern string
+	Code    int
+	re      *regexp.Regexp
+}
+
+func (e *And) Match(req *models.RequestInfoWithID) bool {
+	return e.Left.Match(req) && e.Right.Match(req)
+}
+
+func (e *And) String() string {
+	return "(" + e.Left.String() + " & " + e.Right.String() + ")"
+}
+
+func (e *Or) Match(req *models.RequestInfoWithID) bool {
+	return e.Left.Match(req) || e.Right.Match(req)
+}
+
+func (e *Or) String() string {
+	return "(" + e.Left.String() + " | " + e.Right.String() + ")"
+}
+
+func (e *Not) Match(req *models.RequestInfoWithID) bool {
+	return !e.Expr.Match(req)
+}
+
+func (e *Not) String() string {
+	return "!" + e.Expr.String()
+}
+
+func (f *Filter) String() string {
+	switch f.Op {
+	case OpAll, OpRequest, OpResponse:
+		return f.Op
+	default:
+		return f.Op + " " + quote(f.Pattern)
+	}
+}
+
+func (f *Filter) Match(req *models.RequestInfoWithID) bool {
+	if req.Request == nil {
+		return false
+	}
+	resp := req.Response
+
+	switch f.Op {
+	case OpAll:
+		return true
+	case OpRequest:
+		return resp == nil
+	case OpResponse:
+		return resp != nil
+	case OpDomain:
+		return f.re.MatchString(req.Request.Host)
+	case OpMethod:
+		return f.re.MatchString(req.Request.Method)
+	case OpURL:
+		return f.re.MatchString(req.Request.URL)
+	case OpCode:
+		return resp != nil && resp.StatusCode == f.Code
+	case OpHeader, OpHeaderRequest, OpHeaderResponse:
+		if f.Part != PartResponse && f.matchHeaders(req.Request.Header) {
+			return true
+		}
+		return f.Part != PartRequest && resp != nil && f.matchHeaders(resp.Header)
+	case OpBody, OpBodyRequest, OpBodyResponse:
+		if f.Part != PartResponse && f.matchBody(req.Request.Body) {
+			return true
+		}
+		return f.Part != PartRequest && resp != nil && f.matchBody(resp.Body)
+	case OpType, OpTypeRequest, OpTypeResponse:
+		if f.Part != PartResponse && f.re.MatchString(req.Request.BodyMeta.MimeType) {
+			return true
+		}
+		return f.Part != PartRequest && resp != nil && f.re.MatchString(resp.BodyMeta.MimeType)
+	}
+	return false
+}
+
+// matchHeaders matches name and every value of each header separately, the
+// same way Mongo query does.
+func (f *Filter) matchHeaders(header map[string][]string) bool {
+	for k, values := range header {
+		if f.re.MatchString(k) {
+			return true
+		}
+		for _, v := range values {
+			if f.re.MatchString(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchBody skips binary bodies, Mongo keeps them as BSON binary which
+// regex can't match.
+func (f *Filter) matchBody(body models.Body) bool {
+	return utf8.Valid(body) && f.re.Match(body)
+}
+
+func quote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'()&|!") {
+		return s
+	}
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`) + `"`
+}
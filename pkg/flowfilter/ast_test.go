@@ -0,0 +1,94 @@
+package flowfilter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+func TestMatch(t *testing.T) {
+	post := &models.RequestInfoWithID{
+		Request: &models.ParsedRequest{
+			Method:   "POST",
+			URL:      "https://api.example.com/login?next=/home",
+			Host:     "api.example.com",
+			Header:   http.Header{"Content-Type": {"application/json"}, "X-Token": {"abc", "def"}},
+			Body:     models.Body(`{"user":"admin"}`),
+			BodyMeta: models.BodyMeta{MimeType: "application/json"},
+		},
+		Response: &models.ParsedResponse{
+			StatusCode: 302,
+			Header:     http.Header{"Location": {"/home"}, "Set-Cookie": {"sid=1"}},
+			Body:       models.Body("Found"),
+			BodyMeta:   models.BodyMeta{MimeType: "text/html"},
+		},
+	}
+	pending := &models.RequestInfoWithID{
+		Request: &models.ParsedRequest{
+			Method: "GET",
+			URL:    "http://static.test/logo.png",
+			Host:   "static.test",
+			Body:   models.Body{0xff, 0xfe, 'x'},
+		},
+	}
+
+	tests := []struct {
+		expr          string
+		post, pending bool
+	}{
+		{"~all", true, true},
+		{"~q", false, true},
+		{"~s", true, false},
+		{"~d example", true, false},
+		{"~d ^EXAMPLE", false, false},
+		{"~d ^api\\.", true, false},
+		{"~m post", true, false},
+		{"~u logo", false, true},
+		{"login", true, false},
+		{"~c 302", true, false},
+		{"~c 200", false, false},
+		{"!~c 200", true, true},
+		{"~h token", true, false},
+		{"~h ^x-token$", true, false},
+		{"~h ^def$", true, false},
+		{`~h "X-Token abc"`, false, false},
+		{"~hq location", false, false},
+		{"~hs location", true, false},
+		{"~hs content-type", false, false},
+		{"~b admin", true, false},
+		{"~bq admin", true, false},
+		{"~bs admin", false, false},
+		{"~bs found", true, false},
+		{"~b x", false, false},
+		{"~t json", true, false},
+		{"~tq html", false, false},
+		{"~ts html", true, false},
+		{"~m get | ~c 302", true, true},
+		{"~m get & ~c 302", false, false},
+		{"!(~q | ~d example)", false, false},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := expr.Match(post); got != tt.post {
+			t.Errorf("%q matches post: %v, want %v", tt.expr, got, tt.post)
+		}
+		if got := expr.Match(pending); got != tt.pending {
+			t.Errorf("%q matches pending: %v, want %v", tt.expr, got, tt.pending)
+		}
+	}
+}
+
+func TestMatchWithoutRequest(t *testing.T) {
+	expr, err := Parse("~all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expr.Match(&models.RequestInfoWithID{}) {
+		t.Error("~all matches exchange without request")
+	}
+}
@@ -0,0 +1,93 @@
+package flowfilter
+
+import "strings"
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	pos    int
+	quoted bool
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src}
+}
+
+func (l *lexer) next() token {
+	for l.pos < len(l.src) && isSpace(l.src[l.pos]) {
+		l.pos++
+	}
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: l.pos}
+	}
+
+	start := l.pos
+	switch c := l.src[l.pos]; c {
+	case '&':
+		l.pos++
+		return token{kind: tokAnd, text: "&", pos: start}
+	case '|':
+		l.pos++
+		return token{kind: tokOr, text: "|", pos: start}
+	case '!':
+		l.pos++
+		return token{kind: tokNot, text: "!", pos: start}
+	case '(':
+		l.pos++
+		return token{kind: tokLParen, text: "(", pos: start}
+	case ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")", pos: start}
+	case '"', '\'':
+		return l.quoted(c)
+	}
+
+	for l.pos < len(l.src) && !isSpace(l.src[l.pos]) && !strings.ContainsRune("&|()", rune(l.src[l.pos])) {
+		l.pos++
+	}
+	return token{kind: tokWord, text: l.src[start:l.pos], pos: start}
+}
+
+// quoted reads quoted value, backslash escapes the quote and itself, other
+// backslashes are kept for regex. Unterminated quote takes the rest of
+// expression.
+func (l *lexer) quoted(quote byte) token {
+	start := l.pos
+	l.pos++
+
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		l.pos++
+		if c == quote {
+			break
+		}
+		if c == '\\' && l.pos < len(l.src) && (l.src[l.pos] == quote || l.src[l.pos] == '\\') {
+			c = l.src[l.pos]
+			l.pos++
+		}
+		b.WriteByte(c)
+	}
+	return token{kind: tokWord, text: b.String(), pos: start, quoted: true}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
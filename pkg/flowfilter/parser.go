@@ -0,0 +1,199 @@
+package flowfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	OpAll            = "~all"
+	OpRequest        = "~q"
+	OpResponse       = "~s"
+	OpDomain         = "~d"
+	OpMethod         = "~m"
+	OpURL            = "~u"
+	OpCode           = "~c"
+	OpHeader         = "~h"
+	OpHeaderRequest  = "~hq"
+	OpHeaderResponse = "~hs"
+	OpBody           = "~b"
+	OpBodyRequest    = "~bq"
+	OpBodyResponse   = "~bs"
+	OpType           = "~t"
+	OpTypeRequest    = "~tq"
+	OpTypeResponse   = "~ts"
+)
+
+type opInfo struct {
+	part     Part
+	hasValue bool
+}
+
+var ops = map[string]opInfo{
+	OpAll:            {},
+	OpRequest:        {},
+	OpResponse:       {},
+	OpDomain:         {hasValue: true},
+	OpMethod:         {hasValue: true},
+	OpURL:            {hasValue: true},
+	OpCode:           {hasValue: true},
+	OpHeader:         {part: PartAny, hasValue: true},
+	OpHeaderRequest:  {part: PartRequest, hasValue: true},
+	OpHeaderResponse: {part: PartResponse, hasValue: true},
+	OpBody:           {part: PartAny, hasValue: true},
+	OpBodyRequest:    {part: PartRequest, hasValue: true},
+	OpBodyResponse:   {part: PartResponse, hasValue: true},
+	OpType:           {part: PartAny, hasValue: true},
+	OpTypeRequest:    {part: PartRequest, hasValue: true},
+	OpTypeResponse:   {part: PartResponse, hasValue: true},
+}
+
+// Parse parses mitmproxy-like filter expression, e.g.
+//
+//	~d example.com & ~m POST & !(~c 200 | ~c 302)
+//
+// Operators are "!", "&" and "|" in order of precedence, filters written next
+// to each other are joined with "&". A bare word is matched against URL.
+// Regex values are case-insensitive, values with spaces, operators or
+// parentheses have to be quoted.
+func Parse(s string) (Expr, error) {
+	p := &parser{lex: newLexer(s)}
+	p.next()
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", p.tok.text, p.tok.pos)
+	}
+	return expr, nil
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func (p *parser) next() {
+	p.tok = p.lex.next()
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.tok.kind {
+		case tokAnd:
+			p.next()
+		case tokNot, tokLParen, tokWord:
+			// implicit and
+		default:
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if p.tok.kind == tokNot {
+		p.next()
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Expr: expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	switch p.tok.kind {
+	case tokLParen:
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, fmt.Errorf("missing ) at %d", p.tok.pos)
+		}
+		p.next()
+		return expr, nil
+	case tokWord:
+		return p.parseFilter()
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d", p.tok.text, p.tok.pos)
+	}
+}
+
+func (p *parser) parseFilter() (Expr, error) {
+	word := p.tok
+	p.next()
+
+	if word.quoted || !strings.HasPrefix(word.text, "~") {
+		return newFilter(OpURL, PartAny, word.text)
+	}
+
+	op := strings.ToLower(word.text)
+	info, ok := ops[op]
+	if !ok {
+		return nil, fmt.Errorf("unknown filter %s at %d", word.text, word.pos)
+	}
+	if !info.hasValue {
+		return &Filter{Op: op}, nil
+	}
+
+	if p.tok.kind != tokWord {
+		return nil, fmt.Errorf("filter %s needs a value at %d", op, p.tok.pos)
+	}
+	value := p.tok.text
+	p.next()
+
+	return newFilter(op, info.part, value)
+}
+
+func newFilter(op string, part Part, value string) (*Filter, error) {
+	f := &Filter{Op: op, Part: part, Pattern: value}
+	if op == OpCode {
+		code, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("wrong status code %q", value)
+		}
+		f.Code = code
+		return f, nil
+	}
+
+	re, err := regexp.Compile("(?i)" + value)
+	if err != nil {
+		return nil, fmt.Errorf("wrong regex %q: %v", value, err)
+	}
+	f.re = re
+	return f, nil
+}
@@ -0,0 +1,108 @@
+package flowfilter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"~all", "~all"},
+		{"~q", "~q"},
+		{"~d example.com", "~d example.com"},
+		{"~D example.com", "~d example.com"},
+		{"~c 200", "~c 200"},
+		{"example", "~u example"},
+		{`"~d"`, "~u ~d"},
+		{`~b "a b"`, `~b "a b"`},
+		{`~b 'it\'s'`, `~b "it's"`},
+		{`~u "a\\b"`, `~u a\b`},
+		{`~u "\d+"`, `~u \d+`},
+		{"~d a & ~m POST", "(~d a & ~m POST)"},
+		{"~d a ~m POST", "(~d a & ~m POST)"},
+		{"~d a | ~d b & ~s", "(~d a | (~d b & ~s))"},
+		{"~d a & ~d b | ~s", "((~d a & ~d b) | ~s)"},
+		{"!~s", "!~s"},
+		{"!!~s", "!!~s"},
+		{"!~c 200 & ~s", "(!~c 200 & ~s)"},
+		{"~d a & !(~c 200 | ~c 302)", "(~d a & !(~c 200 | ~c 302))"},
+		{" ( ~q ) ", "~q"},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := expr.String(); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.expr, got, tt.want)
+		}
+		// String is a valid expression with the same meaning
+		again, err := Parse(expr.String())
+		if err != nil {
+			t.Errorf("Parse(%q): %v", expr.String(), err)
+		} else if again.String() != expr.String() {
+			t.Errorf("Parse(%q) = %s", expr.String(), again)
+		}
+	}
+}
+
+func TestParseParts(t *testing.T) {
+	tests := []struct {
+		expr string
+		part Part
+	}{
+		{"~h x", PartAny},
+		{"~hq x", PartRequest},
+		{"~hs x", PartResponse},
+		{"~b x", PartAny},
+		{"~bq x", PartRequest},
+		{"~bs x", PartResponse},
+		{"~t x", PartAny},
+		{"~tq x", PartRequest},
+		{"~ts x", PartResponse},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if f := expr.(*Filter); f.Part != tt.part {
+			t.Errorf("Parse(%q) part = %d, want %d", tt.expr, f.Part, tt.part)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{"", "unexpected end"},
+		{"~d", "needs a value"},
+		{"~d &", "needs a value"},
+		{"~x a", "unknown filter"},
+		{"~c ok", "wrong status code"},
+		{"~u (", "needs a value"},
+		{"~u \"(\"", "wrong regex"},
+		{"(~q", "missing )"},
+		{"~q)", "unexpected \")\""},
+		{"~q &", "unexpected end"},
+		{"| ~q", "unexpected \"|\""},
+		{"!", "unexpected end"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		if err == nil {
+			t.Errorf("Parse(%q): no error", tt.expr)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Parse(%q) error = %q, want %q", tt.expr, err, tt.err)
+		}
+	}
+}
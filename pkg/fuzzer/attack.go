@@ -0,0 +1,112 @@
+package fuzzer
+
+import (
+	"fmt"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+// Item is one request of the attack, Payloads[i] goes to point Positions[i].
+type Item struct {
+	Index     int
+	Positions []int
+	Payloads  []string
+}
+
+// Attack enumerates requests of the attack mode without building them
+// all at once.
+type Attack struct {
+	mode   string
+	points int
+	sets   []Payloads
+	total  int
+}
+
+func NewAttack(mode string, points int, sets []*models.PayloadSet) (*Attack, error) {
+	if points == 0 {
+		return nil, fmt.Errorf("no insertion points")
+	}
+
+	a := &Attack{mode: mode, points: points}
+	for _, set := range sets {
+		if set == nil {
+			return nil, fmt.Errorf("payload set is empty")
+		}
+		p, err := NewPayloads(set)
+		if err != nil {
+			return nil, err
+		}
+		a.sets = append(a.sets, p)
+	}
+
+	switch mode {
+	case models.FuzzSniper, models.FuzzBatteringRam:
+		if len(a.sets) != 1 {
+			return nil, fmt.Errorf("%s attack needs one payload set", mode)
+		}
+		a.total = a.sets[0].Len()
+		if mode == models.FuzzSniper {
+			a.total *= points
+		}
+	case models.FuzzPitchfork, models.FuzzClusterBomb:
+		if len(a.sets) != points {
+			return nil, fmt.Errorf("%s attack needs payload set for every point", mode)
+		}
+		a.total = a.sets[0].Len()
+		for _, set := range a.sets[1:] {
+			if mode == models.FuzzPitchfork {
+				a.total = min(a.total, set.Len())
+			} else {
+				a.total *= set.Len()
+			}
+			if a.total > MaxRequests {
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown attack mode %q", mode)
+	}
+
+	if a.total > MaxRequests {
+		return nil, fmt.Errorf("attack has more than %d requests", MaxRequests)
+	}
+	return a, nil
+}
+
+// Total returns the number of requests of the attack.
+func (a *Attack) Total() int {
+	return a.total
+}
+
+// Item returns i-th request of the attack. Sniper goes through the payloads
+// for each point in turn, cluster bomb changes the last point fastest.
+func (a *Attack) Item(i int) *Item {
+	item := &Item{Index: i}
+	switch a.mode {
+	case models.FuzzSniper:
+		n := a.sets[0].Len()
+		item.Positions = []int{i / n}
+		item.Payloads = []string{a.sets[0].At(i % n)}
+	case models.FuzzBatteringRam:
+		payload := a.sets[0].At(i)
+		for p := range a.points {
+			item.Positions = append(item.Positions, p)
+			item.Payloads = append(item.Payloads, payload)
+		}
+	case models.FuzzPitchfork:
+		for p, set := range a.sets {
+			item.Positions = append(item.Positions, p)
+			item.Payloads = append(item.Payloads, set.At(i))
+		}
+	case models.FuzzClusterBomb:
+		item.Positions = make([]int, a.points)
+		item.Payloads = make([]string, a.points)
+		for p := a.points - 1; p >= 0; p-- {
+			n := a.sets[p].Len()
+			item.Positions[p] = p
+			item.Payloads[p] = a.sets[p].At(i % n)
+			i /= n
+		}
+	}
+	return item
+}
@@ -0,0 +1,204 @@
+package fuzzer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/decoder"
+)
+
+const (
+	DefaultConcurrency = 5
+	MaxConcurrency     = 50
+	DefaultTimeout     = 10 * time.Second
+)
+
+// Exchange is the sent request with its response, both bodies can be read
+// again.
+type Exchange struct {
+	Request  *http.Request
+	Response *http.Response
+	Timings  *models.Timings
+}
+
+type Engine struct {
+	client      *http.Client
+	concurrency int
+	grep        []*regexp.Regexp
+}
+
+// NewEngine compiles grep expressions and prepares client which neither
+// follows redirects nor checks certificates, like the repeater.
+func NewEngine(concurrency int, timeout time.Duration, grep []string) (*Engine, error) {
+	e := &Engine{concurrency: concurrency}
+	if e.concurrency <= 0 {
+		e.concurrency = DefaultConcurrency
+	}
+	if e.concurrency > MaxConcurrency {
+		return nil, fmt.Errorf("concurrency must be at most %d", MaxConcurrency)
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	for _, expr := range grep {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("wrong grep expression %q: %v", expr, err)
+		}
+		e.grep = append(e.grep, re)
+	}
+
+	e.client = &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Transport: &http.Transport{
+			TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+			MaxIdleConnsPerHost: e.concurrency,
+		},
+	}
+	return e, nil
+}
+
+// Run sends every request of the attack and calls fn with its result. fn is
+// called from several goroutines, exchange is nil when the request failed.
+// Requests interrupted by ctx are not reported, Run returns ctx error then.
+func (e *Engine) Run(ctx context.Context, base *models.ParsedRequest, points []*models.InsertionPoint, attack *Attack, fn func(*models.FuzzResult, *Exchange)) error {
+	defer e.client.CloseIdleConnections()
+
+	items := make(chan int)
+	var wg sync.WaitGroup
+	for range e.concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range items {
+				item := attack.Item(i)
+				res, ex := e.send(ctx, base, points, item)
+				if ctx.Err() != nil {
+					continue
+				}
+				fn(res, ex)
+			}
+		}()
+	}
+
+loop:
+	for i := range attack.Total() {
+		select {
+		case items <- i:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(items)
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+func (e *Engine) send(ctx context.Context, base *models.ParsedRequest, points []*models.InsertionPoint, item *Item) (*models.FuzzResult, *Exchange) {
+	res := &models.FuzzResult{
+		Index:     item.Index,
+		Positions: item.Positions,
+		Payloads:  item.Payloads,
+	}
+
+	parsedReq, err := Inject(base, points, item)
+	if err != nil {
+		res.Error = err.Error()
+		return res, nil
+	}
+	req, err := NewRequest(ctx, parsedReq)
+	if err != nil {
+		res.Error = err.Error()
+		return res, nil
+	}
+
+	timings := &models.Timings{StartedAt: time.Now()}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		res.Error = err.Error()
+		res.TimeMs = time.Since(timings.StartedAt).Milliseconds()
+		return res, nil
+	}
+	defer resp.Body.Close()
+	timings.Wait = time.Since(timings.StartedAt)
+
+	body, err := io.ReadAll(resp.Body)
+	timings.Receive = time.Since(timings.StartedAt) - timings.Wait
+	res.StatusCode = resp.StatusCode
+	res.Length = int64(len(body))
+	res.TimeMs = time.Since(timings.StartedAt).Milliseconds()
+	if err != nil {
+		res.Error = err.Error()
+		return res, nil
+	}
+	res.Matches = e.match(resp.Header, body)
+
+	// bodies are consumed, they are needed again to store the exchange
+	req.Body = io.NopCloser(bytes.NewReader(parsedReq.Body))
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return res, &Exchange{Request: req, Response: resp, Timings: timings}
+}
+
+// match searches grep expressions in headers and decoded body.
+func (e *Engine) match(header http.Header, body []byte) []string {
+	if len(e.grep) == 0 {
+		return nil
+	}
+
+	if decoded, err := decoder.DecodeBody(body, header.Get("Content-Encoding")); err == nil {
+		body = decoded
+	}
+	var text strings.Builder
+	for name, values := range header {
+		for _, v := range values {
+			text.WriteString(name + ": " + v + "\n")
+		}
+	}
+	text.WriteString("\n")
+	text.Write(body)
+
+	var matches []string
+	for _, re := range e.grep {
+		if re.MatchString(text.String()) {
+			matches = append(matches, re.String())
+		}
+	}
+	return matches
+}
+
+// NewRequest builds request to send from the stored one, Host header
+// edits are kept.
+func NewRequest(ctx context.Context, parsedReq *models.ParsedRequest) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, parsedReq.Method, parsedReq.URL, bytes.NewReader(parsedReq.Body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = parsedReq.Header.Clone()
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	if parsedReq.Host != "" {
+		req.Host = parsedReq.Host
+	}
+	if req.Header.Get("Cookie") == "" {
+		for _, cookie := range parsedReq.Cookies {
+			req.AddCookie(cookie)
+		}
+	}
+	return req, nil
+}
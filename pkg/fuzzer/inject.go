@@ -0,0 +1,133 @@
+package fuzzer
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+// Inject returns copy of request with item payloads put into the points.
+// Values are replaced, not appended to, and missing parameters, headers
+// and cookies are added. Everything else in the request is kept byte for
+// byte, so responses differ only because of the payloads.
+func Inject(req *models.ParsedRequest, points []*models.InsertionPoint, item *Item) (*models.ParsedRequest, error) {
+	for i, pos := range item.Positions {
+		if pos < 0 || pos >= len(points) {
+			return nil, fmt.Errorf("wrong position %d", pos)
+		}
+		edit, err := pointEdit(req, points[pos], item.Payloads[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s point %q: %v", points[pos].Type, points[pos].Name, err)
+		}
+		if req, err = edit.Apply(req); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+func pointEdit(req *models.ParsedRequest, point *models.InsertionPoint, payload string) (*models.RequestEdit, error) {
+	switch point.Type {
+	case models.PointQuery:
+		u, err := url.Parse(req.URL)
+		if err != nil {
+			return nil, err
+		}
+		u.RawQuery = setParam(u.RawQuery, point.Name, payload)
+		s := u.String()
+		return &models.RequestEdit{URL: &s}, nil
+	case models.PointForm:
+		if len(req.Body) > 0 && req.BodyMeta.MimeType != "application/x-www-form-urlencoded" {
+			return nil, fmt.Errorf("request body is not a form")
+		}
+		body := setParam(string(req.Body), point.Name, payload)
+		return &models.RequestEdit{Body: &body}, nil
+	case models.PointJSON:
+		body, err := setJSON(req.Body, point.Name, payload)
+		if err != nil {
+			return nil, err
+		}
+		s := string(body)
+		return &models.RequestEdit{Body: &s}, nil
+	case models.PointXML:
+		body, err := setXML(req.Body, point.Name, payload)
+		if err != nil {
+			return nil, err
+		}
+		s := string(body)
+		return &models.RequestEdit{Body: &s}, nil
+	case models.PointHeader:
+		if point.Name == "" {
+			return nil, fmt.Errorf("header name is required")
+		}
+		return &models.RequestEdit{Headers: map[string][]string{point.Name: {payload}}}, nil
+	case models.PointCookie:
+		if point.Name == "" {
+			return nil, fmt.Errorf("cookie name is required")
+		}
+		return &models.RequestEdit{Cookies: map[string]*string{point.Name: &payload}}, nil
+	case models.PointPath:
+		s, err := setPathSegment(req.URL, point.Name, payload)
+		if err != nil {
+			return nil, err
+		}
+		return &models.RequestEdit{URL: &s}, nil
+	}
+	return nil, fmt.Errorf("unknown point type")
+}
+
+// setParam replaces value of the first name parameter in urlencoded
+// string, keeping order and encoding of the others, or appends it.
+func setParam(raw, name, value string) string {
+	pair := url.QueryEscape(name) + "=" + url.QueryEscape(value)
+	if raw == "" {
+		return pair
+	}
+
+	params := strings.Split(raw, "&")
+	for i, p := range params {
+		key, _, _ := strings.Cut(p, "=")
+		if k, err := url.QueryUnescape(key); err == nil && k == name {
+			params[i] = key + "=" + url.QueryEscape(value)
+			return strings.Join(params, "&")
+		}
+	}
+	return raw + "&" + pair
+}
+
+// setPathSegment replaces path segment with index name, counting from the
+// first segment after the leading slash. Value goes raw, so slashes and
+// encodings of payloads reach the server as written. Characters which
+// can't be in a path are escaped by the URL.
+func setPathSegment(rawURL, name, value string) (string, error) {
+	n, err := strconv.Atoi(name)
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("path point name must be segment index")
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	segments := strings.Split(strings.TrimPrefix(u.EscapedPath(), "/"), "/")
+	if n >= len(segments) {
+		return "", fmt.Errorf("path has only %d segments", len(segments))
+	}
+	segments[n] = value
+
+	escaped := "/" + strings.Join(segments, "/")
+	path, err := url.PathUnescape(escaped)
+	if err != nil {
+		// a stray percent sign is not an encoding
+		segments[n] = url.PathEscape(value)
+		escaped = "/" + strings.Join(segments, "/")
+		if path, err = url.PathUnescape(escaped); err != nil {
+			return "", err
+		}
+	}
+	u.Path, u.RawPath = path, escaped
+	return u.String(), nil
+}
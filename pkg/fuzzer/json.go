@@ -0,0 +1,235 @@
+package fuzzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// setJSON puts payload at path of JSON document, the rest of the document
+// is copied as is. Missing key of an existing object is added. Payload
+// stays a number, boolean or null if it replaces one and is valid as such,
+// otherwise it is inserted as a string.
+func setJSON(data []byte, path, payload string) ([]byte, error) {
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("request body is not JSON")
+	}
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sp, err := locate(data, skipWS(data, 0), segments)
+	if err != nil {
+		return nil, err
+	}
+
+	value := quoteJSON(payload)
+	if sp.key == "" && isScalar(data[sp.start:sp.end]) && isScalar([]byte(payload)) && json.Valid([]byte(payload)) {
+		value = payload
+	}
+	if sp.key != "" {
+		value = quoteJSON(sp.key) + ":" + value
+		if sp.comma {
+			value = "," + value
+		}
+	}
+
+	res := make([]byte, 0, len(data)+len(value))
+	res = append(res, data[:sp.start]...)
+	res = append(res, value...)
+	return append(res, data[sp.end:]...), nil
+}
+
+// parsePath parses paths like $.user["first name"].ids[0], the same form
+// diff reports them in. Keys are strings, indexes are ints.
+func parsePath(path string) ([]any, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("json path must start with $")
+	}
+
+	var segments []any
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty key in json path %q", path)
+			}
+			segments = append(segments, rest[1:end+1])
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if len(rest) > 1 && rest[1] == '"' {
+				strEnd, err := skipString([]byte(rest), 1)
+				if err != nil {
+					return nil, fmt.Errorf("wrong key in json path %q", path)
+				}
+				end = strEnd
+				if end >= len(rest) || rest[end] != ']' {
+					return nil, fmt.Errorf("wrong key in json path %q", path)
+				}
+				var key string
+				if err := json.Unmarshal([]byte(rest[1:end]), &key); err != nil {
+					return nil, fmt.Errorf("wrong key in json path %q", path)
+				}
+				segments = append(segments, key)
+			} else {
+				if end < 0 {
+					return nil, fmt.Errorf("unclosed index in json path %q", path)
+				}
+				n, err := strconv.Atoi(rest[1:end])
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("wrong index in json path %q", path)
+				}
+				segments = append(segments, n)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("wrong json path %q", path)
+		}
+	}
+	return segments, nil
+}
+
+// span is the part of document to replace. Non-empty key means the value
+// is inserted as a new member of the object before its closing brace.
+type span struct {
+	start, end int
+	key        string
+	comma      bool
+}
+
+func locate(data []byte, i int, segments []any) (*span, error) {
+	if len(segments) == 0 {
+		end, err := skipValue(data, i)
+		if err != nil {
+			return nil, err
+		}
+		return &span{start: i, end: end}, nil
+	}
+
+	switch seg := segments[0].(type) {
+	case string:
+		if data[i] != '{' {
+			return nil, fmt.Errorf("key %q of a non-object", seg)
+		}
+		members := 0
+		for i = skipWS(data, i+1); data[i] != '}'; members++ {
+			keyEnd, err := skipString(data, i)
+			if err != nil {
+				return nil, err
+			}
+			var key string
+			if err := json.Unmarshal(data[i:keyEnd], &key); err != nil {
+				return nil, err
+			}
+			// skip ':'
+			i = skipWS(data, skipWS(data, keyEnd)+1)
+			if key == seg {
+				return locate(data, i, segments[1:])
+			}
+			if i, err = skipValue(data, i); err != nil {
+				return nil, err
+			}
+			if i = skipWS(data, i); data[i] == ',' {
+				i = skipWS(data, i+1)
+			}
+		}
+		if len(segments) > 1 {
+			return nil, fmt.Errorf("key %q not found", seg)
+		}
+		return &span{start: i, end: i, key: seg, comma: members > 0}, nil
+	case int:
+		if data[i] != '[' {
+			return nil, fmt.Errorf("index %d of a non-array", seg)
+		}
+		for n, i := 0, skipWS(data, i+1); data[i] != ']'; n++ {
+			if n == seg {
+				return locate(data, i, segments[1:])
+			}
+			var err error
+			if i, err = skipValue(data, i); err != nil {
+				return nil, err
+			}
+			if i = skipWS(data, i); data[i] == ',' {
+				i = skipWS(data, i+1)
+			}
+		}
+		return nil, fmt.Errorf("index %d out of range", seg)
+	}
+	return nil, fmt.Errorf("wrong json path")
+}
+
+// skipValue returns the end of the value starting at i. Document is
+// already known to be valid.
+func skipValue(data []byte, i int) (int, error) {
+	switch data[i] {
+	case '"':
+		return skipString(data, i)
+	case '{', '[':
+		depth := 0
+		for i < len(data) {
+			switch data[i] {
+			case '"':
+				end, err := skipString(data, i)
+				if err != nil {
+					return 0, err
+				}
+				i = end
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1, nil
+				}
+			}
+			i++
+		}
+		return 0, fmt.Errorf("unexpected end of json")
+	default:
+		for i < len(data) && !bytes.ContainsRune([]byte(",}] \t\r\n"), rune(data[i])) {
+			i++
+		}
+		return i, nil
+	}
+}
+
+func skipString(data []byte, i int) (int, error) {
+	for j := i + 1; j < len(data); j++ {
+		switch data[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated string")
+}
+
+func skipWS(data []byte, i int) int {
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t' || data[i] == '\r' || data[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+func isScalar(value []byte) bool {
+	return len(value) > 0 && value[0] != '"' && value[0] != '{' && value[0] != '['
+}
+
+func quoteJSON(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,177 @@
+package fuzzer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+const (
+	// MaxRequests limits the number of requests of one attack.
+	MaxRequests = 100000
+
+	maxBruteforceLength = 8
+)
+
+// Payloads is a payload set which is generated on demand, so big ranges
+// and combinations are never kept in memory.
+type Payloads interface {
+	Len() int
+	At(i int) string
+}
+
+func NewPayloads(set *models.PayloadSet) (Payloads, error) {
+	var p Payloads
+	var err error
+	switch set.Type {
+	case models.PayloadList, "":
+		p, err = newList(set)
+	case models.PayloadNumbers:
+		p, err = newNumbers(set)
+	case models.PayloadBruteforce:
+		p, err = newBruteforce(set)
+	default:
+		return nil, fmt.Errorf("unknown payload type %q", set.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch set.Encode {
+	case "":
+		return p, nil
+	case "url", "base64":
+		return &encoded{Payloads: p, encoding: set.Encode}, nil
+	default:
+		return nil, fmt.Errorf("unknown payload encoding %q", set.Encode)
+	}
+}
+
+type list []string
+
+func newList(set *models.PayloadSet) (list, error) {
+	if len(set.Values) == 0 {
+		return nil, fmt.Errorf("payload list is empty")
+	}
+	if len(set.Values) > MaxRequests {
+		return nil, fmt.Errorf("payload list is longer than %d", MaxRequests)
+	}
+	return list(set.Values), nil
+}
+
+func (l list) Len() int        { return len(l) }
+func (l list) At(i int) string { return l[i] }
+
+type numbers struct {
+	from, step int64
+	count      int
+	format     string
+}
+
+func newNumbers(set *models.PayloadSet) (*numbers, error) {
+	step := set.Step
+	if step == 0 {
+		step = 1
+		if set.To < set.From {
+			step = -1
+		}
+	}
+	if (set.To-set.From)/step < 0 {
+		return nil, fmt.Errorf("step %d never reaches %d from %d", step, set.To, set.From)
+	}
+
+	count := (set.To-set.From)/step + 1
+	if count > MaxRequests {
+		return nil, fmt.Errorf("number range is longer than %d", MaxRequests)
+	}
+
+	n := &numbers{from: set.From, step: step, count: int(count), format: set.Format}
+	if n.format == "" {
+		n.format = "%d"
+	}
+	if s := n.At(0); strings.Contains(s, "%!") {
+		return nil, fmt.Errorf("wrong number format %q", set.Format)
+	}
+	return n, nil
+}
+
+func (n *numbers) Len() int { return n.count }
+
+func (n *numbers) At(i int) string {
+	return fmt.Sprintf(n.format, n.from+int64(i)*n.step)
+}
+
+// bruteforce enumerates strings of charset, shorter ones first.
+type bruteforce struct {
+	charset []rune
+	min     int
+	count   int
+}
+
+func newBruteforce(set *models.PayloadSet) (*bruteforce, error) {
+	charset := []rune(set.Charset)
+	if len(charset) == 0 {
+		return nil, fmt.Errorf("bruteforce charset is empty")
+	}
+	minLen, maxLen := max(set.MinLength, 1), set.MaxLength
+	if maxLen == 0 {
+		maxLen = minLen
+	}
+	if maxLen < minLen || maxLen > maxBruteforceLength {
+		return nil, fmt.Errorf("bruteforce length must be from 1 to %d", maxBruteforceLength)
+	}
+
+	count := 0
+	for l := minLen; l <= maxLen; l++ {
+		n := 1
+		for range l {
+			n *= len(charset)
+			if n > MaxRequests {
+				return nil, fmt.Errorf("bruteforce produces more than %d payloads", MaxRequests)
+			}
+		}
+		count += n
+	}
+	if count > MaxRequests {
+		return nil, fmt.Errorf("bruteforce produces more than %d payloads", MaxRequests)
+	}
+
+	return &bruteforce{charset: charset, min: minLen, count: count}, nil
+}
+
+func (b *bruteforce) Len() int { return b.count }
+
+func (b *bruteforce) At(i int) string {
+	l, n := b.min, 1
+	for range l {
+		n *= len(b.charset)
+	}
+	for i >= n {
+		i -= n
+		l++
+		n *= len(b.charset)
+	}
+
+	res := make([]rune, l)
+	for j := l - 1; j >= 0; j-- {
+		res[j] = b.charset[i%len(b.charset)]
+		i /= len(b.charset)
+	}
+	return string(res)
+}
+
+type encoded struct {
+	Payloads
+	encoding string
+}
+
+func (e *encoded) At(i int) string {
+	s := e.Payloads.At(i)
+	if e.encoding == "base64" {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+	return url.QueryEscape(s)
+}
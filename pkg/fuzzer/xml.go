@@ -0,0 +1,268 @@
+package fuzzer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// entityRefRe matches character and entity references.
+	entityRefRe  = regexp.MustCompile(`&(#[0-9]+|#x[0-9a-fA-F]+|[A-Za-z_:][\w.:-]*);`)
+	firstIndexRe = regexp.MustCompile(`\[1\](/|$)`)
+)
+
+// XMLValue is text of an element without child elements or value of an
+// attribute. Path is like /order/item[2]/@id, index of the first element
+// of the name is omitted.
+type XMLValue struct {
+	Path  string
+	Value string
+}
+
+// xmlNode is XMLValue with its place in the document. Start and end are
+// the bounds of the raw value, of the "/>" for a self-closing element.
+type xmlNode struct {
+	XMLValue
+	name        string
+	start, end  int
+	attr        bool
+	selfClosing bool
+}
+
+// XMLValues lists values of the document in document order.
+func XMLValues(data []byte) ([]XMLValue, error) {
+	nodes, err := xmlNodes(data)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]XMLValue, len(nodes))
+	for i, n := range nodes {
+		values[i] = n.XMLValue
+	}
+	return values, nil
+}
+
+// setXML puts payload at path of XML document, the rest of the document is
+// copied as is. Payload which is well-formed markup, like an entity
+// reference, is inserted as such, otherwise it is escaped.
+func setXML(data []byte, path, payload string) ([]byte, error) {
+	nodes, err := xmlNodes(data)
+	if err != nil {
+		return nil, err
+	}
+	var node *xmlNode
+	for _, n := range nodes {
+		if n.Path == normalizeXPath(path) {
+			node = n
+			break
+		}
+	}
+	if node == nil {
+		return nil, fmt.Errorf("node %q not found", path)
+	}
+
+	var value string
+	switch {
+	case node.attr && !strings.ContainsAny(entityRefRe.ReplaceAllString(payload, ""), "<&\"'"):
+		value = payload
+	case node.attr:
+		value = escapeXML(payload)
+	case wellFormed(payload):
+		value = payload
+	default:
+		value = escapeXML(payload)
+	}
+	if node.selfClosing {
+		value = ">" + value + "</" + node.name + ">"
+	}
+
+	res := make([]byte, 0, len(data)+len(value))
+	res = append(res, data[:node.start]...)
+	res = append(res, value...)
+	return append(res, data[node.end:]...), nil
+}
+
+// xmlNodes walks raw tokens, so prefixes of names stay as they are written.
+func xmlNodes(data []byte) ([]*xmlNode, error) {
+	type frame struct {
+		path   string
+		counts map[string]int
+		leaf   *xmlNode
+	}
+	stack := []*frame{{counts: make(map[string]int)}}
+
+	var nodes []*xmlNode
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		offset := int(dec.InputOffset())
+		token, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("request body is not XML: %v", err)
+		}
+
+		top := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			// the parent has children, its text is not a value
+			if top.leaf != nil {
+				top.leaf.start, top.leaf = -1, nil
+			}
+			name := xmlName(t.Name)
+			top.counts[name]++
+			path := top.path + "/" + name
+			if n := top.counts[name]; n > 1 {
+				path += "[" + strconv.Itoa(n) + "]"
+			}
+
+			end := int(dec.InputOffset())
+			tag := data[offset:end]
+			for _, a := range attrSpans(tag) {
+				a.Path = path + "/@" + a.name
+				a.start += offset
+				a.end += offset
+				nodes = append(nodes, a)
+			}
+
+			leaf := &xmlNode{XMLValue: XMLValue{Path: path}, name: name, start: end, end: end}
+			if bytes.HasSuffix(tag, []byte("/>")) {
+				leaf.start, leaf.selfClosing = end-2, true
+			}
+			nodes = append(nodes, leaf)
+			stack = append(stack, &frame{path: path, counts: make(map[string]int), leaf: leaf})
+		case xml.CharData:
+			if top.leaf != nil {
+				top.leaf.Value += string(t)
+			}
+		case xml.EndElement:
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("request body is not XML: unexpected end element")
+			}
+			if top.leaf != nil && !top.leaf.selfClosing {
+				top.leaf.end = offset
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) != 1 || len(nodes) == 0 {
+		return nil, fmt.Errorf("request body is not XML")
+	}
+
+	// elements with children were added before their children were seen
+	values := nodes[:0]
+	for _, n := range nodes {
+		if n.start >= 0 {
+			values = append(values, n)
+		}
+	}
+	return values, nil
+}
+
+// attrSpans finds values of attributes in the raw start tag.
+func attrSpans(tag []byte) []*xmlNode {
+	var res []*xmlNode
+	i := 1
+	for i < len(tag) && !isXMLSpace(tag[i]) && tag[i] != '>' && tag[i] != '/' {
+		i++
+	}
+	for {
+		for i < len(tag) && isXMLSpace(tag[i]) {
+			i++
+		}
+		start := i
+		for i < len(tag) && tag[i] != '=' && !isXMLSpace(tag[i]) && tag[i] != '>' && tag[i] != '/' {
+			i++
+		}
+		if i == start {
+			return res
+		}
+		name := string(tag[start:i])
+		for i < len(tag) && (isXMLSpace(tag[i]) || tag[i] == '=') {
+			i++
+		}
+		if i == len(tag) || tag[i] != '"' && tag[i] != '\'' {
+			return res
+		}
+		end := bytes.IndexByte(tag[i+1:], tag[i])
+		if end < 0 {
+			return res
+		}
+		raw := string(tag[i+1 : i+1+end])
+		value, err := unescapeXML(raw)
+		if err != nil {
+			value = raw
+		}
+		// namespace declarations are not data
+		if name != "xmlns" && !strings.HasPrefix(name, "xmlns:") {
+			res = append(res, &xmlNode{
+				XMLValue: XMLValue{Value: value},
+				name:     name,
+				start:    i + 1,
+				end:      i + 1 + end,
+				attr:     true,
+			})
+		}
+		i += end + 2
+	}
+}
+
+// normalizeXPath drops index [1], so both forms find the first element.
+func normalizeXPath(path string) string {
+	return firstIndexRe.ReplaceAllString(path, "$1")
+}
+
+// wellFormed tells whether s is valid element content, undeclared entity
+// references are allowed.
+func wellFormed(s string) bool {
+	s = entityRefRe.ReplaceAllString(s, "")
+	if !strings.ContainsAny(s, "<&") {
+		return true
+	}
+	dec := xml.NewDecoder(strings.NewReader("<x>" + s + "</x>"))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			return true
+		} else if err != nil {
+			return false
+		}
+	}
+}
+
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func unescapeXML(s string) (string, error) {
+	dec := xml.NewDecoder(strings.NewReader("<x>" + s + "</x>"))
+	var out strings.Builder
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return out.String(), nil
+		} else if err != nil {
+			return "", err
+		}
+		if data, ok := token.(xml.CharData); ok {
+			out.Write(data)
+		}
+	}
+}
+
+func xmlName(n xml.Name) string {
+	if n.Space != "" {
+		return n.Space + ":" + n.Local
+	}
+	return n.Local
+}
+
+func isXMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
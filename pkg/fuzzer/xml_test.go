@@ -0,0 +1,100 @@
+package fuzzer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestXMLValues(t *testing.T) {
+	doc := `<?xml version="1.0"?>
+<s:order xmlns:s="urn:shop" id="7">
+	<s:item sku='a'>1</s:item>
+	<s:item sku="b&amp;c"/>
+	<note>x &amp; y</note>
+	<empty></empty>
+</s:order>`
+	want := []XMLValue{
+		{"/s:order/@id", "7"},
+		{"/s:order/s:item/@sku", "a"},
+		{"/s:order/s:item", "1"},
+		{"/s:order/s:item[2]/@sku", "b&c"},
+		{"/s:order/s:item[2]", ""},
+		{"/s:order/note", "x & y"},
+		{"/s:order/empty", ""},
+	}
+	got, err := XMLValues([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for _, doc := range []string{"", "plain text", "<a><b></a>", "<a></a></b>", "<a>"} {
+		if _, err := XMLValues([]byte(doc)); err == nil {
+			t.Errorf("%q is taken for XML", doc)
+		}
+	}
+}
+
+func TestSetXML(t *testing.T) {
+	tests := []struct {
+		doc, path, payload string
+		want               string
+	}{
+		{`<a><b>1</b><c>2</c></a>`, "/a/c", "x", `<a><b>1</b><c>x</c></a>`},
+		{`<a><b>1</b></a>`, "/a/b", "", `<a><b></b></a>`},
+		{`<a><i>1</i><i>2</i><i>3</i></a>`, "/a/i[2]", "x", `<a><i>1</i><i>x</i><i>3</i></a>`},
+		{`<a><i>1</i><i>2</i></a>`, "/a/i[1]", "x", `<a><i>x</i><i>2</i></a>`},
+		{`<a><i><j>1</j></i><i><j>2</j></i></a>`, "/a/i[2]/j", "x", `<a><i><j>1</j></i><i><j>x</j></i></a>`},
+		{`<?xml version="1.0"?><a>1</a>`, "/a", "x", `<?xml version="1.0"?><a>x</a>`},
+		{`<s:a xmlns:s="urn:x"><s:b>1</s:b></s:a>`, "/s:a/s:b", "x", `<s:a xmlns:s="urn:x"><s:b>x</s:b></s:a>`},
+
+		// markup and entity references are inserted as they are
+		{`<a><b>1</b></a>`, "/a/b", "&xxe;", `<a><b>&xxe;</b></a>`},
+		{`<a><b>1</b></a>`, "/a/b", "1&#39;&lt;", `<a><b>1&#39;&lt;</b></a>`},
+		{`<a><b>1</b></a>`, "/a/b", "<c>2</c>", `<a><b><c>2</c></b></a>`},
+		{`<a><b>1</b></a>`, "/a/b", "1' OR <x", `<a><b>1&#39; OR &lt;x</b></a>`},
+		{`<a><b>1</b></a>`, "/a/b", "a & b", `<a><b>a &amp; b</b></a>`},
+
+		// self-closing elements get the end tag
+		{`<a><b/></a>`, "/a/b", "x", `<a><b>x</b></a>`},
+		{`<a><b id="1" /><c/></a>`, "/a/b", "&e;", `<a><b id="1" >&e;</b><c/></a>`},
+		{`<a><b id="1"/></a>`, "/a/b/@id", "2", `<a><b id="2"/></a>`},
+
+		// attributes keep their quotes, the payload is escaped for them
+		{`<a id="1" name='n'><b/></a>`, "/a/@name", "x", `<a id="1" name='x'><b/></a>`},
+		{`<a id="1" name='n'><b/></a>`, "/a/@id", `x"y`, `<a id="x&#34;y" name='n'><b/></a>`},
+		{`<a id="1" name='n'><b/></a>`, "/a/@name", "x'y", `<a id="1" name='x&#39;y'><b/></a>`},
+		{`<a id = "1"><b/></a>`, "/a/@id", "&e;", `<a id = "&e;"><b/></a>`},
+		{`<a id="1"><b/></a>`, "/a/@id", "<&e;", `<a id="&lt;&amp;e;"><b/></a>`},
+		{`<a><i n="1"/><i n="2"/></a>`, "/a/i[2]/@n", "x", `<a><i n="1"/><i n="x"/></a>`},
+	}
+	for _, tt := range tests {
+		got, err := setXML([]byte(tt.doc), tt.path, tt.payload)
+		if err != nil {
+			t.Errorf("setXML(%q, %q, %q): %v", tt.doc, tt.path, tt.payload, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("setXML(%q, %q, %q) = %q, want %q", tt.doc, tt.path, tt.payload, got, tt.want)
+		}
+	}
+}
+
+func TestSetXMLErrors(t *testing.T) {
+	tests := []struct {
+		doc, path string
+	}{
+		{`<a><b>1</b></a>`, "/a"},
+		{`<a><b>1</b></a>`, "/a/c"},
+		{`<a><b>1</b></a>`, "/a/b[2]"},
+		{`<a xmlns:s="urn:x"><b/></a>`, "/a/@xmlns:s"},
+		{`not xml`, "/a"},
+	}
+	for _, tt := range tests {
+		if got, err := setXML([]byte(tt.doc), tt.path, "x"); err == nil {
+			t.Errorf("setXML(%q, %q) = %q, want error", tt.doc, tt.path, got)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package har
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+const (
+	httpVersion    = "HTTP/1.1"
+	encodingBase64 = "base64"
+)
+
+func NewHAR(entries []*Entry) *HAR {
+	if entries == nil {
+		entries = make([]*Entry, 0)
+	}
+	return &HAR{Log: &Log{
+		Version: Version,
+		Creator: &Creator{Name: creatorName, Version: Version},
+		Entries: entries,
+	}}
+}
+
+// NewEntry converts stored exchange to HAR entry. Offloaded bodies have to
+// be loaded before, otherwise content is empty.
+func NewEntry(reqInfo *models.RequestInfo) *Entry {
+	entry := &Entry{
+		StartedDateTime: reqInfo.CreatedAt,
+		Request:         newRequest(reqInfo.Request),
+		Response:        newResponse(reqInfo.Response),
+		Cache:           &Cache{},
+		Timings:         newTimings(reqInfo.Timings),
+	}
+	if reqInfo.Timings != nil && !reqInfo.Timings.StartedAt.IsZero() {
+		entry.StartedDateTime = reqInfo.Timings.StartedAt
+	}
+
+	t := entry.Timings
+	for _, v := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		if v > 0 {
+			entry.Time += v
+		}
+	}
+	return entry
+}
+
+func newRequest(req *models.ParsedRequest) *Request {
+	r := &Request{
+		Method:      req.Method,
+		URL:         req.URL,
+		HTTPVersion: httpVersion,
+		Cookies:     newCookies(req.Cookies),
+		Headers:     newNameValues(req.Header),
+		QueryString: make([]*NameValue, 0),
+		HeadersSize: -1,
+		BodySize:    req.BodyMeta.Size,
+	}
+
+	if u, err := url.Parse(req.URL); err == nil {
+		r.QueryString = newNameValues(u.Query())
+	}
+
+	if req.BodyMeta.Size > 0 {
+		text, encoding := bodyText(req.Body, req.BodyMeta)
+		r.PostData = &PostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Params:   make([]*Param, 0),
+			Text:     text,
+			Encoding: encoding,
+		}
+		if req.BodyMeta.MimeType == "application/x-www-form-urlencoded" {
+			for _, nv := range newNameValues(req.PostForm) {
+				r.PostData.Params = append(r.PostData.Params, &Param{Name: nv.Name, Value: nv.Value})
+			}
+		}
+	}
+
+	return r
+}
+
+// newResponse returns empty response for request-only entries, the same way
+// browsers export failed requests.
+func newResponse(resp *models.ParsedResponse) *Response {
+	if resp == nil {
+		return &Response{
+			HTTPVersion: httpVersion,
+			Cookies:     make([]*Cookie, 0),
+			Headers:     make([]*NameValue, 0),
+			Content:     &Content{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		}
+	}
+
+	text, encoding := bodyText(resp.Body, resp.BodyMeta)
+	content := &Content{
+		Size:     resp.BodyMeta.Size,
+		MimeType: resp.Header.Get("Content-Type"),
+		Text:     text,
+		Encoding: encoding,
+	}
+	if resp.RawSize > 0 && resp.DecodedSize > resp.RawSize {
+		content.Compression = resp.DecodedSize - resp.RawSize
+	}
+
+	return &Response{
+		Status:      resp.StatusCode,
+		StatusText:  statusText(resp.Status, resp.StatusCode),
+		HTTPVersion: httpVersion,
+		Cookies:     newCookies(resp.Cookies),
+		Headers:     newNameValues(resp.Header),
+		Content:     content,
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    resp.RawSize,
+	}
+}
+
+func newTimings(t *models.Timings) *Timings {
+	timings := &Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+	if t == nil {
+		return timings
+	}
+
+	if t.Connect > 0 {
+		timings.Connect = millis(t.Connect)
+	}
+	timings.Send = millis(t.Send)
+	timings.Wait = millis(t.Wait)
+	timings.Receive = millis(t.Receive)
+	return timings
+}
+
+func newCookies(cookies []*http.Cookie) []*Cookie {
+	res := make([]*Cookie, 0, len(cookies))
+	for _, c := range cookies {
+		cookie := &Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			HTTPOnly: c.HttpOnly,
+			Secure:   c.Secure,
+		}
+		if !c.Expires.IsZero() {
+			expires := c.Expires
+			cookie.Expires = &expires
+		}
+		res = append(res, cookie)
+	}
+	return res
+}
+
+// newNameValues flattens header or url values, sorted by name so export is
+// stable.
+func newNameValues(values map[string][]string) []*NameValue {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := make([]*NameValue, 0, len(values))
+	for _, name := range names {
+		for _, v := range values[name] {
+			res = append(res, &NameValue{Name: name, Value: v})
+		}
+	}
+	return res
+}
+
+func bodyText(body []byte, meta models.BodyMeta) (string, string) {
+	if meta.Binary {
+		return base64.StdEncoding.EncodeToString(body), encodingBase64
+	}
+	return string(body), ""
+}
+
+func statusText(status string, code int) string {
+	if text := strings.TrimPrefix(status, fmt.Sprintf("%d ", code)); text != status {
+		return text
+	}
+	return http.StatusText(code)
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// ToRequestInfo converts HAR entry back to exchange, parsing it the same way
+// as proxied traffic.
+func (e *Entry) ToRequestInfo() (*models.RequestInfo, error) {
+	if e.Request == nil {
+		return nil, fmt.Errorf("entry has no request")
+	}
+
+	req, err := e.Request.toHTTP()
+	if err != nil {
+		return nil, err
+	}
+	parsedReq, err := models.NewParsedRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse request: %v", err)
+	}
+
+	var parsedResp *models.ParsedResponse
+	// status 0 is used for requests without response
+	if e.Response != nil && e.Response.Status > 0 {
+		resp, err := e.Response.toHTTP(req)
+		if err != nil {
+			return nil, err
+		}
+		parsedResp, err = models.NewParsedResponse(resp)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse response: %v", err)
+		}
+	}
+
+	reqInfo := models.NewRequestInfo(parsedReq, parsedResp)
+	if !e.StartedDateTime.IsZero() {
+		reqInfo.CreatedAt = e.StartedDateTime
+	}
+	if t := e.Timings; t != nil {
+		reqInfo.Timings = &models.Timings{
+			StartedAt: e.StartedDateTime,
+			Connect:   duration(t.Connect),
+			Send:      duration(t.Send),
+			Wait:      duration(t.Wait),
+			Receive:   duration(t.Receive),
+		}
+	}
+	return reqInfo, nil
+}
+
+func (r *Request) toHTTP() (*http.Request, error) {
+	var body []byte
+	if r.PostData != nil {
+		data, err := decodeText(r.PostData.Text, r.PostData.Encoding)
+		if err != nil {
+			return nil, fmt.Errorf("can't decode post data: %v", err)
+		}
+		body = data
+	}
+
+	req, err := http.NewRequest(r.Method, r.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("wrong request: %v", err)
+	}
+	req.Header = toHeader(r.Headers)
+	if req.Header.Get("Cookie") == "" {
+		for _, c := range r.Cookies {
+			req.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+		}
+	}
+	if r.PostData != nil && r.PostData.MimeType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", r.PostData.MimeType)
+	}
+
+	return req, nil
+}
+
+func (r *Response) toHTTP(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if r.Content != nil {
+		data, err := decodeText(r.Content.Text, r.Content.Encoding)
+		if err != nil {
+			return nil, fmt.Errorf("can't decode content: %v", err)
+		}
+		body = data
+	}
+
+	// content is stored decoded, headers must not claim the codings
+	header := toHeader(r.Headers)
+	if len(body) > 0 {
+		header.Del("Content-Encoding")
+		if header.Get("Content-Length") != "" {
+			header.Set("Content-Length", strconv.Itoa(len(body)))
+		}
+	}
+
+	statusText := r.StatusText
+	if statusText == "" {
+		statusText = http.StatusText(r.Status)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, statusText),
+		StatusCode:    r.Status,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// toHeader skips HTTP/2 pseudo headers like ":authority".
+func toHeader(values []*NameValue) http.Header {
+	header := make(http.Header)
+	for _, nv := range values {
+		if nv == nil || strings.HasPrefix(nv.Name, ":") {
+			continue
+		}
+		header.Add(nv.Name, nv.Value)
+	}
+	return header
+}
+
+func decodeText(text, encoding string) ([]byte, error) {
+	if encoding == encodingBase64 {
+		return base64.StdEncoding.DecodeString(text)
+	}
+	return []byte(text), nil
+}
+
+func duration(ms float64) time.Duration {
+	if ms < 0 {
+		return 0
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
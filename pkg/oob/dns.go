@@ -0,0 +1,120 @@
+package oob
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+const (
+	dnsTypeA        = 1
+	dnsClassIN      = 1
+	dnsRcodeOK      = 0
+	dnsRcodeNotImpl = 4
+	dnsRcodeRefused = 5
+)
+
+var errDNSFormat = errors.New("malformed dns message")
+
+// serveDNS answers A queries of names in the domain with the address of
+// the server and records every query of them.
+func (s *Server) serveDNS() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.dnsConn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		resp, name, qtype, err := s.answerDNS(buf[:n])
+		if err != nil {
+			continue
+		}
+		if id := s.idOf(name); id != "" {
+			s.record(id, models.OOBDNS, addr.String(), name+" "+dnsTypeName(qtype))
+		}
+		_, _ = s.dnsConn.WriteTo(resp, addr)
+	}
+}
+
+// answerDNS builds the response to the first question of the query.
+func (s *Server) answerDNS(query []byte) ([]byte, string, uint16, error) {
+	if len(query) < 12 || binary.BigEndian.Uint16(query[4:6]) == 0 {
+		return nil, "", 0, errDNSFormat
+	}
+
+	var labels []string
+	i := 12
+	for {
+		if i >= len(query) {
+			return nil, "", 0, errDNSFormat
+		}
+		l := int(query[i])
+		i++
+		if l == 0 {
+			break
+		}
+		// compression isn't used in questions
+		if l > 63 || i+l > len(query) {
+			return nil, "", 0, errDNSFormat
+		}
+		labels = append(labels, string(query[i:i+l]))
+		i += l
+	}
+	if i+4 > len(query) {
+		return nil, "", 0, errDNSFormat
+	}
+	qtype := binary.BigEndian.Uint16(query[i : i+2])
+	qclass := binary.BigEndian.Uint16(query[i+2 : i+4])
+	question := query[12 : i+4]
+	name := strings.ToLower(strings.Join(labels, "."))
+
+	rcode := dnsRcodeOK
+	inZone := name == s.cfg.Domain || strings.HasSuffix(name, "."+s.cfg.Domain)
+	switch {
+	case !inZone:
+		rcode = dnsRcodeRefused
+	case qclass != dnsClassIN:
+		rcode = dnsRcodeNotImpl
+	}
+	answer := rcode == dnsRcodeOK && qtype == dnsTypeA
+
+	resp := make([]byte, 12, 12+len(question)+16)
+	copy(resp[0:2], query[0:2])
+	// QR, opcode and RD of the query, AA
+	flags := 0x8000 | binary.BigEndian.Uint16(query[2:4])&0x7900 | 0x0400 | uint16(rcode)
+	binary.BigEndian.PutUint16(resp[2:4], flags)
+	binary.BigEndian.PutUint16(resp[4:6], 1)
+	if answer {
+		binary.BigEndian.PutUint16(resp[6:8], 1)
+	}
+	resp = append(resp, question...)
+	if answer {
+		// name is a pointer to the question
+		resp = append(resp, 0xc0, 12)
+		resp = binary.BigEndian.AppendUint16(resp, dnsTypeA)
+		resp = binary.BigEndian.AppendUint16(resp, dnsClassIN)
+		resp = binary.BigEndian.AppendUint32(resp, 0)
+		resp = binary.BigEndian.AppendUint16(resp, 4)
+		resp = append(resp, s.answer...)
+	}
+	return resp, name, qtype, nil
+}
+
+func dnsTypeName(qtype uint16) string {
+	switch qtype {
+	case 1:
+		return "A"
+	case 28:
+		return "AAAA"
+	case 5:
+		return "CNAME"
+	case 15:
+		return "MX"
+	case 16:
+		return "TXT"
+	}
+	return "TYPE" + strconv.Itoa(int(qtype))
+}
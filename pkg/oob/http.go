@@ -0,0 +1,33 @@
+package oob
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+// serveHTTP takes payload ID from the subdomain or from the first path
+// segment and answers with it, so the response can be recognized too.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	id := s.idOf(strings.ToLower(host))
+	if id == "" {
+		id, _, _ = strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		id = strings.ToLower(id)
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxDataSize)
+	data, _ := httputil.DumpRequest(r, true)
+	_, _ = io.Copy(io.Discard, r.Body)
+	s.record(id, models.OOBHTTP, r.RemoteAddr, string(data))
+
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = io.WriteString(w, id)
+}
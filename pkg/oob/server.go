@@ -0,0 +1,213 @@
+// Package oob is a callback server for blind vulnerabilities. Payloads make
+// the target request a unique path or resolve a unique subdomain, requests
+// to them are recorded and matched to the payload which caused them.
+package oob
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+const (
+	idLength = 12
+	// maxTags limits tracked payloads, the oldest ones are forgotten first.
+	maxTags = 100000
+	// maxInteractions is the number of recent interactions kept.
+	maxInteractions = 1000
+	// maxDataSize limits recorded request data.
+	maxDataSize = 4096
+)
+
+// Config sets addresses to listen on, empty address disables the protocol.
+// PublicHost is put into payload URLs, HTTPAddr by default. Domain is the
+// zone the DNS server answers for.
+type Config struct {
+	HTTPAddr   string
+	DNSAddr    string
+	Domain     string
+	PublicHost string
+}
+
+// tag is what a payload was sent for.
+type tag struct {
+	scanID  string
+	finding *models.Finding
+}
+
+type Server struct {
+	cfg      Config
+	answer   net.IP
+	httpSrv  *http.Server
+	dnsConn  net.PacketConn
+	mu       sync.Mutex
+	tags     map[string]*tag
+	order    []string
+	recent   []*models.Interaction
+	handlers []func(*models.Interaction)
+}
+
+func NewServer(cfg Config) *Server {
+	if cfg.PublicHost == "" {
+		cfg.PublicHost = cfg.HTTPAddr
+	}
+	cfg.Domain = strings.ToLower(strings.Trim(cfg.Domain, "."))
+
+	// resolved names point at the HTTP server, so HTTP payloads work with
+	// domains too
+	answer := net.IPv4(127, 0, 0, 1)
+	host := cfg.PublicHost
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.To4() != nil && !ip.IsUnspecified() {
+		answer = ip.To4()
+	}
+
+	return &Server{
+		cfg:    cfg,
+		answer: answer,
+		tags:   make(map[string]*tag),
+	}
+}
+
+// Start listens on configured addresses and serves in background.
+func (s *Server) Start() error {
+	if s.cfg.HTTPAddr != "" {
+		l, err := net.Listen("tcp", s.cfg.HTTPAddr)
+		if err != nil {
+			return fmt.Errorf("can't listen oob http: %v", err)
+		}
+		s.httpSrv = &http.Server{
+			Handler:     http.HandlerFunc(s.serveHTTP),
+			ReadTimeout: 10 * time.Second,
+		}
+		go s.httpSrv.Serve(l)
+	}
+
+	if s.cfg.DNSAddr != "" {
+		if s.cfg.Domain == "" {
+			return fmt.Errorf("oob dns needs a domain")
+		}
+		conn, err := net.ListenPacket("udp", s.cfg.DNSAddr)
+		if err != nil {
+			return fmt.Errorf("can't listen oob dns: %v", err)
+		}
+		s.dnsConn = conn
+		go s.serveDNS()
+	}
+	return nil
+}
+
+func (s *Server) Close() error {
+	if s.httpSrv != nil {
+		s.httpSrv.Close()
+	}
+	if s.dnsConn != nil {
+		s.dnsConn.Close()
+	}
+	return nil
+}
+
+// HTTP tells whether URL payloads can be used.
+func (s *Server) HTTP() bool {
+	return s != nil && s.cfg.HTTPAddr != ""
+}
+
+// DNS tells whether domain payloads can be used.
+func (s *Server) DNS() bool {
+	return s != nil && s.cfg.DNSAddr != ""
+}
+
+// NewID returns unique payload ID, it is a valid DNS label.
+func (s *Server) NewID() string {
+	b := make([]byte, idLength/2)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (s *Server) URL(id string) string {
+	return "http://" + s.cfg.PublicHost + "/" + id
+}
+
+func (s *Server) Domain(id string) string {
+	return id + "." + s.cfg.Domain
+}
+
+// Track remembers the scan and the finding payload id is sent for, they
+// are set in its interactions.
+func (s *Server) Track(id, scanID string, finding *models.Finding) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.order) == maxTags {
+		delete(s.tags, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.tags[id] = &tag{scanID: scanID, finding: finding}
+	s.order = append(s.order, id)
+}
+
+// OnInteraction registers fn to be called with every interaction. It is
+// called from server goroutines. Must be called before Start.
+func (s *Server) OnInteraction(fn func(*models.Interaction)) {
+	s.handlers = append(s.handlers, fn)
+}
+
+// Interactions returns recent interactions from the oldest, only ones of
+// the scan if scanID is set.
+func (s *Server) Interactions(scanID string) []*models.Interaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make([]*models.Interaction, 0)
+	for _, i := range s.recent {
+		if scanID == "" || i.ScanID == scanID {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+func (s *Server) record(id, protocol, remoteAddr, data string) {
+	if len(data) > maxDataSize {
+		data = data[:maxDataSize]
+	}
+	i := &models.Interaction{
+		PayloadID:  id,
+		Protocol:   protocol,
+		RemoteAddr: remoteAddr,
+		Data:       data,
+		Time:       time.Now(),
+	}
+
+	s.mu.Lock()
+	if t, ok := s.tags[id]; ok {
+		i.ScanID = t.scanID
+		i.Finding = t.finding
+	}
+	if len(s.recent) == maxInteractions {
+		s.recent = s.recent[1:]
+	}
+	s.recent = append(s.recent, i)
+	s.mu.Unlock()
+
+	for _, fn := range s.handlers {
+		fn(i)
+	}
+}
+
+// idOf returns the label right before the domain, name is lower case.
+func (s *Server) idOf(name string) string {
+	name = strings.TrimSuffix(name, ".")
+	if s.cfg.Domain == "" || !strings.HasSuffix(name, "."+s.cfg.Domain) {
+		return ""
+	}
+	name = strings.TrimSuffix(name, "."+s.cfg.Domain)
+	return name[strings.LastIndexByte(name, '.')+1:]
+}
@@ -0,0 +1,213 @@
+package oob
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+// dnsQuery builds a query with one question of name.
+func dnsQuery(id uint16, name string, qtype, qclass uint16) []byte {
+	q := binary.BigEndian.AppendUint16(nil, id)
+	// RD, one question
+	q = append(q, 0x01, 0x00, 0, 1, 0, 0, 0, 0, 0, 0)
+	for _, l := range strings.Split(name, ".") {
+		q = append(q, byte(len(l)))
+		q = append(q, l...)
+	}
+	q = append(q, 0)
+	q = binary.BigEndian.AppendUint16(q, qtype)
+	return binary.BigEndian.AppendUint16(q, qclass)
+}
+
+// interactions collects interactions of the server as they are recorded.
+func interactions(s *Server) <-chan *models.Interaction {
+	ch := make(chan *models.Interaction, 10)
+	s.OnInteraction(func(i *models.Interaction) { ch <- i })
+	return ch
+}
+
+func next(t *testing.T, ch <-chan *models.Interaction) *models.Interaction {
+	t.Helper()
+	select {
+	case i := <-ch:
+		return i
+	case <-time.After(5 * time.Second):
+		t.Fatal("no interaction recorded")
+		return nil
+	}
+}
+
+func TestDNS(t *testing.T) {
+	s := NewServer(Config{DNSAddr: "127.0.0.1:0", Domain: "OOB.Test.", PublicHost: "10.1.2.3:80"})
+	recorded := interactions(s)
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	conn, err := net.Dial("udp", s.dnsConn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	tests := []struct {
+		name          string
+		qtype, qclass uint16
+		rcode         int
+		answers       int
+		id            string
+	}{
+		{"abc123.oob.test", dnsTypeA, dnsClassIN, dnsRcodeOK, 1, "abc123"},
+		{"x.ABC123.oob.test", dnsTypeA, dnsClassIN, dnsRcodeOK, 1, "abc123"},
+		{"abc123.oob.test", 28, dnsClassIN, dnsRcodeOK, 0, "abc123"},
+		{"oob.test", dnsTypeA, dnsClassIN, dnsRcodeOK, 1, ""},
+		{"abc123.oob.test", dnsTypeA, 3, dnsRcodeNotImpl, 0, "abc123"},
+		{"abc123.example.com", dnsTypeA, dnsClassIN, dnsRcodeRefused, 0, ""},
+	}
+	for n, tt := range tests {
+		query := dnsQuery(uint16(n+1), tt.name, tt.qtype, tt.qclass)
+		if _, err := conn.Write(query); err != nil {
+			t.Fatal(err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		resp := make([]byte, 512)
+		l, err := conn.Read(resp)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		resp = resp[:l]
+
+		if binary.BigEndian.Uint16(resp[0:2]) != uint16(n+1) {
+			t.Errorf("%s: response id %x", tt.name, resp[0:2])
+		}
+		flags := binary.BigEndian.Uint16(resp[2:4])
+		if flags&0x8000 == 0 || flags&0x0400 == 0 || flags&0x0100 == 0 {
+			t.Errorf("%s: flags %016b", tt.name, flags)
+		}
+		if rcode := int(flags & 0xf); rcode != tt.rcode {
+			t.Errorf("%s: rcode %d, want %d", tt.name, rcode, tt.rcode)
+		}
+		if answers := int(binary.BigEndian.Uint16(resp[6:8])); answers != tt.answers {
+			t.Errorf("%s: %d answers, want %d", tt.name, answers, tt.answers)
+		}
+		if tt.answers > 0 && !net.IP(resp[len(resp)-4:]).Equal(net.IPv4(10, 1, 2, 3)) {
+			t.Errorf("%s: answered %v", tt.name, net.IP(resp[len(resp)-4:]))
+		}
+
+		if tt.id != "" {
+			i := next(t, recorded)
+			if i.PayloadID != tt.id || i.Protocol != models.OOBDNS || !strings.HasPrefix(i.Data, strings.ToLower(tt.name)+" ") {
+				t.Errorf("%s: recorded %+v", tt.name, i)
+			}
+		}
+	}
+	select {
+	case i := <-recorded:
+		t.Errorf("query out of the payload names recorded %+v", i)
+	default:
+	}
+}
+
+func TestAnswerDNSMalformed(t *testing.T) {
+	s := NewServer(Config{Domain: "oob.test"})
+	valid := dnsQuery(1, "abc.oob.test", dnsTypeA, dnsClassIN)
+	tests := map[string][]byte{
+		"short header":    valid[:11],
+		"no questions":    append(append([]byte{}, valid[:4]...), append([]byte{0, 0}, valid[6:]...)...),
+		"truncated name":  valid[:16],
+		"no type":         valid[:len(valid)-3],
+		"label too long":  append(append([]byte{}, valid[:12]...), 64),
+		"label past end":  append(append([]byte{}, valid[:12]...), 10, 'a'),
+		"compressed name": append(append([]byte{}, valid[:12]...), 0xc0, 12, 0, 1, 0, 1),
+	}
+	for name, query := range tests {
+		if _, _, _, err := s.answerDNS(query); err == nil {
+			t.Errorf("%s: query accepted", name)
+		}
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	s := NewServer(Config{HTTPAddr: "127.0.0.1:0", Domain: "oob.test"})
+	recorded := interactions(s)
+	srv := httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	defer srv.Close()
+
+	tests := []struct {
+		host, path string
+		id         string
+	}{
+		{"", "/abc123", "abc123"},
+		{"", "/ABC123/x/y?q=1", "abc123"},
+		{"def456.oob.test", "/", "def456"},
+		{"x.DEF456.oob.test:8080", "/abc123", "def456"},
+		{"other.test", "/", ""},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest("POST", srv.URL+tt.path, strings.NewReader("payload body"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.host != "" {
+			req.Host = tt.host
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != tt.id {
+			t.Errorf("%s%s: answered %q, want %q", tt.host, tt.path, body, tt.id)
+		}
+
+		i := next(t, recorded)
+		if i.PayloadID != tt.id || i.Protocol != models.OOBHTTP || !strings.Contains(i.Data, "payload body") {
+			t.Errorf("%s%s: recorded %+v", tt.host, tt.path, i)
+		}
+	}
+}
+
+func TestTrack(t *testing.T) {
+	s := NewServer(Config{HTTPAddr: "127.0.0.1:0"})
+	recorded := interactions(s)
+	srv := httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	defer srv.Close()
+
+	tracked, untracked := s.NewID(), s.NewID()
+	finding := &models.Finding{Check: "ssrf", Param: "url"}
+	s.Track(tracked, "scan1", finding)
+
+	for _, id := range []string{tracked, untracked} {
+		resp, err := http.Get(srv.URL + "/" + id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	i := next(t, recorded)
+	if i.PayloadID != tracked || i.ScanID != "scan1" || i.Finding != finding {
+		t.Errorf("tracked payload recorded %+v", i)
+	}
+	i = next(t, recorded)
+	if i.PayloadID != untracked || i.ScanID != "" || i.Finding != nil {
+		t.Errorf("untracked payload recorded %+v", i)
+	}
+
+	if got := s.Interactions("scan1"); len(got) != 1 || got[0].Finding != finding {
+		t.Errorf("interactions of scan %+v", got)
+	}
+	if got := s.Interactions(""); len(got) != 2 {
+		t.Errorf("all interactions %+v", got)
+	}
+}
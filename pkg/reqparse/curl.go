@@ -0,0 +1,304 @@
+package reqparse
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// curlFlags are options without value which don't change the request.
+var curlFlags = map[string]bool{
+	"-k": true, "--insecure": true,
+	"-s": true, "--silent": true,
+	"-S": true, "--show-error": true,
+	"-L": true, "--location": true,
+	"-v": true, "--verbose": true,
+	"-i": true, "--include": true,
+	"-g": true, "--globoff": true,
+	"-f": true, "--fail": true,
+	"-N": true, "--no-buffer": true,
+	"--compressed":     true,
+	"--http1.0":        true,
+	"--http1.1":        true,
+	"--http2":          true,
+	"--path-as-is":     true,
+	"--fail-with-body": true,
+}
+
+// curlIgnored are options with value which don't change the request.
+var curlIgnored = map[string]bool{
+	"-o": true, "--output": true,
+	"-x": true, "--proxy": true,
+	"-m": true, "--max-time": true,
+	"-w": true, "--write-out": true,
+	"-c": true, "--cookie-jar": true,
+	"--connect-timeout": true,
+	"--retry":           true,
+	"--resolve":         true,
+	"--cacert":          true,
+}
+
+// curlValued are options with value that are handled by ParseCurl.
+var curlValued = map[string]bool{
+	"-X": true, "--request": true,
+	"-H": true, "--header": true,
+	"-d": true, "--data": true, "--data-ascii": true, "--data-binary": true, "--data-raw": true,
+	"--data-urlencode": true,
+	"--json":           true,
+	"-F":               true, "--form": true, "--form-string": true,
+	"-b": true, "--cookie": true,
+	"-A": true, "--user-agent": true,
+	"-e": true, "--referer": true,
+	"-u": true, "--user": true,
+	"--url": true,
+}
+
+type curlCmd struct {
+	method   string
+	url      string
+	header   http.Header
+	data     []string
+	form     [][2]string
+	json     bool
+	get      bool
+	head     bool
+	cookies  []string
+	userInfo string
+}
+
+// ParseCurl parses curl command line. Only options which describe the
+// request are supported, reading data or form values from files is not.
+func ParseCurl(cmd string) (*http.Request, error) {
+	words, err := splitShell(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 || words[0] != "curl" {
+		return nil, fmt.Errorf("command must start with curl")
+	}
+
+	c := &curlCmd{header: make(http.Header)}
+	args := expandFlags(words[1:])
+	for i := 0; i < len(args); i++ {
+		opt, value, hasValue := splitOption(args[i])
+		switch {
+		case opt == "":
+			if c.url != "" {
+				return nil, fmt.Errorf("only one url is supported")
+			}
+			c.url = value
+			continue
+		case opt == "-G" || opt == "--get":
+			c.get = true
+			continue
+		case opt == "-I" || opt == "--head":
+			c.head = true
+			continue
+		case curlFlags[opt]:
+			continue
+		case !curlValued[opt] && !curlIgnored[opt]:
+			return nil, fmt.Errorf("unsupported option %s", opt)
+		}
+
+		if !hasValue {
+			i++
+			if i >= len(args) {
+				return nil, fmt.Errorf("option %s needs a value", opt)
+			}
+			value = args[i]
+		}
+		if err := c.apply(opt, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.request()
+}
+
+// expandFlags splits combined flags like -sSk into separate words.
+func expandFlags(words []string) []string {
+	res := make([]string, 0, len(words))
+	for _, word := range words {
+		if len(word) <= 2 || word[0] != '-' || word[1] == '-' || curlValued[word[:2]] || curlIgnored[word[:2]] {
+			res = append(res, word)
+			continue
+		}
+
+		flags := make([]string, 0, len(word)-1)
+		for _, f := range word[1:] {
+			flag := "-" + string(f)
+			if !curlFlags[flag] && flag != "-G" && flag != "-I" {
+				flags = nil
+				break
+			}
+			flags = append(flags, flag)
+		}
+		if flags == nil {
+			res = append(res, word)
+		} else {
+			res = append(res, flags...)
+		}
+	}
+	return res
+}
+
+// splitOption returns option and its value written in the same word, like
+// -XPOST. Empty option means the word is an argument.
+func splitOption(word string) (string, string, bool) {
+	if len(word) < 2 || word[0] != '-' {
+		return "", word, false
+	}
+	if len(word) > 2 && word[1] != '-' && (curlValued[word[:2]] || curlIgnored[word[:2]]) {
+		return word[:2], word[2:], true
+	}
+	return word, "", false
+}
+
+func (c *curlCmd) apply(opt, value string) error {
+	switch opt {
+	case "-X", "--request":
+		c.method = value
+	case "-H", "--header":
+		name, v, ok := strings.Cut(value, ":")
+		if !ok {
+			// "Name;" sends empty header
+			if name, ok = strings.CutSuffix(value, ";"); !ok {
+				return fmt.Errorf("wrong header %q", value)
+			}
+		}
+		c.header.Add(strings.TrimSpace(name), strings.TrimSpace(v))
+	case "-d", "--data", "--data-ascii", "--data-binary":
+		if strings.HasPrefix(value, "@") {
+			return fmt.Errorf("reading data from file is not supported")
+		}
+		c.data = append(c.data, value)
+	case "--data-raw":
+		c.data = append(c.data, value)
+	case "--data-urlencode":
+		encoded, err := urlencodeData(value)
+		if err != nil {
+			return err
+		}
+		c.data = append(c.data, encoded)
+	case "--json":
+		if strings.HasPrefix(value, "@") {
+			return fmt.Errorf("reading data from file is not supported")
+		}
+		c.data = append(c.data, value)
+		c.json = true
+	case "-F", "--form", "--form-string":
+		name, v, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("wrong form field %q", value)
+		}
+		if opt != "--form-string" && (strings.HasPrefix(v, "@") || strings.HasPrefix(v, "<")) {
+			return fmt.Errorf("reading form field from file is not supported")
+		}
+		c.form = append(c.form, [2]string{name, v})
+	case "-b", "--cookie":
+		if !strings.Contains(value, "=") {
+			return fmt.Errorf("reading cookies from file is not supported")
+		}
+		c.cookies = append(c.cookies, value)
+	case "-A", "--user-agent":
+		c.header.Set("User-Agent", value)
+	case "-e", "--referer":
+		c.header.Set("Referer", value)
+	case "-u", "--user":
+		c.userInfo = value
+	case "--url":
+		if c.url != "" {
+			return fmt.Errorf("only one url is supported")
+		}
+		c.url = value
+	}
+	return nil
+}
+
+// urlencodeData handles --data-urlencode forms "content", "=content" and
+// "name=content".
+func urlencodeData(value string) (string, error) {
+	if strings.HasPrefix(value, "@") || (strings.Contains(value, "@") && !strings.Contains(value, "=")) {
+		return "", fmt.Errorf("reading data from file is not supported")
+	}
+	name, content, ok := strings.Cut(value, "=")
+	if !ok {
+		return url.QueryEscape(value), nil
+	}
+	if name == "" {
+		return url.QueryEscape(content), nil
+	}
+	return name + "=" + url.QueryEscape(content), nil
+}
+
+func (c *curlCmd) request() (*http.Request, error) {
+	if c.url == "" {
+		return nil, fmt.Errorf("no url")
+	}
+	if len(c.data) > 0 && len(c.form) > 0 {
+		return nil, fmt.Errorf("data and form can't be used together")
+	}
+
+	method := http.MethodGet
+	var body []byte
+	switch {
+	case c.get:
+		if len(c.data) > 0 {
+			sep := "?"
+			if strings.Contains(c.url, "?") {
+				sep = "&"
+			}
+			c.url += sep + strings.Join(c.data, "&")
+		}
+	case len(c.data) > 0:
+		method = http.MethodPost
+		body = []byte(strings.Join(c.data, "&"))
+		if c.json {
+			setDefault(c.header, "Content-Type", "application/json")
+			setDefault(c.header, "Accept", "application/json")
+		} else {
+			setDefault(c.header, "Content-Type", "application/x-www-form-urlencoded")
+		}
+	case len(c.form) > 0:
+		method = http.MethodPost
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		for _, f := range c.form {
+			if err := w.WriteField(f[0], f[1]); err != nil {
+				return nil, err
+			}
+		}
+		w.Close()
+		body = buf.Bytes()
+		c.header.Set("Content-Type", w.FormDataContentType())
+	}
+	if c.head {
+		method = http.MethodHead
+	}
+	if c.method != "" {
+		method = c.method
+	}
+
+	if len(c.cookies) > 0 {
+		cookie := strings.Join(c.cookies, "; ")
+		if v := c.header.Get("Cookie"); v != "" {
+			cookie = v + "; " + cookie
+		}
+		c.header.Set("Cookie", cookie)
+	}
+	if c.userInfo != "" && c.header.Get("Authorization") == "" {
+		c.header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(c.userInfo)))
+	}
+
+	return newRequest(method, c.url, "http", c.header, body)
+}
+
+func setDefault(h http.Header, name, value string) {
+	if h.Get(name) == "" {
+		h.Set(name, value)
+	}
+}
@@ -0,0 +1,111 @@
+package reqparse
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// checkRequest compares request with the wanted one, only headers listed
+// in want are compared.
+func checkRequest(t *testing.T, name string, req *http.Request, method, url, host string, header map[string]string, body string) {
+	t.Helper()
+	if req.Method != method || req.URL.String() != url || req.Host != host {
+		t.Errorf("%s: got %s %s host %q, want %s %s host %q", name, req.Method, req.URL, req.Host, method, url, host)
+	}
+	for k, v := range header {
+		if got := req.Header.Get(k); got != v {
+			t.Errorf("%s: header %s is %q, want %q", name, k, got, v)
+		}
+	}
+	data, _ := io.ReadAll(req.Body)
+	if string(data) != body {
+		t.Errorf("%s: body %q, want %q", name, data, body)
+	}
+}
+
+func TestParseCurl(t *testing.T) {
+	tests := []struct {
+		cmd               string
+		method, url, host string
+		header            map[string]string
+		body              string
+	}{
+		{`curl example.com`, "GET", "http://example.com", "example.com", nil, ""},
+		{`curl 'https://example.com/a?b=1' -H 'Accept: */*' -H 'X-Empty;' --compressed -sSk`,
+			"GET", "https://example.com/a?b=1", "example.com", map[string]string{"Accept": "*/*", "X-Empty": ""}, ""},
+		{`curl -XPUT https://example.com/ -H"Host: internal.test"`,
+			"PUT", "https://example.com/", "internal.test", nil, ""},
+		{`curl --request DELETE --url https://example.com/x`, "DELETE", "https://example.com/x", "example.com", nil, ""},
+		{`curl https://example.com -d a=1 --data-raw '@b=2'`,
+			"POST", "https://example.com", "example.com", map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, "a=1&@b=2"},
+		{`curl https://example.com -H 'Content-Type: text/plain' --data-binary $'line1\nline2'`,
+			"POST", "https://example.com", "example.com", map[string]string{"Content-Type": "text/plain"}, "line1\nline2"},
+		{`curl https://example.com --data-urlencode 'q=a b&c' --data-urlencode '=x y' --data-urlencode 'z/1'`,
+			"POST", "https://example.com", "example.com", nil, "q=a+b%26c&x+y&z%2F1"},
+		{`curl https://example.com --json '{"a":1}'`,
+			"POST", "https://example.com", "example.com", map[string]string{"Content-Type": "application/json", "Accept": "application/json"}, `{"a":1}`},
+		{`curl -G https://example.com/s?x=1 -d q=go -d n=2`, "GET", "https://example.com/s?x=1&q=go&n=2", "example.com", nil, ""},
+		{`curl -I https://example.com`, "HEAD", "https://example.com", "example.com", nil, ""},
+		{`curl -X PATCH https://example.com -d a=1`, "PATCH", "https://example.com", "example.com", nil, "a=1"},
+		{`curl https://example.com -b 'a=1' -b 'b=2' -H 'Cookie: c=3'`,
+			"GET", "https://example.com", "example.com", map[string]string{"Cookie": "c=3; a=1; b=2"}, ""},
+		{`curl -u user:pass -A agent/1 -e https://ref.test/ https://example.com`,
+			"GET", "https://example.com", "example.com",
+			map[string]string{"Authorization": "Basic dXNlcjpwYXNz", "User-Agent": "agent/1", "Referer": "https://ref.test/"}, ""},
+		{`curl -o out.html --max-time 5 -x http://proxy:8080 https://example.com`, "GET", "https://example.com", "example.com", nil, ""},
+		{`curl https://example.com -H 'Content-Length: 99' -d a=1`, "POST", "https://example.com", "example.com", map[string]string{"Content-Length": ""}, "a=1"},
+	}
+	for _, tt := range tests {
+		req, err := ParseCurl(tt.cmd)
+		if err != nil {
+			t.Errorf("%s: %v", tt.cmd, err)
+			continue
+		}
+		checkRequest(t, tt.cmd, req, tt.method, tt.url, tt.host, tt.header, tt.body)
+	}
+}
+
+func TestParseCurlForm(t *testing.T) {
+	req, err := ParseCurl(`curl https://example.com/upload -F name=go -F 'note=a b' --form-string 'file=@x'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "POST" || !strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/form-data; boundary=") {
+		t.Fatalf("got %s with %q", req.Method, req.Header.Get("Content-Type"))
+	}
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"name": "go", "note": "a b", "file": "@x"} {
+		if got := req.FormValue(name); got != want {
+			t.Errorf("field %s is %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParseCurlErrors(t *testing.T) {
+	for _, cmd := range []string{
+		``,
+		`wget https://example.com`,
+		`curl`,
+		`curl 'https://example.com`,
+		`curl https://a.test https://b.test`,
+		`curl https://example.com -X`,
+		`curl https://example.com --unknown`,
+		`curl https://example.com -H 'no colon'`,
+		`curl https://example.com -d @file.json`,
+		`curl https://example.com --json @file.json`,
+		`curl https://example.com --data-urlencode name@file`,
+		`curl https://example.com -F file=@photo.png`,
+		`curl https://example.com -F novalue`,
+		`curl https://example.com -b cookies.txt`,
+		`curl https://example.com -d a=1 -F b=2`,
+		`curl /path/only`,
+	} {
+		if _, err := ParseCurl(cmd); err == nil {
+			t.Errorf("%q parsed", cmd)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package reqparse
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/pkg/decoder"
+)
+
+// ParseRaw parses HTTP/1.x request message. Hand-written files are allowed
+// to use bare LF and to omit Content-Length, then the rest of data is the
+// body. Request line with origin-form URL is resolved against Host header
+// using scheme.
+func ParseRaw(data []byte, scheme string) (*http.Request, error) {
+	data = bytes.TrimLeft(data, "\r\n")
+	head, body, found := bytes.Cut(data, []byte("\r\n\r\n"))
+	if i := bytes.Index(data, []byte("\n\n")); i >= 0 && (!found || i < len(head)) {
+		head, body = data[:i], data[i+2:]
+	}
+
+	// head shares memory with body, so it is copied before appending
+	msg := make([]byte, 0, len(head)+4)
+	msg = append(append(msg, head...), "\r\n\r\n"...)
+	req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(msg)))
+	if err != nil {
+		return nil, fmt.Errorf("wrong request: %v", err)
+	}
+
+	if strings.EqualFold(req.Header.Get("Transfer-Encoding"), "chunked") || hasChunked(req.TransferEncoding) {
+		dechunked, err := decoder.Dechunk(body)
+		if err != nil {
+			return nil, fmt.Errorf("can't dechunk body: %v", err)
+		}
+		body = dechunked
+		req.Header.Del("Transfer-Encoding")
+	} else if cl := req.Header.Get("Content-Length"); cl != "" {
+		n, err := strconv.Atoi(cl)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("wrong content length %q", cl)
+		}
+		if n < len(body) {
+			body = body[:n]
+		}
+	}
+
+	rawURL := req.RequestURI
+	if !strings.Contains(rawURL, "://") {
+		if req.Host == "" {
+			return nil, fmt.Errorf("no host header")
+		}
+		rawURL = scheme + "://" + req.Host + rawURL
+	}
+
+	header := req.Header
+	if req.Host != "" {
+		header.Set("Host", req.Host)
+	}
+	return newRequest(req.Method, rawURL, scheme, header, body)
+}
+
+func hasChunked(te []string) bool {
+	for _, v := range te {
+		if strings.EqualFold(v, "chunked") {
+			return true
+		}
+	}
+	return false
+}
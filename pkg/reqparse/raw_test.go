@@ -0,0 +1,61 @@
+package reqparse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRaw(t *testing.T) {
+	tests := []struct {
+		name, data, scheme string
+		method, url, host  string
+		header             map[string]string
+		body               string
+	}{
+		{"crlf", "GET /a?b=1 HTTP/1.1\r\nHost: example.com\r\nAccept: */*\r\n\r\n", "https",
+			"GET", "https://example.com/a?b=1", "example.com", map[string]string{"Accept": "*/*"}, ""},
+		{"bare lf", "\n\nPOST /login HTTP/1.1\nHost: example.com:8080\nContent-Type: text/plain\n\nuser=a\npass=b", "http",
+			"POST", "http://example.com:8080/login", "example.com:8080", map[string]string{"Content-Type": "text/plain"}, "user=a\npass=b"},
+		{"blank line in body", "POST / HTTP/1.1\r\nHost: example.com\r\n\r\na\n\nb", "http",
+			"POST", "http://example.com/", "example.com", nil, "a\n\nb"},
+		{"content length", "POST / HTTP/1.1\r\nHost: example.com\r\nContent-Length: 3\r\n\r\nabcdef", "http",
+			"POST", "http://example.com/", "example.com", map[string]string{"Content-Length": ""}, "abc"},
+		{"short body", "POST / HTTP/1.1\r\nHost: example.com\r\nContent-Length: 10\r\n\r\nabc", "http",
+			"POST", "http://example.com/", "example.com", nil, "abc"},
+		{"chunked", "POST /up HTTP/1.1\r\nHost: example.com\r\nTransfer-Encoding: chunked\r\n\r\n3\r\nabc\r\na;ext=1\r\n0123456789\r\n0\r\n\r\n", "http",
+			"POST", "http://example.com/up", "example.com", map[string]string{"Transfer-Encoding": ""}, "abc0123456789"},
+		{"chunked wins over length", "POST / HTTP/1.1\r\nHost: example.com\r\nContent-Length: 2\r\nTransfer-Encoding: chunked\r\n\r\n3\r\nabc\r\n0\r\n\r\n", "http",
+			"POST", "http://example.com/", "example.com", nil, "abc"},
+		// host of absolute-form URL overrides Host header
+		{"absolute url", "GET https://api.example.com/v1 HTTP/1.1\r\nHost: example.com\r\n\r\n", "http",
+			"GET", "https://api.example.com/v1", "api.example.com", nil, ""},
+		{"absolute url without host", "GET http://api.example.com/v1 HTTP/1.0\r\n\r\n", "https",
+			"GET", "http://api.example.com/v1", "api.example.com", nil, ""},
+	}
+	for _, tt := range tests {
+		req, err := ParseRaw([]byte(tt.data), tt.scheme)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		checkRequest(t, tt.name, req, tt.method, tt.url, tt.host, tt.header, tt.body)
+	}
+}
+
+func TestParseRawErrors(t *testing.T) {
+	tests := []struct {
+		name, data, err string
+	}{
+		{"empty", "", "wrong request"},
+		{"not http", "hello world\r\n\r\n", "wrong request"},
+		{"no host", "GET / HTTP/1.1\r\nAccept: */*\r\n\r\n", "no host header"},
+		{"bad length", "POST / HTTP/1.1\r\nHost: example.com\r\nContent-Length: x\r\n\r\nabc", "wrong"},
+		{"bad chunk", "POST / HTTP/1.1\r\nHost: example.com\r\nTransfer-Encoding: chunked\r\n\r\nzz\r\nabc\r\n0\r\n\r\n", "can't dechunk body"},
+	}
+	for _, tt := range tests {
+		_, err := ParseRaw([]byte(tt.data), "http")
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package reqparse
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// newRequest builds request ready to be parsed into models.ParsedRequest,
+// URL without scheme gets defaultScheme.
+func newRequest(method, rawURL, defaultScheme string, header http.Header, body []byte) (*http.Request, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = defaultScheme + "://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("wrong url: %v", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("url %q has no host", rawURL)
+	}
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	if host := header.Get("Host"); host != "" {
+		req.Host = host
+		header.Del("Host")
+	}
+	header.Del("Content-Length")
+	return req, nil
+}
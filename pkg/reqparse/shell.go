@@ -0,0 +1,176 @@
+package reqparse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// splitShell splits command line into words the way POSIX shell does:
+// single and double quotes, backslash escapes, line continuations and bash
+// $'...' strings used by browsers' "copy as cURL". Variables and
+// substitutions are not expanded.
+func splitShell(s string) ([]string, error) {
+	var (
+		words  []string
+		word   strings.Builder
+		inWord bool
+	)
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\\':
+			if i+1 >= len(s) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			i++
+			if s[i] == '\n' {
+				continue
+			}
+			if s[i] == '\r' && i+1 < len(s) && s[i+1] == '\n' {
+				i++
+				continue
+			}
+			word.WriteByte(s[i])
+			inWord = true
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote at %d", i)
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '$' && i+1 < len(s) && s[i+1] == '\'':
+			n, err := ansiCQuoted(s[i+2:], &word)
+			if err != nil {
+				return nil, fmt.Errorf("%v at %d", err, i)
+			}
+			i += n + 1
+			inWord = true
+		case c == '"':
+			n, err := doubleQuoted(s[i+1:], &word)
+			if err != nil {
+				return nil, fmt.Errorf("%v at %d", err, i)
+			}
+			i += n
+			inWord = true
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// doubleQuoted reads string after opening quote and returns number of bytes
+// consumed including the closing quote. Backslash escapes only $ ` " \ and
+// newline, as in shell.
+func doubleQuoted(s string, word *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return i + 1, nil
+		case '\\':
+			if i+1 < len(s) && strings.IndexByte("$`\"\\\n", s[i+1]) >= 0 {
+				i++
+				if s[i] != '\n' {
+					word.WriteByte(s[i])
+				}
+				continue
+			}
+			word.WriteByte(c)
+		default:
+			word.WriteByte(c)
+		}
+	}
+	return 0, fmt.Errorf("unterminated double quote")
+}
+
+// ansiCQuoted reads $'...' string after the opening quote and returns number
+// of bytes consumed including the closing quote.
+func ansiCQuoted(s string, word *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\'' {
+			return i + 1, nil
+		}
+		if c != '\\' || i+1 >= len(s) {
+			word.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch e := s[i]; e {
+		case 'n':
+			word.WriteByte('\n')
+		case 'r':
+			word.WriteByte('\r')
+		case 't':
+			word.WriteByte('\t')
+		case 'a':
+			word.WriteByte('\a')
+		case 'b':
+			word.WriteByte('\b')
+		case 'e', 'E':
+			word.WriteByte(0x1b)
+		case 'f':
+			word.WriteByte('\f')
+		case 'v':
+			word.WriteByte('\v')
+		case 'x':
+			n := hexDigits(s[i+1:], 2)
+			if n == 0 {
+				return 0, fmt.Errorf("wrong \\x escape")
+			}
+			v, _ := strconv.ParseUint(s[i+1:i+1+n], 16, 8)
+			word.WriteByte(byte(v))
+			i += n
+		case 'u', 'U':
+			max := 4
+			if e == 'U' {
+				max = 8
+			}
+			n := hexDigits(s[i+1:], max)
+			if n == 0 {
+				return 0, fmt.Errorf("wrong \\%c escape", e)
+			}
+			v, _ := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+			word.WriteRune(rune(v))
+			i += n
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			n := 1
+			for n < 3 && i+n < len(s) && s[i+n] >= '0' && s[i+n] <= '7' {
+				n++
+			}
+			v, _ := strconv.ParseUint(s[i:i+n], 8, 8)
+			word.WriteByte(byte(v))
+			i += n - 1
+		default:
+			// \\, \', \" and unknown escapes
+			if e != '\\' && e != '\'' && e != '"' && e != '?' {
+				word.WriteByte('\\')
+			}
+			word.WriteByte(e)
+		}
+	}
+	return 0, fmt.Errorf("unterminated $' quote")
+}
+
+func hexDigits(s string, max int) int {
+	n := 0
+	for n < max && n < len(s) && strings.IndexByte("0123456789abcdefABCDEF", s[n]) >= 0 {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+package reqparse
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitShell(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"  curl  \t example.com \n", []string{"curl", "example.com"}},
+		{`curl 'a b' "c d" e\ f`, []string{"curl", "a b", "c d", "e f"}},
+		{`'it'\''s'`, []string{"it's"}},
+		{`"a\"b\\c\$d\e"`, []string{`a"b\c$d\e`}},
+		{`'$HOME "x"'`, []string{`$HOME "x"`}},
+		{`a'b'"c"d`, []string{"abcd"}},
+		{`''`, []string{""}},
+		{"curl \\\n  -k \\\r\n  url", []string{"curl", "-k", "url"}},
+		{"\"a\\\nb\"", []string{"ab"}},
+		{`$'a\nb\tc\'d\\e'`, []string{"a\nb\tc'd\\e"}},
+		{`$'\x41\101é\U0001F600\q'`, []string{"AAé😀\\q"}},
+		{`$'\x4g'`, []string{"\x04g"}},
+		{`a$b`, []string{"a$b"}},
+	}
+	for _, tt := range tests {
+		got, err := splitShell(tt.in)
+		if err != nil {
+			t.Errorf("splitShell(%q): %v", tt.in, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("splitShell(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSplitShellErrors(t *testing.T) {
+	for _, in := range []string{`'abc`, `"abc`, `"abc\"`, `$'abc`, `abc\`, `$'\xzz'`, `$'\u'`} {
+		if got, err := splitShell(in); err == nil {
+			t.Errorf("splitShell(%q) = %q, want error", in, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+// Check is a vulnerability check. For every insertion point the scanner
+// asks the check for mutations, sends them and passes all responses back to
+// Evaluate at once, so a check can compare them with each other and with the
+// baseline response of the unmodified request.
+type Check interface {
+	// ID is used in scan policies and findings.
+	ID() string
+	Name() string
+	Mutations(point *Point, baseline *Response) []*Mutation
+	Evaluate(point *Point, baseline *Response, results []*Result) []*models.Finding
+}
+
+// Observer is a check which also looks at traffic captured by the proxy,
+// e.g. for payloads an earlier scan stored on the server.
+type Observer interface {
+	Check
+	Observe(parsedReq *models.ParsedRequest, resp *Response) []*models.Finding
+}
+
+// Redirector is a check whose probes follow redirects it allows, probes of
+// other checks get the redirect response itself.
+type Redirector interface {
+	Check
+	// FollowRedirect is called with the next request and the ones before
+	// it, the first of them is the probe.
+	FollowRedirect(req *http.Request, via []*http.Request) bool
+}
+
+// Point is an insertion point with its value in the original request.
+// ScanID identifies the scan for findings which come later, like
+// out-of-band ones.
+type Point struct {
+	*models.InsertionPoint
+	Original string
+	Request  *models.ParsedRequest
+	ScanID   string
+}
+
+// Mutation is a value put into the point instead of the original one. Data
+// is kept for the check to use in Evaluate. Edit, when set, changes the
+// request further after the value is put, e.g. declares entities the value
+// refers to.
+type Mutation struct {
+	Payload string
+	Data    any
+	Edit    func(*models.ParsedRequest) (*models.ParsedRequest, error)
+}
+
+// Result is the response to a mutation, Err is set when there is none.
+type Result struct {
+	Mutation *Mutation
+	Response *Response
+	Err      error
+}
+
+// NewFinding fills finding fields common for every check.
+func NewFinding(check Check, point *Point, severity, payload, evidence string) *models.Finding {
+	return &models.Finding{
+		Check:    check.ID(),
+		Name:     check.Name(),
+		Severity: severity,
+		Location: point.Type,
+		Param:    point.Name,
+		Payload:  payload,
+		Evidence: evidence,
+	}
+}
+
+// evidenceContext is the number of bytes shown around the match.
+const evidenceContext = 40
+
+// Evidence returns the first occurrence of match in body with some text
+// around it, or empty string if there is none.
+func Evidence(body, match []byte) string {
+	i := bytes.Index(body, match)
+	if i < 0 {
+		return ""
+	}
+	return EvidenceAt(body, i, len(match))
+}
+
+// EvidenceAt returns n bytes of body from i with some text around them.
+func EvidenceAt(body []byte, i, n int) string {
+	start := max(i-evidenceContext, 0)
+	end := min(i+n+evidenceContext, len(body))
+	return string(bytes.ToValidUTF8(body[start:end], []byte("?")))
+}
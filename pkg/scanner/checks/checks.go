@@ -0,0 +1,31 @@
+// Package checks holds vulnerability checks built into the scanner.
+package checks
+
+import (
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/pkg/oob"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner"
+)
+
+type Config struct {
+	// Delay is the sleep time-based payloads ask for, rounded down to
+	// seconds.
+	Delay time.Duration
+	// OOB is the out-of-band server for blind payloads, nil disables them.
+	OOB *oob.Server
+}
+
+// All returns every built-in check.
+func All(cfg Config) []scanner.Check {
+	return []scanner.Check{
+		NewCommandInjection(cfg.Delay, cfg.OOB),
+		NewSQLInjection(cfg.Delay),
+		NewXSS(),
+		NewPathTraversal(),
+		NewSSRF(cfg.OOB),
+		NewOpenRedirect(),
+		NewSSTI(),
+		NewXXE(cfg.OOB),
+	}
+}
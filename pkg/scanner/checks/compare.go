@@ -0,0 +1,52 @@
+package checks
+
+import (
+	"bytes"
+
+	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner"
+)
+
+// minSimilarity is the share of equal lines two responses of the same page
+// have, the rest is dynamic content.
+const minSimilarity = 0.95
+
+// similar compares status codes and bodies line by line. Reflected values
+// are removed from bodies first, so the payload itself doesn't make pages
+// different.
+func similar(a, b *scanner.Response, reflected ...string) bool {
+	if a.StatusCode != b.StatusCode {
+		return false
+	}
+
+	linesA, linesB := lines(a.Body, reflected), lines(b.Body, reflected)
+	if len(linesA)+len(linesB) == 0 {
+		return true
+	}
+
+	counts := make(map[string]int, len(linesA))
+	for _, l := range linesA {
+		counts[l]++
+	}
+	common := 0
+	for _, l := range linesB {
+		if counts[l] > 0 {
+			counts[l]--
+			common++
+		}
+	}
+	return float64(2*common)/float64(len(linesA)+len(linesB)) >= minSimilarity
+}
+
+func lines(body []byte, reflected []string) []string {
+	for _, r := range reflected {
+		if r != "" {
+			body = bytes.ReplaceAll(body, []byte(r), nil)
+		}
+	}
+	split := bytes.Split(body, []byte("\n"))
+	res := make([]string, len(split))
+	for i, l := range split {
+		res[i] = string(l)
+	}
+	return res
+}
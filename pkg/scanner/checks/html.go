@@ -0,0 +1,154 @@
+package checks
+
+import (
+	"bytes"
+	"slices"
+)
+
+// HTML contexts a value can be reflected in.
+const (
+	ctxText      = "text"
+	ctxAttribute = "attribute"
+	ctxScript    = "script"
+	ctxComment   = "comment"
+)
+
+// urlAttributes take URLs, javascript: scheme runs there.
+var urlAttributes = []string{"href", "src", "action", "formaction", "data"}
+
+// htmlContext is where a reflected value starts. Quote is the quote of the
+// attribute value or of the script string, zero when there is none. URL is
+// set when the value starts a URL attribute.
+type htmlContext struct {
+	name  string
+	quote byte
+	url   bool
+}
+
+func (c htmlContext) String() string {
+	switch {
+	case c.url:
+		return "url attribute"
+	case c.name == ctxAttribute && c.quote == '"':
+		return "double-quoted attribute"
+	case c.name == ctxAttribute && c.quote == '\'':
+		return "single-quoted attribute"
+	case c.name == ctxAttribute:
+		return "unquoted attribute"
+	case c.name == ctxScript && c.quote == '"':
+		return "double-quoted script string"
+	case c.name == ctxScript && c.quote == '\'':
+		return "single-quoted script string"
+	case c.name == ctxScript && c.quote == '`':
+		return "script template string"
+	case c.name == ctxScript:
+		return "script"
+	case c.name == ctxComment:
+		return "html comment"
+	}
+	return "html text"
+}
+
+// contextAt classifies position p of the page by the markup before it. It
+// doesn't build the DOM, unclosed tags and quotes are taken as is.
+func contextAt(body []byte, p int) htmlContext {
+	prefix := bytes.ToLower(body[:p])
+
+	if open := bytes.LastIndex(prefix, []byte("<script")); open > bytes.LastIndex(prefix, []byte("</script")) {
+		if end := bytes.IndexByte(prefix[open:], '>'); end >= 0 {
+			return htmlContext{name: ctxScript, quote: scriptQuote(prefix[open+end+1:])}
+		}
+	}
+	if bytes.LastIndex(prefix, []byte("<!--")) > bytes.LastIndex(prefix, []byte("-->")) {
+		return htmlContext{name: ctxComment}
+	}
+
+	lt, gt := bytes.LastIndexByte(prefix, '<'), bytes.LastIndexByte(prefix, '>')
+	if lt > gt && lt+1 < len(body) && isLetter(body[lt+1]) {
+		return attributeContext(prefix[lt+1:])
+	}
+	return htmlContext{name: ctxText}
+}
+
+// scriptQuote returns the quote of the string the end of code is in.
+func scriptQuote(code []byte) byte {
+	var quote byte
+	for i := 0; i < len(code); i++ {
+		switch c := code[i]; {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '\'' || c == '"' || c == '`'):
+			quote = c
+		}
+	}
+	return quote
+}
+
+// attributeContext walks attributes of the open tag up to its end.
+func attributeContext(tag []byte) htmlContext {
+	i := 0
+	for i < len(tag) && !isSeparator(tag[i]) {
+		i++
+	}
+
+	for i < len(tag) {
+		for i < len(tag) && isSeparator(tag[i]) {
+			i++
+		}
+		start := i
+		for i < len(tag) && tag[i] != '=' && !isSeparator(tag[i]) {
+			i++
+		}
+		name := string(tag[start:i])
+		for i < len(tag) && isSpace(tag[i]) {
+			i++
+		}
+		if i == len(tag) || tag[i] != '=' {
+			// the value is an attribute name
+			if i == len(tag) {
+				return htmlContext{name: ctxAttribute}
+			}
+			continue
+		}
+		i++
+		for i < len(tag) && isSpace(tag[i]) {
+			i++
+		}
+
+		ctx := htmlContext{name: ctxAttribute, url: slices.Contains(urlAttributes, name)}
+		if i < len(tag) && (tag[i] == '"' || tag[i] == '\'') {
+			ctx.quote = tag[i]
+			i++
+		}
+		valueStart := i
+		for i < len(tag) {
+			if ctx.quote != 0 && tag[i] == ctx.quote || ctx.quote == 0 && isSpace(tag[i]) {
+				break
+			}
+			i++
+		}
+		if i == len(tag) {
+			ctx.url = ctx.url && valueStart == len(tag)
+			return ctx
+		}
+		if ctx.quote != 0 {
+			i++
+		}
+	}
+	return htmlContext{name: ctxAttribute}
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// isSeparator tells whether c ends the tag or attribute name.
+func isSeparator(c byte) bool {
+	return isSpace(c) || c == '/'
+}
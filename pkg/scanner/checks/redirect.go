@@ -0,0 +1,124 @@
+package checks
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner"
+)
+
+// redirectHost is the attacker domain payloads redirect to. It is reserved
+// for examples, so a real redirect there never happens by chance.
+const redirectHost = "mitmredirect.example.com"
+
+// redirectWords are in names of parameters which usually take the page to
+// go to next.
+var redirectWords = []string{"redirect", "return", "next", "continue", "goto", "url", "uri", "dest", "target", "forward", "callback", "back"}
+
+// redirectNames are short names matched exactly, they are parts of other
+// words too often.
+var redirectNames = []string{"r", "u", "to", "go", "out", "link"}
+
+// redirectPayloads have {host} replaced with the host of the target,
+// filters often only check that the URL starts with it or contains it.
+var redirectPayloads = []string{
+	"https://" + redirectHost + "/",
+	"//" + redirectHost + "/",
+	`/\` + redirectHost + "/",
+	"https://{host}." + redirectHost + "/",
+	"https://{host}@" + redirectHost + "/",
+}
+
+var (
+	metaRefreshRe = regexp.MustCompile(`(?is)<meta[^>]+http-equiv\s*=\s*["']?refresh[^>]*>`)
+	refreshURLRe  = regexp.MustCompile(`(?i)url\s*=\s*['"]?([^'">\s]+)`)
+	jsRedirectRe  = regexp.MustCompile(`(?i)(?:location(?:\.href)?\s*=|location\.(?:replace|assign)\(|window\.open\()\s*["']([^"']+)["']`)
+)
+
+// OpenRedirect puts URLs of the attacker domain into parameters which look
+// like redirect targets and looks for redirects there by Location and
+// Refresh headers, meta refresh and script. Redirects within the target
+// host are followed, so the redirect may come at the end of the chain.
+type OpenRedirect struct{}
+
+func NewOpenRedirect() *OpenRedirect {
+	return &OpenRedirect{}
+}
+
+func (o *OpenRedirect) ID() string   { return "redirect" }
+func (o *OpenRedirect) Name() string { return "Open redirect" }
+
+func (o *OpenRedirect) Mutations(point *scanner.Point, _ *scanner.Response) []*scanner.Mutation {
+	if !urlValue(point.Original) && !strings.HasPrefix(point.Original, "/") &&
+		!nameHas(point.Name, redirectWords) && !slices.Contains(redirectNames, strings.ToLower(point.Name)) {
+		return nil
+	}
+
+	host := hostname(point.Request)
+	mutations := make([]*scanner.Mutation, len(redirectPayloads))
+	for i, p := range redirectPayloads {
+		mutations[i] = &scanner.Mutation{Payload: strings.ReplaceAll(p, "{host}", host)}
+	}
+	return mutations
+}
+
+// FollowRedirect follows redirects within the host of the probe only.
+func (o *OpenRedirect) FollowRedirect(req *http.Request, via []*http.Request) bool {
+	return req.URL.Host == via[0].URL.Host
+}
+
+func (o *OpenRedirect) Evaluate(point *scanner.Point, baseline *scanner.Response, results []*scanner.Result) []*models.Finding {
+	if _, _, ok := redirectsOut(baseline); ok {
+		return nil
+	}
+	for _, res := range results {
+		if res.Response == nil {
+			continue
+		}
+		if evidence, by, ok := redirectsOut(res.Response); ok {
+			f := scanner.NewFinding(o, point, models.SeverityMedium, res.Mutation.Payload, evidence)
+			f.Detail = by
+			return []*models.Finding{f}
+		}
+	}
+	return nil
+}
+
+// redirectsOut returns the redirect to the attacker domain in the response
+// and what it is made with.
+func redirectsOut(resp *scanner.Response) (string, string, bool) {
+	if loc := resp.Header.Get("Location"); resp.StatusCode/100 == 3 && attackerURL(resp.URL, loc) {
+		return "Location: " + loc, "location header", true
+	}
+	if m := refreshURLRe.FindStringSubmatch(resp.Header.Get("Refresh")); m != nil && attackerURL(resp.URL, m[1]) {
+		return "Refresh: " + resp.Header.Get("Refresh"), "refresh header", true
+	}
+	if !isHTML(resp) {
+		return "", "", false
+	}
+
+	for _, loc := range metaRefreshRe.FindAllIndex(resp.Body, maxReflections) {
+		tag := resp.Body[loc[0]:loc[1]]
+		if m := refreshURLRe.FindSubmatch(tag); m != nil && attackerURL(resp.URL, string(m[1])) {
+			return scanner.EvidenceAt(resp.Body, loc[0], loc[1]-loc[0]), "meta refresh", true
+		}
+	}
+	for _, loc := range jsRedirectRe.FindAllSubmatchIndex(resp.Body, maxReflections) {
+		if attackerURL(resp.URL, string(resp.Body[loc[2]:loc[3]])) {
+			return scanner.EvidenceAt(resp.Body, loc[0], loc[1]-loc[0]), "javascript", true
+		}
+	}
+	return "", "", false
+}
+
+func attackerURL(base, ref string) bool {
+	u := resolve(base, ref)
+	if u == nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == redirectHost || strings.HasSuffix(host, "."+redirectHost)
+}
@@ -0,0 +1,247 @@
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner"
+)
+
+const (
+	sqliError   = "error-based"
+	sqliBoolean = "boolean-based"
+	sqliTime    = "time-based"
+)
+
+// sqliErrorPayloads break quoting of the query, the database then reports
+// a syntax error.
+var sqliErrorPayloads = []string{"'", "\"", "\\", "')"}
+
+// sqliErrors are error messages of DBMS and their drivers.
+var sqliErrors = []struct {
+	dbms string
+	re   *regexp.Regexp
+}{
+	{"MySQL", regexp.MustCompile(`(?i)(SQL syntax.*MySQL|Warning.*mysqli?_|valid MySQL result|MySqlClient\.|com\.mysql\.jdbc|check the manual that (corresponds|fits) to your (MySQL|MariaDB) server version)`)},
+	{"PostgreSQL", regexp.MustCompile(`(?i)(PostgreSQL.*ERROR|Warning.*\Wpg_|valid PostgreSQL result|Npgsql\.|PG::SyntaxError|org\.postgresql\.util\.PSQLException|ERROR:\s+syntax error at or near)`)},
+	{"MSSQL", regexp.MustCompile(`(?i)(Driver.* SQL[\-_ ]*Server|OLE DB.* SQL Server|Warning.*(mssql|sqlsrv)_|System\.Data\.SqlClient\.|Unclosed quotation mark after the character string|Microsoft SQL Native Client error)`)},
+	{"Oracle", regexp.MustCompile(`(?i)(\bORA-\d{5}|Oracle error|Oracle.*Driver|Warning.*\Woci_|quoted string not properly terminated)`)},
+	{"SQLite", regexp.MustCompile(`(?i)(SQLite/JDBCDriver|SQLite\.Exception|System\.Data\.SQLite\.SQLiteException|Warning.*sqlite_|\[SQLITE_ERROR\]|SQLite error \d+:|sqlite3\.OperationalError|unrecognized token:)`)},
+}
+
+// sqliBooleanContexts are pairs of true and false conditions for every
+// kind of quoting. The value is vulnerable in a context when all its true
+// conditions give the baseline page and false ones a different page.
+var sqliBooleanContexts = []struct {
+	name  string
+	pairs [][2]string
+}{
+	{"string", [][2]string{{"' AND '1'='1", "' AND '1'='2"}, {"' AND '2'>'1", "' AND '1'>'2"}}},
+	{"number", [][2]string{{" AND 1=1", " AND 1=2"}, {" AND 2>1", " AND 1>2"}}},
+	{"double-quoted string", [][2]string{{"\" AND \"1\"=\"1", "\" AND \"1\"=\"2"}, {"\" AND \"2\">\"1", "\" AND \"1\">\"2"}}},
+}
+
+// sqliSleeps ask the database to sleep, SQLite has no such function.
+var sqliSleeps = []struct {
+	dbms   string
+	format string
+}{
+	{"MySQL", "%s AND (SELECT 1 FROM (SELECT SLEEP(%d))x)-- -"},
+	{"PostgreSQL", "%s AND 1=(SELECT 1 FROM PG_SLEEP(%d))-- -"},
+	{"MSSQL", "%s;WAITFOR DELAY '0:0:%d'-- -"},
+	{"Oracle", "%s AND 1=DBMS_PIPE.RECEIVE_MESSAGE('a',%d)-- -"},
+}
+
+var sqliSleepQuotes = []string{"'", ""}
+
+type sqliMutation struct {
+	technique string
+	// boolean-based
+	context int
+	pair    int
+	truth   bool
+	// time-based
+	sleep int
+	quote string
+	delay time.Duration
+}
+
+// SQLInjection finds SQL injections by DBMS error messages, by different
+// responses to true and false conditions and by delays the database is
+// asked for.
+type SQLInjection struct {
+	delay int
+}
+
+// NewSQLInjection takes the shortest delay of time-based payloads, twice
+// as long one is sent too.
+func NewSQLInjection(delay time.Duration) *SQLInjection {
+	return &SQLInjection{delay: max(int(delay/time.Second), 1)}
+}
+
+func (s *SQLInjection) ID() string   { return "sqli" }
+func (s *SQLInjection) Name() string { return "SQL injection" }
+
+func (s *SQLInjection) Mutations(point *scanner.Point, _ *scanner.Response) []*scanner.Mutation {
+	var mutations []*scanner.Mutation
+	for _, p := range sqliErrorPayloads {
+		mutations = append(mutations, &scanner.Mutation{
+			Payload: point.Original + p,
+			Data:    &sqliMutation{technique: sqliError},
+		})
+	}
+
+	for i, ctx := range sqliBooleanContexts {
+		for j, pair := range ctx.pairs {
+			for k, cond := range pair {
+				mutations = append(mutations, &scanner.Mutation{
+					Payload: point.Original + cond,
+					Data:    &sqliMutation{technique: sqliBoolean, context: i, pair: j, truth: k == 0},
+				})
+			}
+		}
+	}
+
+	for i, sleep := range sqliSleeps {
+		for _, quote := range sqliSleepQuotes {
+			for _, seconds := range []int{s.delay, 2 * s.delay} {
+				mutations = append(mutations, &scanner.Mutation{
+					Payload: point.Original + fmt.Sprintf(sleep.format, quote, seconds),
+					Data: &sqliMutation{
+						technique: sqliTime,
+						sleep:     i,
+						quote:     quote,
+						delay:     time.Duration(seconds) * time.Second,
+					},
+				})
+			}
+		}
+	}
+	return mutations
+}
+
+// Evaluate reports one finding per point, Detail lists every technique
+// which confirmed it.
+func (s *SQLInjection) Evaluate(point *scanner.Point, baseline *scanner.Response, results []*scanner.Result) []*models.Finding {
+	var finding *models.Finding
+	add := func(payload, evidence, detail string) {
+		if finding == nil {
+			finding = scanner.NewFinding(s, point, models.SeverityHigh, payload, evidence)
+			finding.Detail = detail
+			return
+		}
+		finding.Detail += "; " + detail
+	}
+
+	if payload, evidence, dbms := s.errorBased(baseline, results); payload != "" {
+		add(payload, evidence, sqliError+", "+dbms)
+	}
+	if payload, context := s.booleanBased(point, baseline, results); payload != "" {
+		add(payload, "true and false conditions give different responses", sqliBoolean+", "+context)
+	}
+	if payload, evidence, dbms := s.timeBased(baseline, results); payload != "" {
+		add(payload, evidence, sqliTime+", "+dbms)
+	}
+
+	if finding == nil {
+		return nil
+	}
+	return []*models.Finding{finding}
+}
+
+// errorBased looks for DBMS errors which are not on the baseline page.
+func (s *SQLInjection) errorBased(baseline *scanner.Response, results []*scanner.Result) (string, string, string) {
+	for _, res := range results {
+		if res.Response == nil || res.Mutation.Data.(*sqliMutation).technique != sqliError {
+			continue
+		}
+		for _, e := range sqliErrors {
+			if e.re.Match(baseline.Body) {
+				continue
+			}
+			if loc := e.re.FindIndex(res.Response.Body); loc != nil {
+				evidence := scanner.Evidence(res.Response.Body, res.Response.Body[loc[0]:loc[1]])
+				return res.Mutation.Payload, evidence, e.dbms
+			}
+		}
+	}
+	return "", "", ""
+}
+
+func (s *SQLInjection) booleanBased(point *scanner.Point, baseline *scanner.Response, results []*scanner.Result) (string, string) {
+	type pair struct {
+		truth, falsity *scanner.Result
+	}
+	pairs := make([][]pair, len(sqliBooleanContexts))
+	for i, ctx := range sqliBooleanContexts {
+		pairs[i] = make([]pair, len(ctx.pairs))
+	}
+	for _, res := range results {
+		m := res.Mutation.Data.(*sqliMutation)
+		if m.technique != sqliBoolean {
+			continue
+		}
+		if m.truth {
+			pairs[m.context][m.pair].truth = res
+		} else {
+			pairs[m.context][m.pair].falsity = res
+		}
+	}
+
+contexts:
+	for i, ctx := range pairs {
+		for _, p := range ctx {
+			if p.truth == nil || p.falsity == nil || p.truth.Response == nil || p.falsity.Response == nil {
+				continue contexts
+			}
+			t, f := p.truth.Response, p.falsity.Response
+			if !similar(t, baseline, p.truth.Mutation.Payload, point.Original) ||
+				similar(f, baseline, p.falsity.Mutation.Payload, point.Original) ||
+				similar(f, t, p.falsity.Mutation.Payload, p.truth.Mutation.Payload) {
+				continue contexts
+			}
+		}
+		return ctx[0].falsity.Mutation.Payload, sqliBooleanContexts[i].name
+	}
+	return "", ""
+}
+
+// timeBased takes response times of the baseline and of payloads without
+// sleep as controls.
+func (s *SQLInjection) timeBased(baseline *scanner.Response, results []*scanner.Result) (string, string, string) {
+	controls := []time.Duration{baseline.Duration}
+	type key struct {
+		sleep int
+		quote string
+	}
+	timings := make(map[key][]timing)
+	payloads := make(map[key]string)
+	for _, res := range results {
+		m := res.Mutation.Data.(*sqliMutation)
+		switch {
+		case m.technique != sqliTime:
+			if res.Response != nil {
+				controls = append(controls, res.Response.Duration)
+			}
+		case res.Response != nil:
+			k := key{m.sleep, m.quote}
+			timings[k] = append(timings[k], timing{delay: m.delay, took: res.Response.Duration})
+			if m.delay == time.Duration(s.delay)*time.Second {
+				payloads[k] = res.Mutation.Payload
+			}
+		}
+	}
+
+	for i, sleep := range sqliSleeps {
+		for _, quote := range sqliSleepQuotes {
+			k := key{i, quote}
+			if !delayConfirmed(controls, timings[k]) {
+				continue
+			}
+			return payloads[k], delayEvidence(timings[k]), sleep.dbms
+		}
+	}
+	return "", "", ""
+}
@@ -0,0 +1,49 @@
+package checks
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var mysqlSleep = regexp.MustCompile(`SLEEP\((\d+)\)`)
+
+func TestSQLInjection(t *testing.T) {
+	tests := []struct {
+		name   string
+		page   vulnerable
+		detail string
+	}{
+		{"error", func(w http.ResponseWriter, q string) {
+			if strings.Count(q, "'")%2 == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintln(w, "You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version")
+				return
+			}
+			fmt.Fprintf(w, "<p>item %s</p>\n", q)
+		}, "error-based, MySQL"},
+		// SELECT * FROM items WHERE id = q
+		{"boolean", func(w http.ResponseWriter, q string) {
+			switch q {
+			case "1", "1 AND 1=1", "1 AND 2>1":
+				fmt.Fprintln(w, "<h1>Item 1</h1>\n<p>price 10</p>\n<p>in stock</p>")
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintln(w, "<h1>No such item</h1>")
+			}
+		}, "boolean-based, number"},
+		{"time", func(w http.ResponseWriter, q string) {
+			sleepFor(mysqlSleep, q)
+			fmt.Fprintln(w, "<p>ok</p>")
+		}, "time-based, MySQL"},
+		{"safe", func(w http.ResponseWriter, q string) {
+			fmt.Fprintln(w, "<p>item 1</p>")
+		}, ""},
+	}
+	for _, tt := range tests {
+		findings := scan(t, NewSQLInjection(testDelay), tt.page, "1")
+		checkFindings(t, tt.name, findings, tt.detail)
+	}
+}
@@ -0,0 +1,142 @@
+package checks
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/oob"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner"
+)
+
+// ssrfWords are in names of parameters which usually take an address the
+// server fetches.
+var ssrfWords = []string{"url", "uri", "link", "src", "source", "host", "domain", "site", "callback", "webhook", "feed", "proxy", "fetch", "load", "image", "img", "dest", "target"}
+
+// ssrfTargets are addresses only the server can reach and signatures of
+// what they return.
+var ssrfTargets = []struct {
+	detail string
+	url    string
+	re     *regexp.Regexp
+}{
+	{"cloud metadata, AWS", "http://169.254.169.254/latest/meta-data/", regexp.MustCompile(`(?m)^(ami-id|instance-id|local-ipv4|iam/)$`)},
+	{"cloud metadata, GCP", "http://metadata.google.internal/computeMetadata/v1/", regexp.MustCompile(`(?m)^(instance|project)/$`)},
+	{"cloud metadata, Alibaba", "http://100.100.100.200/latest/meta-data/", regexp.MustCompile(`(?m)^(instance-id|hostname|image-id)$`)},
+	{"local file", "file:///etc/passwd", regexp.MustCompile(`root:[^:\r\n]*:0:0:`)},
+}
+
+// ssrfLoopback are ways to write the address of the server itself,
+// ssrfClosed is the same host on a port nothing listens on.
+var (
+	ssrfLoopback = []string{"http://127.0.0.1/", "http://localhost/", "http://[::1]/", "http://2130706433/", "http://0x7f000001/"}
+	ssrfClosed   = "http://127.0.0.1:1/"
+)
+
+const (
+	ssrfTarget = iota
+	ssrfLocal
+	ssrfControl
+)
+
+type ssrfMutation struct {
+	kind   int
+	target int
+}
+
+// SSRF puts addresses into parameters which look like URLs. With the
+// out-of-band server the server is asked to call it, the call confirms the
+// finding. Cloud metadata and local files are found by their content, the
+// loopback address by response different from one of a closed port.
+type SSRF struct {
+	oob *oob.Server
+}
+
+// NewSSRF takes nil server when out-of-band payloads are off.
+func NewSSRF(srv *oob.Server) *SSRF {
+	return &SSRF{oob: srv}
+}
+
+func (s *SSRF) ID() string   { return "ssrf" }
+func (s *SSRF) Name() string { return "Server-side request forgery" }
+
+func (s *SSRF) Mutations(point *scanner.Point, _ *scanner.Response) []*scanner.Mutation {
+	if !urlValue(point.Original) && !nameHas(point.Name, ssrfWords) {
+		return nil
+	}
+
+	var mutations []*scanner.Mutation
+	if s.oob.HTTP() {
+		mutations = append(mutations, s.oobMutation(point, "%s", s.oob.URL))
+	}
+	if s.oob.DNS() {
+		mutations = append(mutations, s.oobMutation(point, "http://%s/", s.oob.Domain))
+	}
+
+	for i, t := range ssrfTargets {
+		mutations = append(mutations, &scanner.Mutation{Payload: t.url, Data: &ssrfMutation{kind: ssrfTarget, target: i}})
+	}
+	for _, u := range ssrfLoopback {
+		mutations = append(mutations, &scanner.Mutation{Payload: u, Data: &ssrfMutation{kind: ssrfLocal}})
+	}
+	mutations = append(mutations, &scanner.Mutation{Payload: ssrfClosed, Data: &ssrfMutation{kind: ssrfControl}})
+	return mutations
+}
+
+// oobMutation makes payload calling the unique address and tracks it.
+func (s *SSRF) oobMutation(point *scanner.Point, format string, address func(id string) string) *scanner.Mutation {
+	id := s.oob.NewID()
+	payload := fmt.Sprintf(format, address(id))
+	s.oob.Track(id, point.ScanID, scanner.NewFinding(s, point, models.SeverityHigh, payload, ""))
+	return &scanner.Mutation{Payload: payload}
+}
+
+// Evaluate reports content of internal targets missing in the baseline.
+// Otherwise the loopback is reported when at least two of its addresses
+// give the same response and the closed port a different one.
+func (s *SSRF) Evaluate(point *scanner.Point, baseline *scanner.Response, results []*scanner.Result) []*models.Finding {
+	var local []*scanner.Result
+	var control *scanner.Result
+	for _, res := range results {
+		m, ok := res.Mutation.Data.(*ssrfMutation)
+		if !ok || res.Response == nil {
+			continue
+		}
+		switch m.kind {
+		case ssrfTarget:
+			t := ssrfTargets[m.target]
+			if t.re.Match(baseline.Body) {
+				continue
+			}
+			if loc := t.re.FindIndex(res.Response.Body); loc != nil {
+				f := scanner.NewFinding(s, point, models.SeverityHigh, res.Mutation.Payload, scanner.EvidenceAt(res.Response.Body, loc[0], loc[1]-loc[0]))
+				f.Detail = t.detail
+				return []*models.Finding{f}
+			}
+		case ssrfLocal:
+			local = append(local, res)
+		case ssrfControl:
+			control = res
+		}
+	}
+	if control == nil {
+		return nil
+	}
+
+	var answered []*scanner.Result
+	for _, res := range local {
+		if !similar(res.Response, control.Response, res.Mutation.Payload, control.Mutation.Payload) {
+			answered = append(answered, res)
+		}
+	}
+	for i, a := range answered {
+		for _, b := range answered[i+1:] {
+			if similar(a.Response, b.Response, a.Mutation.Payload, b.Mutation.Payload) {
+				f := scanner.NewFinding(s, point, models.SeverityMedium, a.Mutation.Payload, "loopback answers differently from closed port")
+				f.Detail = "internal address, " + a.Mutation.Payload + " and " + b.Mutation.Payload
+				return []*models.Finding{f}
+			}
+		}
+	}
+	return nil
+}
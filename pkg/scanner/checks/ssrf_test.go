@@ -0,0 +1,119 @@
+package checks
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// fetcher shows what the server got from the URL in q, fetch answers for
+// addresses the test doesn't simulate.
+func fetcher(fetch func(u *url.URL) string) vulnerable {
+	return func(w http.ResponseWriter, q string) {
+		u, err := url.Parse(q)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintln(w, "<p>bad url</p>")
+			return
+		}
+		fmt.Fprintf(w, "<h1>Preview</h1>\n<pre>%s</pre>\n", fetch(u))
+	}
+}
+
+func TestSSRF(t *testing.T) {
+	tests := []struct {
+		name   string
+		page   vulnerable
+		detail string
+	}{
+		{"metadata", fetcher(func(u *url.URL) string {
+			if u.Host == "169.254.169.254" && strings.HasPrefix(u.Path, "/latest/meta-data") {
+				return "ami-id\nhostname\ninstance-id\nlocal-ipv4"
+			}
+			return "image"
+		}), "cloud metadata, AWS"},
+		{"file", fetcher(func(u *url.URL) string {
+			if u.Scheme == "file" && u.Path == "/etc/passwd" {
+				return passwd
+			}
+			return "image"
+		}), "local file"},
+		{"loopback", fetcher(func(u *url.URL) string {
+			switch u.Host {
+			case "127.0.0.1", "localhost", "[::1]", "2130706433", "0x7f000001":
+				return "admin panel"
+			case "127.0.0.1:1":
+				return "connection refused"
+			}
+			return "image"
+		}), "internal address"},
+		{"allow list", fetcher(func(u *url.URL) string {
+			if u.Host != "cdn.example.com" {
+				return "host is not allowed"
+			}
+			return "image"
+		}), ""},
+		{"every fetch fails", fetcher(func(u *url.URL) string {
+			return "can't fetch " + u.String()
+		}), ""},
+	}
+	for _, tt := range tests {
+		findings := scan(t, NewSSRF(nil), tt.page, "https://cdn.example.com/logo.png")
+		checkFindings(t, tt.name, findings, tt.detail)
+	}
+
+	// values which are not URLs in parameters not named like them are skipped
+	findings := scan(t, NewSSRF(nil), fetcher(func(u *url.URL) string { return passwd }), "shoes")
+	checkFindings(t, "not a url", findings, "")
+}
+
+func TestOpenRedirect(t *testing.T) {
+	tests := []struct {
+		name   string
+		page   vulnerable
+		detail string
+	}{
+		{"location", func(w http.ResponseWriter, q string) {
+			w.Header().Set("Location", q)
+			w.WriteHeader(http.StatusFound)
+		}, "location header"},
+		{"refresh", func(w http.ResponseWriter, q string) {
+			w.Header().Set("Refresh", "0; url="+q)
+			fmt.Fprintln(w, "<p>redirecting</p>")
+		}, "refresh header"},
+		{"meta refresh", func(w http.ResponseWriter, q string) {
+			fmt.Fprintf(w, `<meta http-equiv="refresh" content="0; url=%s">`, q)
+		}, "meta refresh"},
+		{"javascript", func(w http.ResponseWriter, q string) {
+			fmt.Fprintf(w, `<script>window.location = "%s";</script>`, q)
+		}, "javascript"},
+		// the check of the prefix is passed by "https://host.attacker/"
+		{"prefix", func(w http.ResponseWriter, q string) {
+			if !strings.HasPrefix(q, "https://127.0.0.1") {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintln(w, "<p>bad redirect</p>")
+				return
+			}
+			w.Header().Set("Location", q)
+			w.WriteHeader(http.StatusFound)
+		}, "location header"},
+		{"local only", func(w http.ResponseWriter, q string) {
+			if !strings.HasPrefix(q, "/") || strings.HasPrefix(q, "//") || strings.HasPrefix(q, `/\`) {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintln(w, "<p>bad redirect</p>")
+				return
+			}
+			w.Header().Set("Location", q)
+			w.WriteHeader(http.StatusFound)
+		}, ""},
+		{"escaped", func(w http.ResponseWriter, q string) {
+			fmt.Fprintf(w, `<p>Continue to %s</p>`, q)
+		}, ""},
+	}
+	for _, tt := range tests {
+		findings := scan(t, NewOpenRedirect(), tt.page, "/account")
+		checkFindings(t, tt.name, findings, tt.detail)
+	}
+}
@@ -0,0 +1,165 @@
+package checks
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner"
+)
+
+// sstiPolyglot breaks the syntax of every common template engine, they
+// report the error.
+const sstiPolyglot = "${{<%[%'\"}}%\\."
+
+// sstiErrors are template errors of the engines.
+var sstiErrors = []struct {
+	engine string
+	re     *regexp.Regexp
+}{
+	{"Jinja2", regexp.MustCompile(`jinja2\.exceptions\.|TemplateSyntaxError`)},
+	{"Twig", regexp.MustCompile(`Twig[\\_]Error`)},
+	{"Freemarker", regexp.MustCompile(`freemarker\.core\.|FreeMarker template error`)},
+	{"Go templates", regexp.MustCompile(`template: [^:\s]*:\d+:(\d+:)? (unexpected|function|bad|unterminated|unclosed)`)},
+	{"ERB", regexp.MustCompile(`\(erb\):\d+`)},
+}
+
+// sstiSyntaxes are expression syntaxes in the order they are reported.
+// Probes of one syntax evaluate arithmetic or string functions, engine
+// probes tell engines with the same syntax apart.
+var sstiSyntaxes = []string{"{{ }}", "${ }", "<%= %>", "#{ }", "Go {{ }}"}
+
+// sstiNames are engines reported when no engine probe of the syntax
+// evaluated.
+var sstiNames = map[string]string{
+	"{{ }}":    "Jinja2 or Twig like",
+	"${ }":     "expression language or Freemarker like",
+	"<%= %>":   "ERB or EJS like",
+	"#{ }":     "expression language or Ruby interpolation",
+	"Go {{ }}": "Go templates",
+}
+
+// sstiOutput is the output of the engine which evaluates the payload,
+// engine is empty when the output doesn't tell engines apart.
+type sstiOutput struct {
+	engine string
+	out    string
+}
+
+type sstiMutation struct {
+	polyglot bool
+	syntax   string
+	expect   []sstiOutput
+}
+
+// SSTI sends expressions with random numbers and strings in the syntax of
+// template engines and looks for their results in the response. The syntax
+// which evaluated and outputs of engine specific expressions fingerprint
+// the engine. A polyglot breaking every syntax finds engines by errors.
+type SSTI struct{}
+
+func NewSSTI() *SSTI {
+	return &SSTI{}
+}
+
+func (s *SSTI) ID() string   { return "ssti" }
+func (s *SSTI) Name() string { return "Server-side template injection" }
+
+func (s *SSTI) Mutations(point *scanner.Point, _ *scanner.Response) []*scanner.Mutation {
+	// outputs found in the baseline or the payload are ignored, random
+	// numbers make them unlikely there
+	a, b, n, k := 101+rand.IntN(899), 101+rand.IntN(899), 3+rand.IntN(7), 101+rand.IntN(899)
+	product, repeated, multiplied := strconv.Itoa(a*b), strings.Repeat(strconv.Itoa(k), n), strconv.Itoa(n*k)
+	t1, t2 := "mitm"+strconv.Itoa(a), "ssti"+strconv.Itoa(b)
+
+	mutations := []*scanner.Mutation{{Payload: point.Original + sstiPolyglot, Data: &sstiMutation{polyglot: true}}}
+	add := func(syntax, payload string, expect ...sstiOutput) {
+		mutations = append(mutations, &scanner.Mutation{
+			Payload: point.Original + payload,
+			Data:    &sstiMutation{syntax: syntax, expect: expect},
+		})
+	}
+	add("{{ }}", fmt.Sprintf("{{%d*%d}}", a, b), sstiOutput{"", product})
+	add("{{ }}", fmt.Sprintf("{{%d*'%d'}}", n, k), sstiOutput{"Jinja2", repeated}, sstiOutput{"Twig", multiplied})
+	// Freemarker groups digits of ${a*b}, ?c prints the number as is
+	add("${ }", fmt.Sprintf("${%d*%d}", a, b), sstiOutput{"", product})
+	add("${ }", fmt.Sprintf("${(%d*%d)?c}", a, b), sstiOutput{"Freemarker", product})
+	add("<%= %>", fmt.Sprintf("<%%= %d*%d %%>", a, b), sstiOutput{"", product})
+	add("<%= %>", fmt.Sprintf("<%%= '%d'*%d %%>", k, n), sstiOutput{"ERB", repeated}, sstiOutput{"EJS", multiplied})
+	add("#{ }", fmt.Sprintf("#{%d*%d}", a, b), sstiOutput{"", product})
+	add("Go {{ }}", fmt.Sprintf("{{print %q %q}}", t1, t2), sstiOutput{"Go templates", t1 + t2})
+	return mutations
+}
+
+// Evaluate reports the first syntax which evaluated, with the engine its
+// engine probes point to. Template errors of the polyglot are reported
+// only when nothing evaluated.
+func (s *SSTI) Evaluate(point *scanner.Point, baseline *scanner.Response, results []*scanner.Result) []*models.Finding {
+	type evaluated struct {
+		payload, evidence, engine string
+	}
+	bySyntax := make(map[string]*evaluated)
+	var polyglot *scanner.Result
+	for _, res := range results {
+		m := res.Mutation.Data.(*sstiMutation)
+		if res.Response == nil {
+			continue
+		}
+		if m.polyglot {
+			polyglot = res
+			continue
+		}
+		for _, o := range m.expect {
+			engine, out := o.engine, o.out
+			i := bytes.Index(res.Response.Body, []byte(out))
+			if i < 0 || bytes.Contains(baseline.Body, []byte(out)) || strings.Contains(res.Mutation.Payload, out) {
+				continue
+			}
+			e := bySyntax[m.syntax]
+			if e == nil {
+				e = &evaluated{}
+				bySyntax[m.syntax] = e
+			}
+			if e.payload == "" || engine != "" && e.engine == "" {
+				e.payload, e.evidence = res.Mutation.Payload, scanner.EvidenceAt(res.Response.Body, i, len(out))
+			}
+			if engine != "" {
+				e.engine = engine
+			}
+			break
+		}
+	}
+
+	for _, syntax := range sstiSyntaxes {
+		e := bySyntax[syntax]
+		if e == nil {
+			continue
+		}
+		engine := e.engine
+		if engine == "" {
+			engine = sstiNames[syntax]
+		}
+		f := scanner.NewFinding(s, point, models.SeverityHigh, e.payload, e.evidence)
+		f.Detail = engine + ", " + syntax + " evaluated"
+		return []*models.Finding{f}
+	}
+
+	if polyglot == nil {
+		return nil
+	}
+	for _, e := range sstiErrors {
+		if e.re.Match(baseline.Body) {
+			continue
+		}
+		if loc := e.re.FindIndex(polyglot.Response.Body); loc != nil {
+			f := scanner.NewFinding(s, point, models.SeverityHigh, polyglot.Mutation.Payload, scanner.EvidenceAt(polyglot.Response.Body, loc[0], loc[1]-loc[0]))
+			f.Detail = e.engine + ", template error"
+			return []*models.Finding{f}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package checks
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+// arithmetic evaluates products in the syntax re matches, a product of
+// a quoted number is the string of them.
+func arithmetic(re *regexp.Regexp, str func(a, b int) string) vulnerable {
+	return func(w http.ResponseWriter, q string) {
+		out := re.ReplaceAllStringFunc(q, func(expr string) string {
+			m := re.FindStringSubmatch(expr)
+			a, _ := strconv.Atoi(m[1])
+			b, _ := strconv.Atoi(m[3])
+			if m[2] != "" {
+				return str(a, b)
+			}
+			return strconv.Itoa(a * b)
+		})
+		fmt.Fprintf(w, "<p>Hello %s</p>", out)
+	}
+}
+
+func TestSSTI(t *testing.T) {
+	tests := []struct {
+		name   string
+		page   vulnerable
+		detail string
+	}{
+		{"jinja2", arithmetic(regexp.MustCompile(`\{\{(\d+)\*(')?(\d+)'?\}\}`), func(n, s int) string {
+			return strings.Repeat(strconv.Itoa(s), n)
+		}), "Jinja2, {{ }} evaluated"},
+		{"erb", arithmetic(regexp.MustCompile(`<%= '?(\d+)(')?\*(\d+) %>`), func(s, n int) string {
+			return strings.Repeat(strconv.Itoa(s), n)
+		}), "ERB, <%= %> evaluated"},
+		{"go", func(w http.ResponseWriter, q string) {
+			tmpl, err := template.New("page").Parse("<p>Hello " + q + "</p>")
+			if err == nil {
+				err = tmpl.Execute(w, nil)
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		}, "Go templates, Go {{ }} evaluated"},
+		{"error", func(w http.ResponseWriter, q string) {
+			if strings.Contains(q, "{{<%") {
+				http.Error(w, "jinja2.exceptions.TemplateSyntaxError: unexpected '<'", http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprintf(w, "<p>Hello %s</p>", q)
+		}, "Jinja2, template error"},
+		{"safe", func(w http.ResponseWriter, q string) {
+			fmt.Fprintf(w, "<p>Hello %s</p>", q)
+		}, ""},
+	}
+	for _, tt := range tests {
+		findings := scan(t, NewSSTI(), tt.page, "guest")
+		checkFindings(t, tt.name, findings, tt.detail)
+	}
+}
@@ -0,0 +1,73 @@
+package checks
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// timing is the response time of a payload which asked for delay.
+type timing struct {
+	delay time.Duration
+	took  time.Duration
+}
+
+// delayConfirmed tells whether the server slept as asked. Controls are
+// response times of requests without sleep, their mean plus three standard
+// deviations must stay below the shortest delay, otherwise the host is too
+// noisy to tell. Every delayed response must be slower than the mean by
+// the delay, less 10% of jitter, and longer delays must take longer, so a
+// single slow response proves nothing.
+func delayConfirmed(controls []time.Duration, timings []timing) bool {
+	if len(controls) < 2 || len(timings) < 2 {
+		return false
+	}
+
+	var sum float64
+	for _, c := range controls {
+		sum += float64(c)
+	}
+	mean := sum / float64(len(controls))
+	var variance float64
+	for _, c := range controls {
+		variance += (float64(c) - mean) * (float64(c) - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(controls)-1))
+
+	shortest := timings[0].delay
+	for _, t := range timings {
+		shortest = min(shortest, t.delay)
+	}
+	if mean+3*stddev >= float64(shortest) {
+		return false
+	}
+
+	for _, a := range timings {
+		if float64(a.took)-mean < 0.9*float64(a.delay) {
+			return false
+		}
+		for _, b := range timings {
+			if a.delay > b.delay && a.took <= b.took {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// delayEvidence lists measured response times of delayed payloads from the
+// shortest delay.
+func delayEvidence(timings []timing) string {
+	timings = slices.Clone(timings)
+	slices.SortStableFunc(timings, func(a, b timing) int {
+		return cmp.Compare(a.delay, b.delay)
+	})
+	took := make([]string, len(timings))
+	for i, t := range timings {
+		took[i] = fmt.Sprintf("%v in %v", t.delay, t.took.Round(time.Millisecond))
+	}
+	return "asked delays took " + strings.Join(took, ", ")
+}
@@ -0,0 +1,169 @@
+package checks
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner"
+)
+
+// travDepth is the number of steps up, extra ones stop at the root.
+const travDepth = 8
+
+// travFiles are files every host of the OS has and signatures of their
+// content.
+var travFiles = []struct {
+	os       string
+	path     string
+	absolute string
+	re       *regexp.Regexp
+}{
+	{"unix", "etc/passwd", "/etc/passwd", regexp.MustCompile(`root:[^:\r\n]*:0:0:`)},
+	{"windows", "windows/win.ini", `C:\windows\win.ini`, regexp.MustCompile(`(?i)(; for 16-bit app support|\[mci extensions\])`)},
+}
+
+// travSteps are ways to write a step up. Encoded ones pass filters which
+// look at the value before the application decodes it once more, "....//"
+// is left as "../" by filters removing "../" once. Sep separates names of
+// the file path the same way.
+var travSteps = []struct {
+	os   string
+	step string
+	sep  string
+}{
+	{"", "../", "/"},
+	{"unix", "..%2f", "%2f"},
+	{"unix", "%2e%2e%2f", "%2f"},
+	{"unix", "%252e%252e%252f", "%252f"},
+	{"unix", "....//", "/"},
+	{"unix", "..%c0%af", "/"},
+	{"windows", `..\`, `\`},
+	{"windows", "..%5c", "%5c"},
+}
+
+// travSuffix makes a missing file out of the original value, real paths
+// don't end with it.
+const travSuffix = ".mitmtrav"
+
+const (
+	travFile = iota
+	// travSame is the original value written as another path to the same
+	// file.
+	travSame
+	// travMissing is a path to a file which doesn't exist.
+	travMissing
+)
+
+type travMutation struct {
+	kind int
+	file int
+}
+
+// PathTraversal reads files outside of the directory the value points into
+// with relative, encoded and absolute paths, and finds their content in the
+// response. Values which look like paths are also checked by responses to
+// paths of the same and of a missing file.
+type PathTraversal struct{}
+
+func NewPathTraversal() *PathTraversal {
+	return &PathTraversal{}
+}
+
+func (t *PathTraversal) ID() string   { return "traversal" }
+func (t *PathTraversal) Name() string { return "Path traversal" }
+
+func (t *PathTraversal) Mutations(point *scanner.Point, _ *scanner.Response) []*scanner.Mutation {
+	var mutations []*scanner.Mutation
+	add := func(payload string, data *travMutation) {
+		mutations = append(mutations, &scanner.Mutation{Payload: payload, Data: data})
+	}
+
+	// the value may have to keep its directory or extension
+	dir, ext := "", path.Ext(point.Original)
+	if i := strings.LastIndexAny(point.Original, `/\`); i > 0 {
+		dir = point.Original[:i+1]
+	}
+
+	for i, file := range travFiles {
+		data := &travMutation{kind: travFile, file: i}
+		for _, s := range travSteps {
+			if s.os == "" || s.os == file.os {
+				add(strings.Repeat(s.step, travDepth)+strings.ReplaceAll(file.path, "/", s.sep), data)
+			}
+		}
+
+		up := strings.Repeat("../", travDepth) + file.path
+		add(file.absolute, data)
+		add(up+"\x00", data)
+		if ext != "" {
+			add(up+"\x00"+ext, data)
+		}
+		if dir != "" {
+			add(dir+up, data)
+		}
+	}
+	add("file://"+travFiles[0].absolute, &travMutation{kind: travFile, file: 0})
+
+	if looksLikePath(point.Original) {
+		add("./"+point.Original, &travMutation{kind: travSame})
+		add("././"+point.Original, &travMutation{kind: travSame})
+		add("./"+point.Original+travSuffix, &travMutation{kind: travMissing})
+	}
+	return mutations
+}
+
+// Evaluate reports file content missing in the baseline. Without it the
+// value is reported as a file path when paths of the same file give the
+// baseline page and the missing file a different one.
+func (t *PathTraversal) Evaluate(point *scanner.Point, baseline *scanner.Response, results []*scanner.Result) []*models.Finding {
+	for _, res := range results {
+		m := res.Mutation.Data.(*travMutation)
+		if res.Response == nil || m.kind != travFile {
+			continue
+		}
+		file := travFiles[m.file]
+		if file.re.Match(baseline.Body) {
+			continue
+		}
+		if loc := file.re.FindIndex(res.Response.Body); loc != nil {
+			f := scanner.NewFinding(t, point, models.SeverityHigh, res.Mutation.Payload, scanner.EvidenceAt(res.Response.Body, loc[0], loc[1]-loc[0]))
+			f.Detail = "file content, " + file.absolute
+			return []*models.Finding{f}
+		}
+	}
+
+	var same, missing []*scanner.Result
+	for _, res := range results {
+		switch res.Mutation.Data.(*travMutation).kind {
+		case travSame:
+			same = append(same, res)
+		case travMissing:
+			missing = append(missing, res)
+		}
+	}
+	if len(same) == 0 || len(missing) == 0 {
+		return nil
+	}
+	for _, res := range same {
+		if res.Response == nil || !similar(res.Response, baseline, res.Mutation.Payload, point.Original) {
+			return nil
+		}
+	}
+	for _, res := range missing {
+		if res.Response == nil || similar(res.Response, baseline, res.Mutation.Payload, point.Original) {
+			return nil
+		}
+	}
+
+	f := scanner.NewFinding(t, point, models.SeverityMedium, same[0].Mutation.Payload, "same file gives the same response, missing file a different one")
+	f.Detail = "response differences, value is a file path"
+	return []*models.Finding{f}
+}
+
+// looksLikePath tells whether the value names a file, ids and words are
+// often the same after "./" is added, only the lookup drops it.
+func looksLikePath(value string) bool {
+	return strings.ContainsAny(value, `/\`) || path.Ext(value) != ""
+}
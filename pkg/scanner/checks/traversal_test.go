@@ -0,0 +1,54 @@
+package checks
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+)
+
+const passwd = "root:x:0:0:root:/root:/bin/bash\ndaemon:x:1:1:daemon:/usr/sbin:/usr/sbin/nologin\n"
+
+// files serves files under /srv/files, open tells whether reading outside
+// of it is possible.
+func files(open bool) vulnerable {
+	return func(w http.ResponseWriter, q string) {
+		name := path.Clean("/srv/files/" + q)
+		switch {
+		case name == "/etc/passwd" && open:
+			fmt.Fprint(w, passwd)
+		case name == "/srv/files/docs/report.txt":
+			fmt.Fprint(w, "<h1>Report</h1>\n<p>quarterly numbers</p>\n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<h1>Not found</h1>\n")
+		}
+	}
+}
+
+func TestPathTraversal(t *testing.T) {
+	tests := []struct {
+		name   string
+		page   vulnerable
+		value  string
+		detail string
+	}{
+		{"file", files(true), "docs/report.txt", "file content, /etc/passwd"},
+		{"file path", files(false), "docs/report.txt", "value is a file path"},
+		// a filter removing "../" once leaves "....//" as "../"
+		{"filtered", func(w http.ResponseWriter, q string) {
+			files(true)(w, strings.ReplaceAll(q, "../", ""))
+		}, "docs/report.txt", "file content, /etc/passwd"},
+		{"passwd in page", func(w http.ResponseWriter, q string) {
+			fmt.Fprint(w, passwd)
+		}, "docs/report.txt", ""},
+		{"not a path", func(w http.ResponseWriter, q string) {
+			fmt.Fprintf(w, "<p>%d results</p>\n", len(q))
+		}, "shoes", ""},
+	}
+	for _, tt := range tests {
+		findings := scan(t, NewPathTraversal(), tt.page, tt.value)
+		checkFindings(t, tt.name, findings, tt.detail)
+	}
+}
@@ -0,0 +1,42 @@
+package checks
+
+import (
+	"net/url"
+	"strings"
+)
+
+// urlValue tells whether the value is an absolute or a scheme-relative URL.
+func urlValue(value string) bool {
+	if strings.HasPrefix(value, "//") {
+		return true
+	}
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// nameHas tells whether the parameter name contains one of the words, case
+// and separators are ignored.
+func nameHas(name string, words []string) bool {
+	name = strings.ToLower(strings.NewReplacer("-", "", "_", "", ".", "").Replace(name))
+	for _, w := range words {
+		if strings.Contains(name, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve makes the URL a browser would go to from the page at base.
+// Browsers drop tabs and newlines and read backslashes as slashes.
+func resolve(base, ref string) *url.URL {
+	ref = strings.NewReplacer("\t", "", "\n", "", "\r", "", `\`, "/").Replace(strings.TrimSpace(ref))
+	r, err := url.Parse(ref)
+	if err != nil {
+		return nil
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return r
+	}
+	return b.ResolveReference(r)
+}
@@ -0,0 +1,251 @@
+package checks
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner"
+)
+
+const (
+	// canaryPrefix starts every canary, so captured pages are searched for
+	// one string whatever number of canaries is issued.
+	canaryPrefix = "mitmxss"
+	canaryLength = len(canaryPrefix) + 8
+
+	// maxCanaries limits canaries kept for stored XSS, oldest ones are
+	// forgotten first.
+	maxCanaries = 10000
+	// maxReflections limits reflections of one string examined on a page.
+	maxReflections = 10
+)
+
+// xssPayload breaks out of context, where the canary is reflected in, if
+// it comes back unencoded. Format takes the canary.
+type xssPayload struct {
+	context  string
+	quote    byte
+	anyQuote bool
+	url      bool
+	format   string
+}
+
+var xssPayloads = []xssPayload{
+	{context: ctxText, format: "<%[1]s>"},
+	{context: ctxAttribute, quote: '"', format: `%[1]s" %[1]s=x`},
+	{context: ctxAttribute, quote: '\'', format: `%[1]s' %[1]s=x`},
+	{context: ctxAttribute, format: `%[1]s %[1]s=x`},
+	{context: ctxAttribute, anyQuote: true, url: true, format: `javascript:%[1]s//`},
+	{context: ctxScript, quote: '\'', format: `%[1]s'-%[1]s-'`},
+	{context: ctxScript, quote: '"', format: `%[1]s"-%[1]s-"`},
+	{context: ctxScript, anyQuote: true, format: `</script><%[1]s>`},
+}
+
+// matches tells whether the payload breaks out of ctx.
+func (p *xssPayload) matches(ctx htmlContext) bool {
+	return ctx.name == p.context && (p.anyQuote || ctx.quote == p.quote) && (!p.url || ctx.url)
+}
+
+// xssMutation is the plain canary when payload is nil.
+type xssMutation struct {
+	payload *xssPayload
+}
+
+// canary is a value the scan put into the point, host is where it may show
+// up again.
+type canary struct {
+	host   string
+	method string
+	url    string
+	point  *scanner.Point
+	// seen are pages the stored XSS was already reported for
+	seen map[string]bool
+}
+
+// XSS sends a unique canary to every point, classifies HTML contexts it is
+// reflected in and confirms them with payloads breaking out of the context.
+// Canaries are kept, so pages captured later which show them reveal stored
+// XSS.
+type XSS struct {
+	mu       sync.Mutex
+	canaries map[string]*canary
+	order    []string
+}
+
+func NewXSS() *XSS {
+	return &XSS{
+		canaries: make(map[string]*canary),
+	}
+}
+
+func (x *XSS) ID() string   { return "xss" }
+func (x *XSS) Name() string { return "Cross-site scripting" }
+
+func (x *XSS) Mutations(point *scanner.Point, _ *scanner.Response) []*scanner.Mutation {
+	token := x.newCanary(point)
+	mutations := []*scanner.Mutation{{Payload: token, Data: &xssMutation{}}}
+	for i := range xssPayloads {
+		p := &xssPayloads[i]
+		mutations = append(mutations, &scanner.Mutation{
+			Payload: fmt.Sprintf(p.format, token),
+			Data:    &xssMutation{payload: p},
+		})
+	}
+	return mutations
+}
+
+// Evaluate reports the point when any payload breaks out of the context it
+// is reflected in. Reflections of the plain canary are listed in Detail.
+func (x *XSS) Evaluate(point *scanner.Point, _ *scanner.Response, results []*scanner.Result) []*models.Finding {
+	var reflected []string
+	for _, res := range results {
+		if res.Response == nil || !isHTML(res.Response) || res.Mutation.Data.(*xssMutation).payload != nil {
+			continue
+		}
+		for _, ctx := range reflections(res.Response.Body, res.Mutation.Payload) {
+			if s := ctx.String(); !slices.Contains(reflected, s) {
+				reflected = append(reflected, s)
+			}
+		}
+	}
+
+	for _, res := range results {
+		p := res.Mutation.Data.(*xssMutation).payload
+		if res.Response == nil || !isHTML(res.Response) || p == nil {
+			continue
+		}
+		if evidence, ctx, ok := breakout(res.Response.Body, res.Mutation.Payload, p); ok {
+			f := scanner.NewFinding(x, point, models.SeverityHigh, res.Mutation.Payload, evidence)
+			f.Detail = "breaks out of " + ctx.String()
+			if len(reflected) > 0 {
+				f.Detail = "reflected in " + strings.Join(reflected, ", ") + "; " + f.Detail
+			}
+			return []*models.Finding{f}
+		}
+	}
+	return nil
+}
+
+// Observe looks for canaries in a page captured from the host they were
+// sent to. A canary is reported when one of its payloads breaks out of the
+// context there, once per page.
+func (x *XSS) Observe(parsedReq *models.ParsedRequest, resp *scanner.Response) []*models.Finding {
+	if !isHTML(resp) {
+		return nil
+	}
+
+	var findings []*models.Finding
+	body := resp.Body
+	for n := 0; n < maxReflections; n++ {
+		i := bytes.Index(body, []byte(canaryPrefix))
+		if i < 0 || i+canaryLength > len(body) {
+			break
+		}
+		token := string(body[i : i+canaryLength])
+		body = body[i+len(canaryPrefix):]
+
+		c := x.canary(token, hostname(parsedReq), parsedReq.URL)
+		if c == nil {
+			continue
+		}
+		for j := range xssPayloads {
+			p := &xssPayloads[j]
+			payload := fmt.Sprintf(p.format, token)
+			if evidence, ctx, ok := breakout(resp.Body, payload, p); ok {
+				f := scanner.NewFinding(x, c.point, models.SeverityHigh, payload, evidence)
+				f.Name = "Stored cross-site scripting"
+				f.Detail = fmt.Sprintf("sent with %s %s, shown at %s in %s", c.method, c.url, parsedReq.URL, ctx)
+				findings = append(findings, f)
+				break
+			}
+		}
+	}
+	return findings
+}
+
+func (x *XSS) newCanary(point *scanner.Point) string {
+	b := make([]byte, (canaryLength-len(canaryPrefix))/2)
+	_, _ = rand.Read(b)
+	token := canaryPrefix + hex.EncodeToString(b)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if len(x.order) == maxCanaries {
+		delete(x.canaries, x.order[0])
+		x.order = x.order[1:]
+	}
+	x.canaries[token] = &canary{
+		host:   hostname(point.Request),
+		method: point.Request.Method,
+		url:    point.Request.URL,
+		point:  &scanner.Point{InsertionPoint: point.InsertionPoint, Original: point.Original},
+		seen:   make(map[string]bool),
+	}
+	x.order = append(x.order, token)
+	return token
+}
+
+// canary returns the canary sent to host, which was not reported for page
+// yet, and marks it reported.
+func (x *XSS) canary(token, host, page string) *canary {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	c, ok := x.canaries[token]
+	if !ok || c.host != host || c.seen[page] {
+		return nil
+	}
+	c.seen[page] = true
+	return c
+}
+
+// reflections returns contexts value is reflected in unencoded.
+func reflections(body []byte, value string) []htmlContext {
+	var res []htmlContext
+	offset := 0
+	for range maxReflections {
+		i := bytes.Index(body[offset:], []byte(value))
+		if i < 0 {
+			break
+		}
+		res = append(res, contextAt(body, offset+i))
+		offset += i + len(value)
+	}
+	return res
+}
+
+// breakout looks for the payload reflected unencoded in the context it is
+// made for.
+func breakout(body []byte, payload string, p *xssPayload) (string, htmlContext, bool) {
+	offset := 0
+	for range maxReflections {
+		i := bytes.Index(body[offset:], []byte(payload))
+		if i < 0 {
+			break
+		}
+		if ctx := contextAt(body, offset+i); p.matches(ctx) {
+			return scanner.EvidenceAt(body, offset+i, len(payload)), ctx, true
+		}
+		offset += i + len(payload)
+	}
+	return "", htmlContext{}, false
+}
+
+// isHTML treats responses without Content-Type as HTML, browsers sniff them.
+func isHTML(resp *scanner.Response) bool {
+	ct := resp.Header.Get("Content-Type")
+	return ct == "" || strings.Contains(strings.ToLower(ct), "html")
+}
+
+func hostname(parsedReq *models.ParsedRequest) string {
+	if u, err := url.Parse(parsedReq.URL); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return parsedReq.Host
+}
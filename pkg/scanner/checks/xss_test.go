@@ -0,0 +1,40 @@
+package checks
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"testing"
+)
+
+func TestXSS(t *testing.T) {
+	tests := []struct {
+		name   string
+		page   vulnerable
+		detail string
+	}{
+		{"text", func(w http.ResponseWriter, q string) {
+			fmt.Fprintf(w, "<p>Results for %s</p>", q)
+		}, "breaks out of html text"},
+		{"attribute", func(w http.ResponseWriter, q string) {
+			fmt.Fprintf(w, `<input name="q" value="%s">`, q)
+		}, "breaks out of double-quoted attribute"},
+		{"script", func(w http.ResponseWriter, q string) {
+			fmt.Fprintf(w, "<script>var q = '%s';</script>", q)
+		}, "breaks out of single-quoted script string"},
+		{"link", func(w http.ResponseWriter, q string) {
+			fmt.Fprintf(w, `<a href="%s">back</a>`, html.EscapeString(q))
+		}, "breaks out of url attribute"},
+		{"escaped", func(w http.ResponseWriter, q string) {
+			fmt.Fprintf(w, "<p>Results for %s</p>", html.EscapeString(q))
+		}, ""},
+		{"json", func(w http.ResponseWriter, q string) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"q": "<%s>"}`, q)
+		}, ""},
+	}
+	for _, tt := range tests {
+		findings := scan(t, NewXSS(), tt.page, "shoes")
+		checkFindings(t, tt.name, findings, tt.detail)
+	}
+}
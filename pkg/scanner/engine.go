@@ -0,0 +1,164 @@
+package scanner
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/decoder"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/fuzzer"
+)
+
+const (
+	// responses are checked for markers only, the rest is not read
+	maxBodySize = 10 << 20
+	// maxRedirects limits redirects a Redirector probe follows.
+	maxRedirects = 10
+)
+
+// followKey keeps FollowRedirect of the probe check in the request context.
+type followKey struct{}
+
+type Config struct {
+	Concurrency int
+	// RateLimit is requests per second to one host, zero disables it.
+	RateLimit int
+	Timeout   time.Duration
+}
+
+// Probe is one mutated request made by Check from Mutation at Point.
+type Probe struct {
+	Check    Check
+	Point    *Point
+	Mutation *Mutation
+	Request  *models.ParsedRequest
+
+	group *group
+}
+
+// Response has decoded body of the probe response. URL is where it came
+// from, it differs from the probe URL after redirects.
+type Response struct {
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Duration   time.Duration
+}
+
+// Engine sends probes with bounded concurrency. Rate limit is shared by all
+// scans using the engine.
+type Engine struct {
+	client      *http.Client
+	concurrency int
+	limiter     *hostLimiter
+}
+
+func NewEngine(cfg Config) *Engine {
+	concurrency := max(cfg.Concurrency, 1)
+	limiter := newHostLimiter(cfg.RateLimit)
+	return &Engine{
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				follow, ok := req.Context().Value(followKey{}).(func(*http.Request, []*http.Request) bool)
+				if !ok || len(via) > maxRedirects || !follow(req, via) {
+					return http.ErrUseLastResponse
+				}
+				// followed redirects are requests to the host too
+				return limiter.Wait(req.Context(), req.URL.Host)
+			},
+			Transport: &http.Transport{
+				TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+				MaxIdleConnsPerHost: concurrency,
+			},
+		},
+		concurrency: concurrency,
+		limiter:     limiter,
+	}
+}
+
+// Run sends probes and calls fn with the response or the error of every
+// probe. fn calls are serialized. When ctx is done Run stops sending,
+// drops results of interrupted probes and returns ctx error.
+func (e *Engine) Run(ctx context.Context, probes []*Probe, fn func(*Probe, *Response, error)) error {
+	queue := make(chan *Probe)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range min(e.concurrency, len(probes)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for probe := range queue {
+				sendCtx := ctx
+				if r, ok := probe.Check.(Redirector); ok {
+					sendCtx = context.WithValue(ctx, followKey{}, r.FollowRedirect)
+				}
+				resp, err := e.Send(sendCtx, probe.Request)
+				if ctx.Err() != nil {
+					continue
+				}
+				mu.Lock()
+				fn(probe, resp, err)
+				mu.Unlock()
+			}
+		}()
+	}
+
+loop:
+	for _, probe := range probes {
+		select {
+		case queue <- probe:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// Send sends one request, waiting for the rate limit of its host.
+func (e *Engine) Send(ctx context.Context, parsedReq *models.ParsedRequest) (*Response, error) {
+	u, err := url.Parse(parsedReq.URL)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.limiter.Wait(ctx, u.Host); err != nil {
+		return nil, err
+	}
+
+	req, err := fuzzer.NewRequest(ctx, parsedReq)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, err
+	}
+	if decoded, err := decoder.DecodeBody(body, resp.Header.Get("Content-Encoding")); err == nil {
+		body = decoded
+	}
+
+	return &Response{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		Duration:   time.Since(start),
+	}, nil
+}
@@ -0,0 +1,51 @@
+package scanner
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// hostLimiter spaces requests to the same host evenly, so bursts from many
+// workers don't hit the host at once.
+type hostLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     map[string]time.Time
+}
+
+// newHostLimiter returns nil when rps is zero, nil limiter never waits.
+func newHostLimiter(rps int) *hostLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	return &hostLimiter{
+		interval: time.Second / time.Duration(rps),
+		next:     make(map[string]time.Time),
+	}
+}
+
+// Wait reserves the next slot of the host and sleeps until it comes.
+func (l *hostLimiter) Wait(ctx context.Context, host string) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[host] = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
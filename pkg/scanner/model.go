@@ -1,8 +0,0 @@
-package scanner
-
-type InjectionReport struct {
-	VulnerableGETParams  []string `json:"vulnerableGetParams"`
-	VulnerablePOSTParams []string `json:"vulnerablePostParams"`
-	VulnerableHeaders    []string `json:"vulnerableHeaders"`
-	VulnerableCookies    []string `json:"vulnerableCookies"`
-}
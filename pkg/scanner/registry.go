@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+var pointTypes = []string{models.PointPath, models.PointQuery, models.PointForm, models.PointXML, models.PointHeader, models.PointCookie}
+
+// Registry holds available checks in registration order.
+type Registry struct {
+	checks []Check
+}
+
+func NewRegistry(checks ...Check) (*Registry, error) {
+	r := &Registry{}
+	for _, c := range checks {
+		if err := r.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *Registry) Register(check Check) error {
+	if r.Get(check.ID()) != nil {
+		return fmt.Errorf("check %q is already registered", check.ID())
+	}
+	r.checks = append(r.checks, check)
+	return nil
+}
+
+// Get returns nil for unknown check.
+func (r *Registry) Get(id string) Check {
+	for _, c := range r.checks {
+		if c.ID() == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (r *Registry) Checks() []Check {
+	return slices.Clone(r.checks)
+}
+
+// Validate reports unknown checks and point types of the policy.
+func (r *Registry) Validate(policy *models.ScanPolicy) error {
+	for _, id := range policy.Checks {
+		if r.Get(id) == nil {
+			return fmt.Errorf("unknown check %q", id)
+		}
+	}
+	for _, typ := range policy.Points {
+		if !slices.Contains(pointTypes, typ) {
+			return fmt.Errorf("unknown point type %q", typ)
+		}
+	}
+	return nil
+}
+
+// enabled returns checks of the policy, all of them for empty policy.
+func (r *Registry) enabled(policy *models.ScanPolicy) []Check {
+	if policy == nil || len(policy.Checks) == 0 {
+		return r.Checks()
+	}
+	var checks []Check
+	for _, c := range r.checks {
+		if slices.Contains(policy.Checks, c.ID()) {
+			checks = append(checks, c)
+		}
+	}
+	return checks
+}
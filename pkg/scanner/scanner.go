@@ -1,141 +1,234 @@
 package scanner
 
 import (
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"github.com/MatiXxD/go-mitm-proxy/internal/models"
-	"io"
 	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+	"github.com/MatiXxD/go-mitm-proxy/pkg/fuzzer"
 )
 
-var defaultInjections = []string{
-	";cat /etc/passwd;",
-	"|cat /etc/passwd|",
-	"`cat /etc/passwd`",
+// ErrBaseline means the unmodified request got no response, so there is
+// nothing to compare mutations with.
+var ErrBaseline = errors.New("can't get baseline response")
+
+// skipHeaders are set by the client from the request itself, injecting
+// into them changes nothing.
+var skipHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Transfer-Encoding": true,
+	"Cookie":            true,
 }
 
-var defaultInjectionsResults = []string{
-	"root:",
+// Progress is called after every mutated request with the number of sent
+// requests and their total.
+type Progress func(done, total int)
+
+type Scanner struct {
+	engine   *Engine
+	registry *Registry
 }
 
-type InjectionScanner interface {
-	Scan(*models.ParsedRequest) *InjectionReport
+func NewScanner(engine *Engine, registry *Registry) *Scanner {
+	return &Scanner{
+		engine:   engine,
+		registry: registry,
+	}
 }
 
-type Scanner struct {
-	injections        []string
-	injectionsResults []string
+// Registry returns checks the scanner can run.
+func (s *Scanner) Registry() *Registry {
+	return s.registry
 }
 
-func NewInjectionScanner(injections, injectionsResults []string) InjectionScanner {
-	if injections == nil || len(injections) == 0 {
-		injections = defaultInjections
-	}
+// group is responses of one check at one point, it is evaluated as soon as
+// all of them are received, so bodies are not kept till the end of the scan.
+type group struct {
+	check   Check
+	point   *Point
+	results []*Result
+	pending int
+}
 
-	if injectionsResults == nil || len(injectionsResults) == 0 {
-		injectionsResults = defaultInjectionsResults
+// Scan sends the unmodified request to get the baseline response, then
+// runs checks enabled by the policy against every insertion point. Probes
+// without response are reported as errors. When ctx is done the scan stops
+// and returns result of the evaluated points together with ctx error.
+// scanID is passed to checks in points.
+func (s *Scanner) Scan(ctx context.Context, scanID string, parsedReq *models.ParsedRequest, policy *models.ScanPolicy, progress Progress) (*models.ScanResult, error) {
+	result := &models.ScanResult{
+		Findings: make([]*models.Finding, 0),
+		Errors:   make([]*models.ProbeError, 0),
 	}
 
-	return &Scanner{
-		injections:        injections,
-		injectionsResults: injectionsResults,
+	baseline, err := s.engine.Send(ctx, parsedReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		return result, fmt.Errorf("%w: %v", ErrBaseline, err)
 	}
-}
 
-func (s *Scanner) Scan(parsedReq *models.ParsedRequest) *InjectionReport {
-	report := &InjectionReport{}
-
-	// testing query
-	for param := range parsedReq.Form {
-		for _, inj := range s.injections {
-			injReq := models.CloneParsedRequest(parsedReq)
-			injReq.Form.Set(param, injReq.Form.Get(param)+inj)
-			fmt.Printf("Test injection with: %v\n", injReq)
-			req, _ := newRequest(injReq)
-			if s.checkInjection(req) {
-				report.VulnerableGETParams = append(report.VulnerableGETParams, param)
+	var probes []*Probe
+	for _, point := range InsertionPoints(parsedReq) {
+		point.ScanID = scanID
+		if policy != nil && len(policy.Points) > 0 && !slices.Contains(policy.Points, point.Type) {
+			continue
+		}
+		for _, check := range s.registry.enabled(policy) {
+			g := &group{check: check, point: point}
+			for _, m := range check.Mutations(point, baseline) {
+				req, err := fuzzer.Inject(parsedReq, []*models.InsertionPoint{point.InsertionPoint}, &fuzzer.Item{
+					Positions: []int{0},
+					Payloads:  []string{m.Payload},
+				})
+				if err == nil && m.Edit != nil {
+					req, err = m.Edit(req)
+				}
+				if err != nil {
+					result.Errors = append(result.Errors, newProbeError(point, m.Payload, err))
+					continue
+				}
+				g.pending++
+				probes = append(probes, &Probe{Check: check, Point: point, Mutation: m, Request: req, group: g})
 			}
 		}
 	}
 
-	// testing POST
-	if parsedReq.Method == "POST" && len(parsedReq.PostForm) > 0 {
-		for param := range parsedReq.PostForm {
-			for _, inj := range s.injections {
-				injReq := models.CloneParsedRequest(parsedReq)
-				injReq.PostForm.Set(param, injReq.PostForm.Get(param)+inj)
-				fmt.Printf("Test injection with: %v\n", injReq)
-				req, _ := newRequest(injReq)
-				if s.checkInjection(req) {
-					report.VulnerablePOSTParams = append(report.VulnerablePOSTParams, param)
-				}
-			}
+	if progress != nil {
+		progress(0, len(probes))
+	}
+
+	err = s.engine.Run(ctx, probes, func(probe *Probe, resp *Response, err error) {
+		result.Requests++
+		if progress != nil {
+			progress(result.Requests, len(probes))
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, newProbeError(probe.Point, probe.Mutation.Payload, err))
+		}
+
+		g := probe.group
+		g.results = append(g.results, &Result{Mutation: probe.Mutation, Response: resp, Err: err})
+		if g.pending--; g.pending == 0 {
+			result.Findings = append(result.Findings, g.check.Evaluate(g.point, baseline, g.results)...)
+			g.results = nil
+		}
+	})
+
+	return result, err
+}
+
+// Observe passes captured exchange to checks which look at the traffic.
+func (s *Scanner) Observe(parsedReq *models.ParsedRequest, resp *Response) []*models.Finding {
+	var findings []*models.Finding
+	for _, c := range s.registry.checks {
+		if o, ok := c.(Observer); ok {
+			findings = append(findings, o.Observe(parsedReq, resp)...)
 		}
 	}
+	return findings
+}
 
-	// testing headers
-	for header := range parsedReq.Header {
-		for _, inj := range s.injections {
-			injReq := models.CloneParsedRequest(parsedReq)
-			injReq.Header.Set(header, injReq.Header.Get(header)+inj)
-			fmt.Printf("Test injection with: %v\n", injReq)
-			req, _ := newRequest(injReq)
-			if s.checkInjection(req) {
-				report.VulnerableHeaders = append(report.VulnerableHeaders, header)
-			}
+// InsertionPoints returns non-empty path segments in order, query and form
+// parameters, values of XML body in document order, headers and cookies of
+// the request. Parameters, headers and cookies are sorted by name.
+func InsertionPoints(parsedReq *models.ParsedRequest) []*Point {
+	var points []*Point
+	add := func(typ string, values map[string]string) {
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			points = append(points, &Point{
+				InsertionPoint: &models.InsertionPoint{Type: typ, Name: name},
+				Original:       values[name],
+				Request:        parsedReq,
+			})
 		}
 	}
 
-	// testing cookies
-	for i, _ := range parsedReq.Cookies {
-		for _, inj := range s.injections {
-			injReq := models.CloneParsedRequest(parsedReq)
-			c := *injReq.Cookies[i]
-			c.Value = c.Value + inj
-			fmt.Printf("Test injection with: %v\n", injReq)
-			req, _ := newRequest(injReq)
-			if s.checkInjection(req) {
-				report.VulnerableCookies = append(report.VulnerableCookies, c.String())
+	if u, err := url.Parse(parsedReq.URL); err == nil {
+		// names of path points are segment indexes, as the fuzzer takes them
+		for i, segment := range strings.Split(strings.TrimPrefix(u.EscapedPath(), "/"), "/") {
+			value, err := url.PathUnescape(segment)
+			if err != nil || value == "" {
+				continue
 			}
+			points = append(points, &Point{
+				InsertionPoint: &models.InsertionPoint{Type: models.PointPath, Name: strconv.Itoa(i)},
+				Original:       value,
+				Request:        parsedReq,
+			})
+		}
+		add(models.PointQuery, first(u.Query()))
+	}
+	if parsedReq.BodyMeta.MimeType == "application/x-www-form-urlencoded" {
+		if form, err := url.ParseQuery(string(parsedReq.Body)); err == nil {
+			add(models.PointForm, first(form))
+		}
+	}
+	if isXML(parsedReq.BodyMeta.MimeType) {
+		values, _ := fuzzer.XMLValues(parsedReq.Body)
+		for _, v := range values {
+			points = append(points, &Point{
+				InsertionPoint: &models.InsertionPoint{Type: models.PointXML, Name: v.Path},
+				Original:       v.Value,
+				Request:        parsedReq,
+			})
 		}
 	}
 
-	return report
-}
-
-func (s *Scanner) checkInjection(req *http.Request) bool {
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return false
+	headers := make(map[string]string)
+	for name := range parsedReq.Header {
+		if !skipHeaders[http.CanonicalHeaderKey(name)] {
+			headers[name] = parsedReq.Header.Get(name)
+		}
 	}
-	defer resp.Body.Close()
+	add(models.PointHeader, headers)
 
-	body, _ := io.ReadAll(resp.Body)
-	for _, injRes := range s.injectionsResults {
-		if bytes.Contains(body, []byte(injRes)) {
-			return true
+	cookies := make(map[string]string)
+	for _, c := range parsedReq.Cookies {
+		if _, ok := cookies[c.Name]; !ok {
+			cookies[c.Name] = c.Value
 		}
 	}
+	add(models.PointCookie, cookies)
 
-	return false
+	return points
 }
 
-func newRequest(parsedReq *models.ParsedRequest) (*http.Request, error) {
-	req, err := http.NewRequest(parsedReq.Method, parsedReq.URL, bytes.NewBuffer([]byte(parsedReq.Body)))
-	if err != nil {
-		return nil, err
-	}
-
-	req.Host = parsedReq.URL
-	req.ContentLength = parsedReq.ContentLength
+func isXML(mimeType string) bool {
+	return mimeType == "application/xml" || mimeType == "text/xml" || strings.HasSuffix(mimeType, "+xml")
+}
 
-	req.Header = parsedReq.Header
-	for _, cookie := range parsedReq.Cookies {
-		req.AddCookie(cookie)
+func first(values url.Values) map[string]string {
+	res := make(map[string]string, len(values))
+	for k, v := range values {
+		if len(v) > 0 {
+			res[k] = v[0]
+		} else {
+			res[k] = ""
+		}
 	}
+	return res
+}
 
-	req.PostForm = parsedReq.PostForm
-	req.Form = parsedReq.Form
-
-	return req, nil
+func newProbeError(point *Point, payload string, err error) *models.ProbeError {
+	return &models.ProbeError{
+		Location: point.Type,
+		Name:     point.Name,
+		Payload:  payload,
+		Error:    err.Error(),
+	}
 }
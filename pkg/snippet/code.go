@@ -0,0 +1,136 @@
+package snippet
+
+import (
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+func renderPython(req *models.ParsedRequest) (string, error) {
+	var b strings.Builder
+	b.WriteString("import requests\n\n")
+	fmt.Fprintf(&b, "url = %s\n", quote(req.URL))
+
+	hdrs := joinedHeaders(req.Header)
+	b.WriteString("headers = {")
+	if len(hdrs) > 0 {
+		b.WriteString("\n")
+		for _, h := range hdrs {
+			fmt.Fprintf(&b, "    %s: %s,\n", quote(h.name), quote(h.value))
+		}
+	}
+	b.WriteString("}\n")
+
+	args := "headers=headers"
+	if len(req.Body) > 0 {
+		if isBinary(req) {
+			fmt.Fprintf(&b, "data = %s\n", pythonBytes(req.Body))
+		} else {
+			fmt.Fprintf(&b, "data = %s.encode()\n", quote(string(req.Body)))
+		}
+		args += ", data=data"
+	}
+
+	fmt.Fprintf(&b, "\nresponse = requests.request(%s, url, %s, allow_redirects=False)\n", quote(req.Method), args)
+	b.WriteString("print(response.status_code)\nprint(response.text)\n")
+	return b.String(), nil
+}
+
+func pythonBytes(data []byte) string {
+	var b strings.Builder
+	b.WriteString(`b"`)
+	for _, c := range data {
+		switch {
+		case c == '\\' || c == '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c >= 0x20 && c < 0x7f:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func renderGo(req *models.ParsedRequest) (string, error) {
+	var b strings.Builder
+	b.WriteString("package main\n\nimport (\n\t\"fmt\"\n\t\"io\"\n\t\"net/http\"\n")
+	if len(req.Body) > 0 {
+		b.WriteString("\t\"strings\"\n")
+	}
+	b.WriteString(")\n\nfunc main() {\n")
+
+	body := "nil"
+	if len(req.Body) > 0 {
+		// strconv.Quote escapes invalid UTF-8 bytes, so binary bodies are kept
+		fmt.Fprintf(&b, "body := strings.NewReader(%s)\n", strconv.Quote(string(req.Body)))
+		body = "body"
+	}
+	fmt.Fprintf(&b, "req, err := http.NewRequest(%s, %s, %s)\n", strconv.Quote(req.Method), strconv.Quote(req.URL), body)
+	b.WriteString("if err != nil {\npanic(err)\n}\n")
+	for _, h := range headers(req.Header) {
+		fmt.Fprintf(&b, "req.Header.Add(%s, %s)\n", strconv.Quote(h.name), strconv.Quote(h.value))
+	}
+
+	b.WriteString(`
+client := &http.Client{
+CheckRedirect: func(req *http.Request, via []*http.Request) error {
+return http.ErrUseLastResponse
+},
+}
+resp, err := client.Do(req)
+if err != nil {
+panic(err)
+}
+defer resp.Body.Close()
+
+respBody, err := io.ReadAll(resp.Body)
+if err != nil {
+panic(err)
+}
+fmt.Println(resp.Status)
+fmt.Println(string(respBody))
+}
+`)
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return "", fmt.Errorf("can't format go code: %v", err)
+	}
+	return string(src), nil
+}
+
+func renderJS(req *models.ParsedRequest) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "const response = await fetch(%s, {\n", quote(req.URL))
+	fmt.Fprintf(&b, "  method: %s,\n", quote(req.Method))
+
+	if hdrs := joinedHeaders(req.Header); len(hdrs) > 0 {
+		b.WriteString("  headers: {\n")
+		for _, h := range hdrs {
+			fmt.Fprintf(&b, "    %s: %s,\n", quote(h.name), quote(h.value))
+		}
+		b.WriteString("  },\n")
+	}
+
+	if len(req.Body) > 0 {
+		if isBinary(req) {
+			nums := make([]string, len(req.Body))
+			for i, c := range req.Body {
+				nums[i] = strconv.Itoa(int(c))
+			}
+			fmt.Fprintf(&b, "  body: new Uint8Array([%s]),\n", strings.Join(nums, ", "))
+		} else {
+			fmt.Fprintf(&b, "  body: %s,\n", quote(string(req.Body)))
+		}
+	}
+
+	b.WriteString("  redirect: \"manual\",\n});\n")
+	b.WriteString("console.log(response.status);\nconsole.log(await response.text());\n")
+	return b.String(), nil
+}
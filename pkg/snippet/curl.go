@@ -0,0 +1,62 @@
+package snippet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+func renderCurl(req *models.ParsedRequest) (string, error) {
+	var args []string
+	// with -X HEAD curl waits for the body which never comes
+	if req.Method == "HEAD" && len(req.Body) == 0 {
+		args = append(args, "-I")
+	} else if len(req.Body) > 0 || req.Method != "GET" {
+		args = append(args, "-X "+shellQuote(req.Method))
+	}
+	args = append(args, shellQuote(req.URL))
+	for _, h := range headers(req.Header) {
+		args = append(args, "-H "+shellQuote(h.name+": "+h.value))
+	}
+
+	if len(req.Body) == 0 {
+		return "curl " + strings.Join(args, " \\\n  ") + "\n", nil
+	}
+
+	// binary body can't be passed as argument, it is piped through printf
+	if isBinary(req) {
+		args = append(args, "--data-binary @-")
+		return fmt.Sprintf("printf '%%b' %s | \\\n  curl %s", printfQuote(req.Body), strings.Join(args, " \\\n  ")) + "\n", nil
+	}
+
+	args = append(args, "--data-binary "+shellQuote(string(req.Body)))
+	return "curl " + strings.Join(args, " \\\n  ") + "\n", nil
+}
+
+// shellQuote quotes s for POSIX shell, single quotes are closed, escaped and
+// opened again.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// printfQuote escapes data for printf %b, every byte except printable ASCII
+// is written as \0NNN.
+func printfQuote(data []byte) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, c := range data {
+		switch {
+		case c == '\\':
+			b.WriteString(`\\`)
+		case c == '\'':
+			b.WriteString(`'\''`)
+		case c >= 0x20 && c < 0x7f:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, `\0%03o`, c)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
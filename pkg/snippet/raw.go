@@ -0,0 +1,30 @@
+package snippet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+// renderRaw renders HTTP/1.1 message as it goes over the wire, body is kept
+// as is even if it is binary.
+func renderRaw(req *models.ParsedRequest) (string, error) {
+	host, path := hostAndPath(req.URL)
+	if host == "" {
+		host = req.Host
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s HTTP/1.1\r\n", req.Method, path)
+	fmt.Fprintf(&b, "Host: %s\r\n", host)
+	for _, h := range headers(req.Header) {
+		fmt.Fprintf(&b, "%s: %s\r\n", h.name, h.value)
+	}
+	if len(req.Body) > 0 {
+		fmt.Fprintf(&b, "Content-Length: %d\r\n", len(req.Body))
+	}
+	b.WriteString("\r\n")
+	b.Write(req.Body)
+	return b.String(), nil
+}
@@ -0,0 +1,130 @@
+package snippet
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MatiXxD/go-mitm-proxy/internal/models"
+)
+
+const (
+	FormatCurl   = "curl"
+	FormatRaw    = "raw"
+	FormatPython = "python"
+	FormatGo     = "go"
+	FormatJS     = "js"
+)
+
+var ErrUnknownFormat = errors.New("unknown format")
+
+var renderers = map[string]func(*models.ParsedRequest) (string, error){
+	FormatCurl:   renderCurl,
+	FormatRaw:    renderRaw,
+	FormatPython: renderPython,
+	FormatGo:     renderGo,
+	FormatJS:     renderJS,
+}
+
+// Formats returns supported format names.
+func Formats() []string {
+	formats := make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Render renders request in given format. Body has to be loaded, offloaded
+// bodies are not fetched here.
+func Render(req *models.ParsedRequest, format string) (string, error) {
+	render, ok := renderers[format]
+	if !ok {
+		return "", ErrUnknownFormat
+	}
+	return render(req)
+}
+
+// skipHeaders are computed by clients from URL and body, so copying them
+// only breaks edited requests.
+var skipHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Proxy-Connection":  true,
+	"Transfer-Encoding": true,
+}
+
+type header struct {
+	name  string
+	value string
+}
+
+// headers returns request headers sorted by name, multiple values keep
+// their order.
+func headers(h http.Header) []header {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		if !skipHeaders[http.CanonicalHeaderKey(name)] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var res []header
+	for _, name := range names {
+		for _, v := range h[name] {
+			res = append(res, header{name: name, value: v})
+		}
+	}
+	return res
+}
+
+// joinedHeaders merges repeated headers for languages that keep headers in a
+// map, cookies are joined the way browsers send them.
+func joinedHeaders(h http.Header) []header {
+	var res []header
+	for _, hdr := range headers(h) {
+		if n := len(res); n > 0 && res[n-1].name == hdr.name {
+			sep := ", "
+			if http.CanonicalHeaderKey(hdr.name) == "Cookie" {
+				sep = "; "
+			}
+			res[n-1].value += sep + hdr.value
+			continue
+		}
+		res = append(res, hdr)
+	}
+	return res
+}
+
+// isBinary also catches bodies in other charsets, they can't be written as
+// UTF-8 string literals.
+func isBinary(req *models.ParsedRequest) bool {
+	return req.BodyMeta.Binary || !utf8.Valid(req.Body)
+}
+
+func hostAndPath(rawURL string) (string, string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", rawURL
+	}
+	path := u.RequestURI()
+	if path == "" {
+		path = "/"
+	}
+	return u.Host, path
+}
+
+// quote returns double-quoted string literal valid in JavaScript and Python.
+func quote(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}